@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
@@ -54,6 +55,7 @@ func TestGetAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
 			},
 		},
 		{
@@ -67,6 +69,7 @@ func TestGetAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -79,6 +82,7 @@ func TestGetAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 	}
@@ -110,10 +114,13 @@ func TestGetAccountAPI(t *testing.T){
 func TestCreateAccountAPI(t *testing.T){
 	testAccount := randomAccount();
 	testUser, _ := randomUser();
-	args := db.CreateAccountParams{
-		Owner: testUser.Username,
-		Balance: 0,
-		Currency: testAccount.Currency,
+	args := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner: testUser.Username,
+			Balance: 0,
+			Currency: testAccount.Currency,
+			AccountType: util.CheckingAccount,
+		},
 	}
 
 	testCases := []struct{
@@ -127,7 +134,7 @@ func TestCreateAccountAPI(t *testing.T){
 			account: testAccount,
 			buildStubs: func (store *mockdb.MockStore)  {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(args)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
 					Return(testAccount, nil)
 			},
@@ -141,12 +148,13 @@ func TestCreateAccountAPI(t *testing.T){
 			account: testAccount,
 			buildStubs: func (store *mockdb.MockStore)  {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(args)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -159,6 +167,7 @@ func TestCreateAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 	}
@@ -251,6 +260,7 @@ func TestListAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -266,6 +276,7 @@ func TestListAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 	}
@@ -337,6 +348,7 @@ func TestDeleteAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
 			},
 		},
 		{
@@ -354,6 +366,7 @@ func TestDeleteAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -372,6 +385,7 @@ func TestDeleteAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -388,6 +402,7 @@ func TestDeleteAccountAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 	}
@@ -416,7 +431,10 @@ func TestDeleteAccountAPI(t *testing.T){
 }
 
 func TestUpdateAccountAPI(t *testing.T){
-	testUser, _ := randomUser();
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	testUser, _ := randomUser()
+	testUser.Role = util.DepositorRole
 	account := randomAccount()
 	account.Owner = testUser.Username
 	updatedAccount := db.Account{
@@ -429,6 +447,7 @@ func TestUpdateAccountAPI(t *testing.T){
 
 	testCases := []struct{
 		name string
+		user db.User
 		accountId int64
 		requestBody updateAccountJsonRequest
 		buildStubs func(store *mockdb.MockStore)
@@ -436,18 +455,23 @@ func TestUpdateAccountAPI(t *testing.T){
 	}{
 		{
 			name: "OK",
+			user: admin,
 			accountId: account.ID,
 			requestBody: updateAccountJsonRequest{
 				Balance: updatedAccount.Balance,
 			},
 			buildStubs: func (store *mockdb.MockStore)  {
-				args := db.UpdateAccountParams{
-					ID: account.ID,
-					Balance: updatedAccount.Balance,
+				args := db.UpdateAccountTxParams{
+					UpdateAccountParams: db.UpdateAccountParams{
+						ID: account.ID,
+						Balance: updatedAccount.Balance,
+					},
+					SetBy: admin.Username,
 				}
 
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(args)).
+					UpdateAccountTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
 					Return(updatedAccount, nil)
 			},
@@ -456,53 +480,103 @@ func TestUpdateAccountAPI(t *testing.T){
 				requireBodyMatchAccount(t, recorder.Body, updatedAccount)
 			},
 		},
+		{
+			// Not even the owner may set their own balance.
+			name: "Owner",
+			user: testUser,
+			accountId: account.ID,
+			requestBody: updateAccountJsonRequest{
+				Balance: updatedAccount.Balance,
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().GetUser(gomock.Any(), testUser.Username).Times(1).Return(testUser, nil)
+				store.EXPECT().
+					UpdateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "NotFound",
+			user: admin,
+			accountId: account.ID,
+			requestBody: updateAccountJsonRequest{
+				Balance: updatedAccount.Balance,
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().
+					UpdateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
 		{
 			name: "InternalError",
+			user: admin,
 			accountId: account.ID,
 			requestBody: updateAccountJsonRequest{
 				Balance: updatedAccount.Balance,
 			},
 			buildStubs: func (store *mockdb.MockStore)  {
-				args := db.UpdateAccountParams{
-					ID: account.ID,
-					Balance: updatedAccount.Balance,
+				args := db.UpdateAccountTxParams{
+					UpdateAccountParams: db.UpdateAccountParams{
+						ID: account.ID,
+						Balance: updatedAccount.Balance,
+					},
+					SetBy: admin.Username,
 				}
 
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(args)).
+					UpdateAccountTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
 			name: "InvalidID",
+			user: admin,
 			accountId: 0,
 			requestBody: updateAccountJsonRequest{
 				Balance: updatedAccount.Balance,
 			},
 			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					UpdateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 		{
 			name: "EmptyBalance",
+			user: admin,
 			accountId: account.ID,
 			requestBody: updateAccountJsonRequest{},
 			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					UpdateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 	}
@@ -519,20 +593,115 @@ func TestUpdateAccountAPI(t *testing.T){
 		require.NoError(t, err)
 		recorder := httptest.NewRecorder()
 
-		url := fmt.Sprintf("/accounts/%d", tc.accountId) 
+		url := fmt.Sprintf("/admin/accounts/%d/balance", tc.accountId) 
 		jsonData, err := json.Marshal(tc.requestBody)
 		require.NoError(t, err)
 
 		request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonData))
 		require.NoError(t, err)
 
-		addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+		addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
 
 		server.router.ServeHTTP(recorder, request)
 		tc.checkReposne(t, recorder)
 	}
 }
 
+// TestUpdateAccountLegacyAPI checks the old PUT /accounts/:id route still
+// answers: admins overwrite the balance, everyone else gets a 403.
+func TestUpdateAccountLegacyAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	owner, _ := randomUser()
+	owner.Role = util.DepositorRole
+	stranger, _ := randomUser()
+	stranger.Role = util.DepositorRole
+	account := randomAccount()
+	account.Owner = owner.Username
+
+	testCases := []struct {
+		name         string
+		user         db.User
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Admin",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpdateAccountTx(gomock.Any(), db.UpdateAccountTxParams{
+					UpdateAccountParams: db.UpdateAccountParams{ID: account.ID, Balance: 500},
+					SetBy:               admin.Username,
+				}).Times(1).Return(account, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Owner",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), owner.Username).Times(1).Return(owner, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "Stranger",
+			user: stranger,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), stranger.Username).Times(1).Return(stranger, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().UpdateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "NotFound",
+			user: owner,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), owner.Username).Times(1).Return(owner, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpdateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/accounts/%d", account.ID), bytes.NewBufferString(`{"balance":500}`))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
 func randomAccount() db.Account {
 	return db.Account{
 		ID: util.RandomInt(1, 1000),
@@ -560,4 +729,243 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, account []db.Acc
 	err = json.Unmarshal(data, &gotAccount)
 	require.NoError(t, err)
 	require.Equal(t, account, gotAccount)
-}
\ No newline at end of file
+}
+func TestCreateAccountLimitAPI(t *testing.T) {
+	testUser, _ := randomUser()
+
+	testCases := []struct {
+		name         string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "BelowLimit",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:       testUser.Username,
+						Currency:    "USD",
+						AccountType: util.PocketAccount,
+						Nickname:    "Holiday",
+					},
+					MaxAccounts: 3,
+				}).Times(1).Return(db.Account{ID: 1}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "LimitReached",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrAccountLimitReached)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeAccountLimitReached)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			server.maxAccountsPerUser = 3
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(createAccountRequest{
+				Currency:    "USD",
+				AccountType: util.PocketAccount,
+				Nickname:    "Holiday",
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestListAccountFiltersAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	currency := "EUR"
+	accountType := util.SavingsAccount
+
+	testCases := []struct {
+		name         string
+		query        string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "CurrencyAndType",
+			query: "page_id=1&page_size=5&currency=EUR&account_type=savings",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), db.ListAccountsParams{
+					Owner:       testUser.Username,
+					Currency:    &currency,
+					AccountType: &accountType,
+					Limit:       5,
+				}).Times(1).Return([]db.Account{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidType",
+			query: "page_id=1&page_size=5&account_type=system",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestSetAccountFrozenAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	depositor, _ := randomUser()
+	depositor.Role = util.DepositorRole
+
+	testCases := []struct {
+		name         string
+		user         db.User
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Freeze",
+			user:   admin,
+			url:    fmt.Sprintf("/admin/accounts/%d/frozen", 7),
+			body:   gin.H{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), db.SetAccountFrozenParams{ID: 7, Frozen: true}).
+					Times(1).Return(db.SetAccountFrozenTxResult{Account: db.Account{ID: 7, Frozen: true}, Changed: true}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var account db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &account))
+				require.True(t, account.Frozen)
+			},
+		},
+		{
+			name:   "FreezeMissingFlag",
+			user:   admin,
+			url:    fmt.Sprintf("/admin/accounts/%d/frozen", 7),
+			body:   gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:   "FreezeNotAdmin",
+			user:   depositor,
+			url:    fmt.Sprintf("/admin/accounts/%d/frozen", 7),
+			body:   gin.H{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "InvalidID",
+			user: admin,
+			url:  "/admin/accounts/0/frozen",
+			body: gin.H{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			user: admin,
+			url:  fmt.Sprintf("/admin/accounts/%d/frozen", 7),
+			body: gin.H{"frozen": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), db.SetAccountFrozenParams{ID: 7, Frozen: false}).
+					Times(1).Return(db.SetAccountFrozenTxResult{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+
+			request, err := http.NewRequest(http.MethodPut, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
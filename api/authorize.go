@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// authorizeAccount checks that username may act on account with the given
+// permission. The owner holds every permission; anyone else needs a row in
+// account_members granting at least that permission. On failure it writes
+// the response and returns false.
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, username string, permission string) bool {
+	if status, err := server.checkPermission(ctx, account, username, permission); err != nil {
+		writeError(ctx, status, err)
+		return false
+	}
+
+	return true
+}
+
+// checkPermission applies the rules of authorizeAccount without writing a
+// response. On failure it returns the HTTP status the error maps to.
+func (server *Server) checkPermission(ctx context.Context, account db.Account, username string, permission string) (int, error) {
+	if account.Owner == username {
+		return http.StatusOK, nil
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusForbidden, token.ErrDoesNotBelong
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	if !util.HasPermission(member.Permission, permission) {
+		return http.StatusForbidden, token.ErrActionForbidden
+	}
+
+	return http.StatusOK, nil
+}
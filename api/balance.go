@@ -0,0 +1,100 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+type getBalanceUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getBalanceQueryRequest struct {
+	At time.Time `form:"at" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var reqUri getBalanceUriRequest
+	var reqQuery getBalanceQueryRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&reqQuery); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, reqUri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, authPayload.Username, util.ViewPermission) {
+		return
+	}
+
+	at := reqQuery.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	balance, err := snapshot.BalanceAt(ctx, server.store, account, at)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance)
+}
+
+type runSnapshotRequest struct {
+	Date string `json:"date" binding:"required,datetime=2006-01-02"`
+}
+
+type runSnapshotResponse struct {
+	Date     string `json:"date"`
+	Accounts int64  `json:"accounts"`
+}
+
+func (server *Server) runBalanceSnapshot(ctx *gin.Context) {
+	var req runSnapshotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	written, err := server.snapshotJob.Run(ctx, date)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runSnapshotResponse{
+		Date:     req.Date,
+		Accounts: written,
+	})
+}
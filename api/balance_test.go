@@ -0,0 +1,113 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAccountBalanceAPI(t *testing.T) {
+	account := randomAccount()
+	testUser, _ := randomUser()
+	account.Owner = testUser.Username
+	at := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		at           string
+		username     string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			at:       at.Format(time.RFC3339),
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountBalanceSnapshot{AccountID: account.ID, SnapshotDate: snapshot.Day(at).AddDate(0, 0, -1), Balance: 100}, nil)
+				store.EXPECT().SumEntriesInRange(gomock.Any(), gomock.Any()).Times(1).Return(int64(25), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var balance snapshot.Balance
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &balance))
+				require.Equal(t, int64(125), balance.Balance)
+				require.True(t, at.Equal(balance.At))
+			},
+		},
+		{
+			name:     "Forbidden",
+			at:       at.Format(time.RFC3339),
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, Username: "someoneelse"}).
+					Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "NotFound",
+			at:       at.Format(time.RFC3339),
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:     "InvalidTimestamp",
+			at:       "yesterday",
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance?at=%s", account.ID, url.QueryEscape(tc.at))
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/logging"
+	"github.com/ulunnuha-h/simple_bank/token"
+)
+
+// APIError is the body of every error response. Code is stable and meant for
+// clients to branch on; Message is for humans and may change.
+type APIError struct {
+	Code      string        `json:"code"`
+	Message   string        `json:"message"`
+	Details   []ErrorDetail `json:"details,omitempty"`
+	RequestID string        `json:"request_id,omitempty"`
+}
+
+// ErrorDetail describes one invalid field of a request.
+type ErrorDetail struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error codes. Generic codes follow the status; domain codes name the rule
+// that was broken.
+const (
+	CodeInvalidArgument          = "invalid_argument"
+	CodeValidationFailed         = "validation_failed"
+	CodeUnauthenticated          = "unauthenticated"
+	CodeTokenExpired             = "token_expired"
+	CodeInvalidToken             = "invalid_token"
+	CodeSessionExpired           = "session_expired"
+	CodePermissionDenied         = "permission_denied"
+	CodeNotFound                 = "not_found"
+	CodeAlreadyExists            = "already_exists"
+	CodeConflict                 = "conflict"
+	CodePayloadTooLarge          = "payload_too_large"
+	CodeRateLimited              = "rate_limited"
+	CodeInternal                 = "internal"
+	CodeUnavailable              = "unavailable"
+	CodeInsufficientFunds        = "insufficient_funds"
+	CodeCurrencyMismatch         = "currency_mismatch"
+	CodeAccountFrozen            = "account_frozen"
+	CodeAccountLimitReached      = "account_limit_reached"
+	CodePaymentRequestNotPending = "payment_request_not_pending"
+	CodePaymentRequestExpired    = "payment_request_expired"
+	CodeBatchNotPending          = "batch_not_pending"
+	CodeCSRFInvalid              = "csrf_invalid"
+	CodeOriginNotAllowed         = "origin_not_allowed"
+)
+
+var (
+	errInsufficientBalance = errors.New("insufficient balance")
+	errCurrencyMismatch    = errors.New("currency mismatch")
+	errAccountLimit        = errors.New("account limit reached")
+	errAlreadyExists       = errors.New("resource already exists")
+	errReferenceNotFound   = errors.New("referenced resource does not exist")
+	errConstraint          = errors.New("request violates a data constraint")
+	errTxContention        = errors.New("too much contention, try again")
+	errNotFound            = errors.New("resource not found")
+	errInternal            = errors.New("internal server error")
+)
+
+type errorClass struct {
+	err    error
+	status int
+	code   string
+	// message replaces the error text when the text isn't fit for clients.
+	message string
+}
+
+// errorClasses maps sentinel errors to their status and code. It is the one
+// place that decides how a known error is reported; the status a handler
+// passes only applies to errors not listed here.
+var errorClasses = []errorClass{
+	{err: sql.ErrNoRows, status: http.StatusNotFound, code: CodeNotFound, message: errNotFound.Error()},
+	{err: errNotFound, status: http.StatusNotFound, code: CodeNotFound},
+	{err: errReferenceNotFound, status: http.StatusNotFound, code: CodeNotFound},
+	{err: errRecipientNotFound, status: http.StatusNotFound, code: CodeNotFound},
+	{err: errAlreadyExists, status: http.StatusConflict, code: CodeAlreadyExists},
+	{err: errConstraint, status: http.StatusConflict, code: CodeConflict},
+	{err: errTxContention, status: http.StatusServiceUnavailable, code: CodeUnavailable},
+
+	{err: token.ErrAuthNotProvided, status: http.StatusUnauthorized, code: CodeUnauthenticated},
+	{err: token.ErrExpiredToken, status: http.StatusUnauthorized, code: CodeTokenExpired},
+	{err: token.ErrInvalidToken, status: http.StatusUnauthorized, code: CodeInvalidToken},
+	{err: errSessionExpired, status: http.StatusUnauthorized, code: CodeSessionExpired},
+	{err: token.ErrActionForbidden, status: http.StatusForbidden, code: CodePermissionDenied},
+	{err: token.ErrDoesNotBelong, status: http.StatusForbidden, code: CodePermissionDenied},
+	{err: token.ErrAdminOnly, status: http.StatusForbidden, code: CodePermissionDenied},
+	{err: errCSRFToken, status: http.StatusForbidden, code: CodeCSRFInvalid},
+	{err: errOriginNotAllowed, status: http.StatusForbidden, code: CodeOriginNotAllowed},
+
+	{err: errInsufficientBalance, status: http.StatusBadRequest, code: CodeInsufficientFunds},
+	{err: errCurrencyMismatch, status: http.StatusBadRequest, code: CodeCurrencyMismatch},
+	{err: errAccountFrozen, status: http.StatusForbidden, code: CodeAccountFrozen},
+	{err: errAccountLimit, status: http.StatusForbidden, code: CodeAccountLimitReached},
+	{err: errTooManyLookups, status: http.StatusTooManyRequests, code: CodeRateLimited},
+	{err: errRateLimited, status: http.StatusTooManyRequests, code: CodeRateLimited},
+	{err: errBatchFileSize, status: http.StatusRequestEntityTooLarge, code: CodePayloadTooLarge},
+	{err: errBatchNotPending, status: http.StatusConflict, code: CodeBatchNotPending},
+	{err: db.ErrPaymentRequestNotPending, status: http.StatusConflict, code: CodePaymentRequestNotPending},
+	{err: db.ErrPaymentRequestExpired, status: http.StatusConflict, code: CodePaymentRequestExpired},
+	{err: db.ErrBatchItemNotValid, status: http.StatusConflict, code: CodeConflict},
+}
+
+// statusCodes are the codes of errors that aren't classified, by status.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeInvalidArgument,
+	http.StatusUnauthorized:          CodeUnauthenticated,
+	http.StatusForbidden:             CodePermissionDenied,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+}
+
+// pqDomainError turns a postgres error into the domain error it stands for,
+// so constraint names and SQL never reach clients.
+func pqDomainError(err *pq.Error) error {
+	switch err.Code.Name() {
+	case "unique_violation":
+		return errAlreadyExists
+	case "foreign_key_violation":
+		return errReferenceNotFound
+	case "check_violation", "not_null_violation":
+		return errConstraint
+	case "serialization_failure", "deadlock_detected":
+		return errTxContention
+	}
+	return nil
+}
+
+// newAPIError describes err as an APIError. status is used when err is not
+// one of the known errors; 5xx errors never expose their text.
+func newAPIError(status int, err error) (int, APIError) {
+	if err == nil {
+		return http.StatusInternalServerError, APIError{Code: CodeInternal, Message: errInternal.Error()}
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return http.StatusBadRequest, APIError{
+			Code:    CodeValidationFailed,
+			Message: "request validation failed",
+			Details: validationDetails(validationErrs),
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return http.StatusBadRequest, APIError{Code: CodeInvalidArgument, Message: "request body is not valid JSON"}
+	case errors.As(err, &typeErr):
+		return http.StatusBadRequest, APIError{
+			Code:    CodeValidationFailed,
+			Message: "request validation failed",
+			Details: []ErrorDetail{{Field: typeErr.Field, Rule: "type", Message: fmt.Sprintf("must be a %s", typeErr.Type)}},
+		}
+	case errors.As(err, &numErr):
+		return http.StatusBadRequest, APIError{Code: CodeInvalidArgument, Message: fmt.Sprintf("%q is not a valid number", numErr.Num)}
+	}
+
+	message := err.Error()
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		if domainErr := pqDomainError(pqErr); domainErr != nil {
+			err, message = domainErr, domainErr.Error()
+		} else {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	for _, class := range errorClasses {
+		if errors.Is(err, class.err) {
+			if class.message != "" {
+				message = class.message
+			}
+			return class.status, APIError{Code: class.code, Message: message}
+		}
+	}
+
+	if status >= http.StatusInternalServerError || status < http.StatusBadRequest {
+		return http.StatusInternalServerError, APIError{Code: CodeInternal, Message: errInternal.Error()}
+	}
+
+	code, ok := statusCodes[status]
+	if !ok {
+		code = CodeInvalidArgument
+	}
+	return status, APIError{Code: code, Message: message}
+}
+
+// writeError writes err as an APIError carrying the request id. The raw
+// error is attached to the context so the request log keeps it.
+func writeError(ctx *gin.Context, status int, err error) {
+	status, body := newAPIError(status, err)
+	body.RequestID = logging.RequestID(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+	}
+	ctx.JSON(status, body)
+}
+
+// abortWithError is writeError for middleware: later handlers don't run.
+func abortWithError(ctx *gin.Context, status int, err error) {
+	writeError(ctx, status, err)
+	ctx.Abort()
+}
+
+func validationDetails(errs validator.ValidationErrors) []ErrorDetail {
+	details := make([]ErrorDetail, 0, len(errs))
+	for _, fieldErr := range errs {
+		details = append(details, ErrorDetail{
+			Field:   fieldErr.Field(),
+			Rule:    fieldErr.Tag(),
+			Message: ruleMessage(fieldErr),
+		})
+	}
+	return details
+}
+
+func ruleMessage(fieldErr validator.FieldError) string {
+	param := fieldErr.Param()
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + param
+	case "max", "lte":
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "lt":
+		return "must be less than " + param
+	case "len":
+		return "must have length " + param
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(param, " ", ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "datetime":
+		return "must be a date in the format " + param
+	case "gtefield":
+		return "must not be less than " + param
+	}
+
+	if enum, ok := bindingEnums[fieldErr.Tag()]; ok {
+		return "must be one of: " + strings.Join(enum, ", ")
+	}
+	return "failed the " + fieldErr.Tag() + " rule"
+}
+
+// jsonFieldName makes validation errors report the name a client sent,
+// taken from the json, form or uri tag of the field.
+func jsonFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name, _ := tagName(field.Tag.Get(key))
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"go.uber.org/mock/gomock"
+)
+
+// requireErrorCode checks that the response is an APIError with code.
+func requireErrorCode(t *testing.T, recorder *httptest.ResponseRecorder, code string) {
+	var body APIError
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Equal(t, code, body.Code, recorder.Body.String())
+	require.NotEmpty(t, body.Message)
+}
+
+func TestNewAPIError(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         int
+		err            error
+		expectedStatus int
+		expectedCode   string
+		// expectedMessage is checked when set.
+		expectedMessage string
+	}{
+		{
+			name:            "NoRows",
+			status:          http.StatusInternalServerError,
+			err:             fmt.Errorf("get account: %w", sql.ErrNoRows),
+			expectedStatus:  http.StatusNotFound,
+			expectedCode:    CodeNotFound,
+			expectedMessage: "resource not found",
+		},
+		{
+			name:           "UniqueViolation",
+			status:         http.StatusInternalServerError,
+			err:            &pq.Error{Code: "23505", Constraint: "users_email_key"},
+			expectedStatus: http.StatusConflict,
+			expectedCode:   CodeAlreadyExists,
+		},
+		{
+			name:           "ForeignKeyViolation",
+			status:         http.StatusInternalServerError,
+			err:            &pq.Error{Code: "23503"},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   CodeNotFound,
+		},
+		{
+			name:           "SerializationFailure",
+			status:         http.StatusInternalServerError,
+			err:            &pq.Error{Code: "40001"},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedCode:   CodeUnavailable,
+		},
+		{
+			name:            "OtherPostgresError",
+			status:          http.StatusBadRequest,
+			err:             &pq.Error{Code: "42P01", Message: `relation "accounts" does not exist`},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCode:    CodeInternal,
+			expectedMessage: "internal server error",
+		},
+		{
+			name:            "InternalHidesText",
+			status:          http.StatusInternalServerError,
+			err:             errors.New("dial tcp 10.0.0.5:5432: connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedCode:    CodeInternal,
+			expectedMessage: "internal server error",
+		},
+		{
+			name:            "StatusFallback",
+			status:          http.StatusBadRequest,
+			err:             errors.New("to must not be before from"),
+			expectedStatus:  http.StatusBadRequest,
+			expectedCode:    CodeInvalidArgument,
+			expectedMessage: "to must not be before from",
+		},
+		{
+			name:           "DomainSentinel",
+			status:         http.StatusInternalServerError,
+			err:            fmt.Errorf("account [1]: %w", errInsufficientBalance),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInsufficientFunds,
+		},
+		{
+			name:           "ExpiredToken",
+			status:         http.StatusForbidden,
+			err:            token.ErrExpiredToken,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   CodeTokenExpired,
+		},
+		{
+			name:           "InvalidJSON",
+			status:         http.StatusBadRequest,
+			err:            json.Unmarshal([]byte("{"), &struct{}{}),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   CodeInvalidArgument,
+		},
+		{
+			name:           "Nil",
+			status:         http.StatusBadRequest,
+			err:            nil,
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   CodeInternal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, apiErr := newAPIError(tc.status, tc.err)
+			require.Equal(t, tc.expectedStatus, status)
+			require.Equal(t, tc.expectedCode, apiErr.Code)
+			require.NotEmpty(t, apiErr.Message)
+			if tc.expectedMessage != "" {
+				require.Equal(t, tc.expectedMessage, apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestValidationErrorDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]any{"currency": "XYZ", "nickname": string(bytes.Repeat([]byte("n"), 65))})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set(requestIDHeader, "req-42")
+	addAuthorization(t, request, server.tokenGenerator, authTypeBearer, "someone", time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var apiErr APIError
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &apiErr))
+	require.Equal(t, CodeValidationFailed, apiErr.Code)
+	require.Equal(t, "req-42", apiErr.RequestID)
+	require.ElementsMatch(t, []ErrorDetail{
+		{Field: "currency", Rule: "oneof", Message: "must be one of: USD, EUR, IDR"},
+		{Field: "nickname", Rule: "max", Message: "must be at most 64"},
+	}, apiErr.Details)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+const (
+	defaultEventPollInterval = 500 * time.Millisecond
+	defaultEventLimit        = 100
+)
+
+type listEventsRequest struct {
+	After int64 `form:"after" binding:"min=0"`
+	Limit int32 `form:"limit" binding:"omitempty,min=1,max=1000"`
+	// Wait is how many seconds to hold the request open when there is
+	// nothing after the cursor yet.
+	Wait int `form:"wait" binding:"min=0,max=30"`
+}
+
+type listEventsResponse struct {
+	Events []db.Event `json:"events"`
+	// NextAfter is the cursor to pass as after on the next call.
+	NextAfter int64 `json:"next_after"`
+}
+
+// listEvents reads the ledger event stream after a sequence number. With
+// wait set it long-polls: an empty result is only returned once the wait is
+// over, so consumers can loop on next_after without hammering the database.
+func (server *Server) listEvents(ctx *gin.Context) {
+	var req listEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultEventLimit
+	}
+
+	deadline := time.Now().Add(time.Duration(req.Wait) * time.Second)
+	for {
+		events, err := server.store.ListEvents(ctx, db.ListEventsParams{
+			AfterSeq: req.After,
+			Limit:    req.Limit,
+		})
+		if err != nil {
+			writeError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		if len(events) > 0 || !time.Now().Before(deadline) {
+			response := listEventsResponse{Events: events, NextAfter: req.After}
+			if len(events) > 0 {
+				response.NextAfter = events[len(events)-1].Seq
+			}
+			ctx.JSON(http.StatusOK, response)
+			return
+		}
+
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-server.draining:
+			deadline = time.Now()
+		case <-time.After(server.eventPollInterval):
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListEventsAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	service, _ := randomUser()
+	service.Role = util.ServiceRole
+	depositor, _ := randomUser()
+
+	events := []db.Event{
+		{Seq: 41, EventID: uuid.New(), EventType: util.TransferCreatedEvent, AccountIds: []int64{1, 2}, Payload: json.RawMessage(`{}`)},
+		{Seq: 42, EventID: uuid.New(), EventType: util.BalanceSetEvent, AccountIds: []int64{1}, Payload: json.RawMessage(`{}`)},
+	}
+
+	testCases := []struct {
+		name         string
+		user         db.User
+		query        string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			user:  admin,
+			query: "after=40",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListEvents(gomock.Any(), db.ListEventsParams{AfterSeq: 40, Limit: defaultEventLimit}).
+					Times(1).Return(events, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response listEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Events, 2)
+				require.Equal(t, int64(42), response.NextAfter)
+			},
+		},
+		{
+			name:  "ServiceLongPoll",
+			user:  service,
+			query: "after=41&limit=10&wait=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), service.Username).Times(1).Return(service, nil)
+				gomock.InOrder(
+					store.EXPECT().ListEvents(gomock.Any(), db.ListEventsParams{AfterSeq: 41, Limit: 10}).
+						Times(2).Return([]db.Event{}, nil),
+					store.EXPECT().ListEvents(gomock.Any(), db.ListEventsParams{AfterSeq: 41, Limit: 10}).
+						Times(1).Return(events[1:], nil),
+				)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response listEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Len(t, response.Events, 1)
+				require.Equal(t, int64(42), response.NextAfter)
+			},
+		},
+		{
+			name:  "EmptyKeepsCursor",
+			user:  admin,
+			query: "after=42",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListEvents(gomock.Any(), gomock.Any()).Times(1).Return([]db.Event{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response listEventsResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Empty(t, response.Events)
+				require.Equal(t, int64(42), response.NextAfter)
+			},
+		},
+		{
+			name:  "Depositor",
+			user:  depositor,
+			query: "after=0",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().ListEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:  "WaitTooLong",
+			user:  admin,
+			query: "after=0&wait=120",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			server.eventPollInterval = time.Millisecond
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/events?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+type upsertFeeScheduleRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+	Tier     string `json:"tier" binding:"required,alphanum"`
+	FlatFee  int64  `json:"flat_fee" binding:"min=0"`
+	RatePpm  int64  `json:"rate_ppm" binding:"min=0,lte=1000000"`
+	MinFee   int64  `json:"min_fee" binding:"min=0"`
+	MaxFee   *int64 `json:"max_fee" binding:"omitempty,gtefield=MinFee"`
+}
+
+func (server *Server) upsertFeeSchedule(ctx *gin.Context) {
+	var req upsertFeeScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := server.store.UpsertFeeSchedule(ctx, db.UpsertFeeScheduleParams{
+		Currency: req.Currency,
+		Tier:     req.Tier,
+		FlatFee:  req.FlatFee,
+		RatePpm:  req.RatePpm,
+		MinFee:   req.MinFee,
+		MaxFee:   req.MaxFee,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+type updateAccountTierUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updateAccountTierJsonRequest struct {
+	Tier string `json:"tier" binding:"required,alphanum"`
+}
+
+func (server *Server) updateAccountTier(ctx *gin.Context) {
+	var reqUri updateAccountTierUriRequest
+	var reqJson updateAccountTierJsonRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	account, err := server.store.UpdateAccountTier(ctx, db.UpdateAccountTierParams{
+		ID:   reqUri.ID,
+		Tier: reqJson.Tier,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
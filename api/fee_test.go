@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestQuoteTransferAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	account := randomAccount()
+	account.Owner = testUser.Username
+	account.Currency = "USD"
+	account.Tier = util.StandardTier
+
+	maxFee := int64(500)
+	schedule := db.FeeSchedule{Currency: "USD", Tier: util.StandardTier, FlatFee: 25, RatePpm: 10_000, MaxFee: &maxFee}
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_account_id": account.ID, "amount": 1_000, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), db.GetFeeScheduleParams{Currency: "USD", Tier: util.StandardTier}).
+					Times(1).Return(schedule, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got quoteTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, int64(1_000), got.Amount)
+				require.Equal(t, int64(25), got.Fee.Flat)
+				require.Equal(t, int64(10), got.Fee.Percentage)
+				require.Equal(t, int64(35), got.Fee.Total)
+				require.Equal(t, int64(1_035), got.TotalDebit)
+			},
+		},
+		{
+			name: "NoSchedule",
+			body: gin.H{"from_account_id": account.ID, "amount": 1_000, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got quoteTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Zero(t, got.Fee.Total)
+				require.Equal(t, int64(1_000), got.TotalDebit)
+			},
+		},
+		{
+			name: "WithRecipient",
+			body: gin.H{"from_account_id": account.ID, "to_account_number": validAccountNumber, "amount": 1_000, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).
+					Return(db.GetAccountHolderByNumberRow{ID: 9, AccountNumber: validAccountNumber, Currency: "USD", FullName: "Jane Roe"}, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got quoteTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.NotNil(t, got.Recipient)
+				require.Equal(t, "J*** R**", got.Recipient.Holder)
+			},
+		},
+		{
+			name: "NotOwner",
+			body: gin.H{"from_account_id": account.ID, "amount": 1_000, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = "someone"
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(other, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			body: gin.H{"from_account_id": account.ID, "amount": 0, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/quote", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestFeeScheduleAdminAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	depositor, _ := randomUser()
+	depositor.Role = util.DepositorRole
+
+	testCases := []struct {
+		name         string
+		user         db.User
+		method       string
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "UpsertOK",
+			user:   admin,
+			method: http.MethodPut,
+			url:    "/admin/fee-schedules",
+			body:   gin.H{"currency": "EUR", "tier": "premium", "flat_fee": 0, "rate_ppm": 2_500, "min_fee": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), db.UpsertFeeScheduleParams{
+					Currency: "EUR", Tier: "premium", RatePpm: 2_500, MinFee: 10,
+				}).Times(1).Return(db.FeeSchedule{ID: 1}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "MaxBelowMin",
+			user:   admin,
+			method: http.MethodPut,
+			url:    "/admin/fee-schedules",
+			body:   gin.H{"currency": "EUR", "tier": "premium", "min_fee": 10, "max_fee": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:   "NotAdmin",
+			user:   depositor,
+			method: http.MethodPut,
+			url:    "/admin/fee-schedules",
+			body:   gin.H{"currency": "EUR", "tier": "premium"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), depositor.Username).Times(1).Return(depositor, nil)
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:   "List",
+			user:   admin,
+			method: http.MethodGet,
+			url:    "/admin/fee-schedules",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return([]db.FeeSchedule{{ID: 1}}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateTier",
+			user:   admin,
+			method: http.MethodPut,
+			url:    fmt.Sprintf("/admin/accounts/%d/tier", 7),
+			body:   gin.H{"tier": "premium"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpdateAccountTier(gomock.Any(), db.UpdateAccountTierParams{ID: 7, Tier: "premium"}).
+					Times(1).Return(db.Account{ID: 7, Tier: "premium"}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UpdateTierNotFound",
+			user:   admin,
+			method: http.MethodPut,
+			url:    fmt.Sprintf("/admin/accounts/%d/tier", 7),
+			body:   gin.H{"tier": "premium"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().UpdateAccountTier(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/logging"
+	"github.com/ulunnuha-h/simple_bank/metrics"
+	"github.com/ulunnuha-h/simple_bank/pb"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/tracing"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// rpcServer serves the SimpleBank gRPC service. It is a thin layer over
+// Server: requests are validated with the same binding rules as their JSON
+// counterparts and run through the same checks, so both APIs behave alike.
+type rpcServer struct {
+	pb.UnimplementedSimpleBankServer
+	server *Server
+}
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName:   true,
+	pb.SimpleBank_LoginUser_FullMethodName:    true,
+	pb.SimpleBank_RefreshToken_FullMethodName: true,
+}
+
+// rpcRateLimitGroups puts the calls that have an HTTP counterpart behind a
+// rate limit into the same group, so a client cannot get around a limit by
+// switching APIs.
+var rpcRateLimitGroups = map[string]rateLimitGroup{
+	pb.SimpleBank_CreateUser_FullMethodName:     signupRateLimit,
+	pb.SimpleBank_LoginUser_FullMethodName:      loginRateLimit,
+	pb.SimpleBank_CreateTransfer_FullMethodName: transferRateLimit,
+}
+
+type authPayloadContextKey struct{}
+
+// rpcCaller is filled in by authInterceptor so rpcLogger, which runs before
+// it, can log who made the call.
+type rpcCaller struct {
+	username string
+}
+
+type rpcCallerContextKey struct{}
+
+// requestIDMetadataKey carries the request id in gRPC metadata, where keys
+// are lower case.
+var requestIDMetadataKey = strings.ToLower(requestIDHeader)
+
+// NewGRPCServer returns a gRPC server with the SimpleBank service registered.
+// Calls go through the same steps as HTTP requests: request id, tracing,
+// logging, metrics, authentication, then rate limiting.
+func (server *Server) NewGRPCServer() *grpc.Server {
+	logger := slog.Default()
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestIDInterceptor(),
+		tracing.UnaryServerInterceptor(),
+		rpcLogger(logger),
+		metrics.UnaryServerInterceptor(),
+		authInterceptor(server.tokenGenerator),
+		server.rateLimitInterceptor(),
+	))
+	pb.RegisterSimpleBankServer(grpcServer, &rpcServer{server: server})
+	return grpcServer
+}
+
+// requestIDInterceptor is RequestIDMiddleware for gRPC: the id comes from
+// and goes back in the x-request-id metadata.
+func requestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var id string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+				id = values[0]
+			}
+		}
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, id))
+		return handler(logging.WithRequestID(ctx, id), req)
+	}
+}
+
+// rpcLogger is RequestLogger for gRPC: one line per call, without the
+// request or its metadata.
+func rpcLogger(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		caller := &rpcCaller{}
+		resp, err := handler(context.WithValue(ctx, rpcCallerContextKey{}, caller), req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Duration("latency", time.Since(start)),
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			attrs = append(attrs, slog.String("client_ip", p.Addr.String()))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+
+		logCtx := ctx
+		if caller.username != "" {
+			logCtx = logging.WithUsername(logCtx, caller.username)
+		}
+		logger.LogAttrs(logCtx, level, "rpc", attrs...)
+		return resp, err
+	}
+}
+
+// authInterceptor applies the rules of AuthMiddleware to the "authorization"
+// metadata of every call outside publicMethods.
+func authInterceptor(tokenGenerator token.Generator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var header string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(authHeaderKey); len(values) > 0 {
+				header = values[0]
+			}
+		}
+
+		payload, err := verifyAuthorization(tokenGenerator, header)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		if caller, ok := ctx.Value(rpcCallerContextKey{}).(*rpcCaller); ok {
+			caller.username = payload.Username
+		}
+		ctx = logging.WithUsername(ctx, payload.Username)
+		return handler(context.WithValue(ctx, authPayloadContextKey{}, payload), req)
+	}
+}
+
+// rateLimitInterceptor is rateLimit for gRPC. It runs after authInterceptor,
+// so public methods are keyed by peer address and the rest by username, like
+// their HTTP routes. A rejected call gets ResourceExhausted and the wait in
+// the retry-after header.
+func (server *Server) rateLimitInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		group, ok := rpcRateLimitGroups[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		result := server.takeRateLimit(ctx, group, rpcRateLimitKey(ctx))
+		if result != nil && !result.Allowed {
+			grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(retryAfterHeader), retryAfterSeconds(result)))
+			return nil, rpcError(http.StatusTooManyRequests, errRateLimited)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func rpcRateLimitKey(ctx context.Context) string {
+	if payload, ok := ctx.Value(authPayloadContextKey{}).(*token.Payload); ok {
+		return "user:" + payload.Username
+	}
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+	return "ip:" + ip
+}
+
+func rpcAuthPayload(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadContextKey{}).(*token.Payload)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, token.ErrAuthNotProvided.Error())
+	}
+	return payload, nil
+}
+
+// rpcValidate checks req against its binding tags, the way ShouldBindJSON
+// does for the HTTP API, and reports the fields that failed the same way.
+func rpcValidate(req any) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return rpcError(http.StatusBadRequest, err)
+	}
+	return nil
+}
+
+// rpcError classifies err like the HTTP API does and turns the result into a
+// gRPC status.
+func rpcError(httpStatus int, err error) error {
+	httpStatus, apiErr := newAPIError(httpStatus, err)
+	code := codes.Internal
+	switch httpStatus {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.FailedPrecondition
+		if apiErr.Code == CodeAlreadyExists {
+			code = codes.AlreadyExists
+		}
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	}
+
+	st := status.New(code, apiErr.Message)
+	if len(apiErr.Details) == 0 {
+		return st.Err()
+	}
+
+	// Invalid fields go in a BadRequest, where gRPC clients look for them.
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(apiErr.Details))
+	for _, detail := range apiErr.Details {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       detail.Field,
+			Description: detail.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Discoverable:      user.Discoverable,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:                account.ID,
+		Owner:             account.Owner,
+		Balance:           account.Balance,
+		Currency:          account.Currency,
+		AccountType:       account.AccountType,
+		InterestProductId: account.InterestProductID,
+		Nickname:          account.Nickname,
+		AccountNumber:     account.AccountNumber,
+		Tier:              account.Tier,
+		Frozen:            account.Frozen,
+		CreatedAt:         timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) (*pb.Transfer, error) {
+	var metadata map[string]string
+	if len(transfer.Metadata) > 0 {
+		if err := json.Unmarshal(transfer.Metadata, &metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Fee:           transfer.Fee,
+		Memo:          transfer.Memo,
+		Reference:     transfer.Reference,
+		Metadata:      metadata,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}, nil
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		TransferId: entry.TransferID,
+		Memo:       entry.Memo,
+		Reference:  entry.Reference,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/pb"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func (rpc *rpcServer) CreateAccount(ctx context.Context, in *pb.CreateAccountRequest) (*pb.Account, error) {
+	req := createAccountRequest{
+		Currency:          in.GetCurrency(),
+		AccountType:       in.GetAccountType(),
+		InterestProductID: in.InterestProductId,
+		Nickname:          in.GetNickname(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	authPayload, err := rpcAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, status, err := rpc.server.openAccount(ctx, authPayload.Username, req)
+	if err != nil {
+		return nil, rpcError(status, err)
+	}
+
+	return convertAccount(account), nil
+}
+
+func (rpc *rpcServer) GetAccount(ctx context.Context, in *pb.GetAccountRequest) (*pb.Account, error) {
+	req := getAccountRequest{ID: in.GetId()}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	authPayload, err := rpcAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, status, err := rpc.server.findAccount(ctx, req.ID, authPayload.Username, util.ViewPermission)
+	if err != nil {
+		return nil, rpcError(status, err)
+	}
+
+	return convertAccount(account), nil
+}
+
+func (rpc *rpcServer) ListAccounts(ctx context.Context, in *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	req := listAccountRequest{
+		PAGE_ID:     in.GetPageId(),
+		PAGE_SIZE:   in.GetPageSize(),
+		Currency:    in.GetCurrency(),
+		AccountType: in.GetAccountType(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	authPayload, err := rpcAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	args := db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PAGE_SIZE,
+		Offset: (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	}
+	if req.Currency != "" {
+		args.Currency = &req.Currency
+	}
+	if req.AccountType != "" {
+		args.AccountType = &req.AccountType
+	}
+
+	accounts, err := rpc.server.store.ListAccounts(ctx, args)
+	if err != nil {
+		return nil, rpcError(http.StatusInternalServerError, err)
+	}
+
+	response := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, 0, len(accounts))}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+	return response, nil
+}
@@ -0,0 +1,376 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/logging"
+	"github.com/ulunnuha-h/simple_bank/pb"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestRPCClient serves the gRPC API of server over an in-memory listener
+// and returns a client connected to it.
+func newTestRPCClient(t *testing.T, server *Server) pb.SimpleBankClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSimpleBankClient(conn)
+}
+
+func rpcAuthContext(t *testing.T, server *Server, username string) context.Context {
+	_, accessToken, err := server.tokenGenerator.CreateToken(username, time.Minute)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), authHeaderKey, fmt.Sprintf("%s %s", authTypeBearer, accessToken))
+}
+
+func TestGRPCGetAccount(t *testing.T) {
+	user, _ := randomUser()
+	account := randomAccount()
+	account.Owner = user.Username
+
+	testCases := []struct {
+		name       string
+		ctx        func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, res *pb.Account, err error)
+	}{
+		{
+			name: "OK",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return rpcAuthContext(t, server, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			},
+			check: func(t *testing.T, res *pb.Account, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetId())
+				require.Equal(t, account.Balance, res.GetBalance())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.Account, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "UnsupportedAuthorization",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authHeaderKey, "basic abc")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.Account, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "NotOwner",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return rpcAuthContext(t, server, "someoneelse")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, Username: "someoneelse"}).
+					Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			check: func(t *testing.T, res *pb.Account, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			ctx: func(t *testing.T, server *Server) context.Context {
+				return rpcAuthContext(t, server, user.Username)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			check: func(t *testing.T, res *pb.Account, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			client := newTestRPCClient(t, server)
+
+			res, err := client.GetAccount(tc.ctx(t, server), &pb.GetAccountRequest{Id: account.ID})
+			tc.check(t, res, err)
+		})
+	}
+}
+
+func TestGRPCLoginUser(t *testing.T) {
+	user, password := randomUser()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user.HashedPassword = hashedPassword
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, args db.CreateSessionParams) (db.Session, error) {
+			require.Equal(t, user.Username, args.Username)
+			require.Contains(t, args.UserAgent, "grpc-go")
+			return db.Session{ID: args.ID, Username: args.Username}, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	client := newTestRPCClient(t, server)
+
+	res, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, res.GetUser().GetUsername())
+	require.NotEmpty(t, res.GetSessionId())
+
+	payload, err := server.tokenGenerator.Verify(res.GetAccessToken())
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+
+	_, err = client.LoginUser(context.Background(), &pb.LoginUserRequest{Username: "not valid!", Password: password})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCCreateTransfer(t *testing.T) {
+	user, _ := randomUser()
+	from := randomAccount()
+	from.Owner = user.Username
+	from.Currency = "USD"
+	from.Balance = 1000
+	to := randomAccount()
+	to.ID = from.ID + 1
+	to.Currency = "USD"
+
+	testCases := []struct {
+		name       string
+		req        *pb.CreateTransferRequest
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateTransferRequest{FromAccountId: from.ID, ToAccountId: to.ID, Amount: 10, Currency: "USD", Metadata: map[string]string{"order": "42"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(to, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountId: from.ID, ToAccountId: to.ID, Amount: 10, Metadata: map[string]string{"order": "42"},
+				}).Times(1).Return(db.TransferTxResult{
+					Transfer: db.Transfer{ID: 7, FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10, Metadata: []byte(`{"order":"42"}`)},
+				}, nil)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), res.GetTransfer().GetId())
+				require.Equal(t, "42", res.GetTransfer().GetMetadata()["order"])
+			},
+		},
+		{
+			name: "MissingRecipient",
+			req:  &pb.CreateTransferRequest{FromAccountId: from.ID, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientBalance",
+			req:  &pb.CreateTransferRequest{FromAccountId: from.ID, ToAccountId: to.ID, Amount: 5000, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "FrozenRecipient",
+			req:  &pb.CreateTransferRequest{FromAccountId: from.ID, ToAccountId: to.ID, Amount: 10, Currency: "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				frozen := to
+				frozen.Frozen = true
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(frozen, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			check: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			client := newTestRPCClient(t, server)
+
+			res, err := client.CreateTransfer(rpcAuthContext(t, server, user.Username), tc.req)
+			tc.check(t, res, err)
+		})
+	}
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	logs := captureLogs(t)
+
+	user, _ := randomUser()
+	account := randomAccount()
+	account.Owner = user.Username
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).
+		DoAndReturn(func(ctx context.Context, _ int64) (db.Account, error) {
+			require.Equal(t, "req-123", logging.RequestID(ctx))
+			return account, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	client := newTestRPCClient(t, server)
+
+	ctx := metadata.AppendToOutgoingContext(rpcAuthContext(t, server, user.Username), requestIDMetadataKey, "req-123")
+
+	var header metadata.MD
+	_, err = client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, []string{"req-123"}, header.Get(requestIDMetadataKey))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	require.Equal(t, "rpc", line["msg"])
+	require.Equal(t, pb.SimpleBank_GetAccount_FullMethodName, line["method"])
+	require.Equal(t, codes.OK.String(), line["code"])
+	require.Equal(t, "req-123", line["request_id"])
+	require.Equal(t, user.Username, line["username"])
+
+	// Calls failing authentication are logged too, with a fresh request id.
+	logs.Reset()
+	_, err = client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Len(t, header.Get(requestIDMetadataKey), 1)
+
+	line = nil
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	require.Equal(t, codes.Unauthenticated.String(), line["code"])
+	require.NotContains(t, line, "username")
+	require.Equal(t, header.Get(requestIDMetadataKey)[0], line["request_id"])
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_LOGIN", "1/1m")
+	setConfig(t, "RATE_LIMIT_TRANSFERS", "1/1m")
+	server := newRateLimitedServer(t)
+	client := newTestRPCClient(t, server)
+
+	// Invalid requests are rejected without touching the store, so any other
+	// code comes from the rate limiter.
+	_, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	var header metadata.MD
+	_, err = client.LoginUser(context.Background(), &pb.LoginUserRequest{}, grpc.Header(&header))
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, []string{"60"}, header.Get("retry-after"))
+
+	// After authentication calls are keyed by username.
+	_, err = client.CreateTransfer(rpcAuthContext(t, server, "alice"), &pb.CreateTransferRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CreateTransfer(rpcAuthContext(t, server, "alice"), &pb.CreateTransferRequest{})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = client.CreateTransfer(rpcAuthContext(t, server, "bob"), &pb.CreateTransferRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The HTTP API draws from the same bucket.
+	recorder := postEmpty(server, "/transfers", "203.0.113.7:4000", func(request *http.Request) {
+		addAuthorization(t, request, server.tokenGenerator, authTypeBearer, "bob", time.Minute)
+	})
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// Calls without a limit are not affected.
+	_, err = client.ListAccounts(rpcAuthContext(t, server, "alice"), &pb.ListAccountsRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCValidationDetails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+	client := newTestRPCClient(t, server)
+
+	_, err = client.CreateTransfer(rpcAuthContext(t, server, "alice"), &pb.CreateTransferRequest{
+		FromAccountId: 1, ToAccountId: 2, Amount: -5, Currency: "USD",
+	})
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, "request validation failed", st.Message())
+	require.NotContains(t, st.Message(), "Key:")
+
+	// The fields are reported as the HTTP API reports them.
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.GetFieldViolations(), 1)
+	require.Equal(t, "amount", badRequest.GetFieldViolations()[0].GetField())
+	require.Equal(t, "must be greater than 0", badRequest.GetFieldViolations()[0].GetDescription())
+}
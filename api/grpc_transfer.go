@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/pb"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer moves money to an account given by id. Payees, account
+// numbers and handles are only resolved by the HTTP API.
+func (rpc *rpcServer) CreateTransfer(ctx context.Context, in *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	req := createTransferRequest{
+		FromAccountId: in.GetFromAccountId(),
+		ToAccountId:   in.GetToAccountId(),
+		Amount:        in.GetAmount(),
+		Currency:      in.GetCurrency(),
+		Memo:          in.GetMemo(),
+		Reference:     in.GetReference(),
+		Metadata:      in.GetMetadata(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	if req.ToAccountId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "to_account_id is required")
+	}
+
+	if err := validateMetadata(req.Metadata); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	authPayload, err := rpcAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fromAccount, httpStatus, err := rpc.server.findCheckedAccount(ctx, req.FromAccountId, req.Currency, req.Amount, true, authPayload.Username)
+	if err != nil {
+		return nil, rpcError(httpStatus, err)
+	}
+
+	if _, httpStatus, err := rpc.server.findCheckedAccount(ctx, req.ToAccountId, req.Currency, 0, false, ""); err != nil {
+		return nil, rpcError(httpStatus, err)
+	}
+
+	if _, httpStatus, err := rpc.server.checkFee(ctx, fromAccount, req.Amount); err != nil {
+		return nil, rpcError(httpStatus, err)
+	}
+
+	result, err := rpc.server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountId: req.FromAccountId,
+		ToAccountId:   req.ToAccountId,
+		Amount:        req.Amount,
+		Memo:          req.Memo,
+		Reference:     req.Reference,
+		Metadata:      req.Metadata,
+	})
+	if err != nil {
+		return nil, rpcError(http.StatusInternalServerError, err)
+	}
+
+	transfer, err := convertTransfer(result.Transfer)
+	if err != nil {
+		return nil, rpcError(http.StatusInternalServerError, err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    transfer,
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+		Fee:         result.Fee.Total,
+	}, nil
+}
+
+func (rpc *rpcServer) ListTransfers(ctx context.Context, in *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	req := listTransfersRequest{
+		AccountID: in.GetAccountId(),
+		Reference: in.GetReference(),
+		PAGE_ID:   in.GetPageId(),
+		PAGE_SIZE: in.GetPageSize(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	authPayload, err := rpcAuthPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, httpStatus, err := rpc.server.findAccount(ctx, req.AccountID, authPayload.Username, util.ViewPermission); err != nil {
+		return nil, rpcError(httpStatus, err)
+	}
+
+	args := db.ListTransfersParams{
+		FromAccountID: req.AccountID,
+		ToAccountID:   req.AccountID,
+		Limit:         req.PAGE_SIZE,
+		Offset:        (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	}
+	if req.Reference != "" {
+		args.Reference = &req.Reference
+	}
+
+	transfers, err := rpc.server.store.ListTransfers(ctx, args)
+	if err != nil {
+		return nil, rpcError(http.StatusInternalServerError, err)
+	}
+
+	response := &pb.ListTransfersResponse{Transfers: make([]*pb.Transfer, 0, len(transfers))}
+	for _, transfer := range transfers {
+		converted, err := convertTransfer(transfer)
+		if err != nil {
+			return nil, rpcError(http.StatusInternalServerError, err)
+		}
+		response.Transfers = append(response.Transfers, converted)
+	}
+	return response, nil
+}
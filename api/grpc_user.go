@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/ulunnuha-h/simple_bank/pb"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (rpc *rpcServer) CreateUser(ctx context.Context, in *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	req := createUserRequest{
+		Username: in.GetUsername(),
+		Password: in.GetPassword(),
+		FullName: in.GetFullName(),
+		Email:    in.GetEmail(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	user, status, err := rpc.server.registerUser(ctx, req)
+	if err != nil {
+		return nil, rpcError(status, err)
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(user)}, nil
+}
+
+func (rpc *rpcServer) LoginUser(ctx context.Context, in *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	req := loginUserRequest{
+		Username: in.GetUsername(),
+		Password: in.GetPassword(),
+	}
+	if err := rpcValidate(&req); err != nil {
+		return nil, err
+	}
+
+	user, status, err := rpc.server.authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		return nil, rpcError(status, err)
+	}
+
+	userAgent, clientIP := rpcClient(ctx)
+	login, err := rpc.server.startSession(ctx, user.Username, userAgent, clientIP)
+	if err != nil {
+		return nil, rpcError(http.StatusInternalServerError, err)
+	}
+
+	return &pb.LoginUserResponse{
+		User:                  convertUser(user),
+		SessionId:             login.session.ID,
+		AccessToken:           login.accessToken,
+		AccessTokenExpiresAt:  timestamppb.New(login.accessPayload.ExpiredAt),
+		RefreshToken:          login.refreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(login.refreshPayload.ExpiredAt),
+	}, nil
+}
+
+func (rpc *rpcServer) RefreshToken(ctx context.Context, in *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	session, accessToken, accessPayload, status, err := rpc.server.renewAccessToken(ctx, in.GetRefreshToken())
+	if err != nil {
+		return nil, rpcError(status, err)
+	}
+
+	return &pb.RefreshTokenResponse{
+		Username:             session.Username,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}, nil
+}
+
+// rpcClient returns the user agent and address of the caller, which are
+// recorded on the session like the HTTP API does.
+func rpcClient(ctx context.Context) (userAgent string, clientIP string) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("user-agent"); len(values) > 0 {
+			userAgent = values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+
+	return userAgent, clientIP
+}
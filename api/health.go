@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const readinessTimeout = 2 * time.Second
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+	// Checks holds the result of each readiness check by name.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// healthz reports that the process is up. It never touches the database,
+// so a database outage doesn't get the process restarted.
+func (server *Server) healthz(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: statusOK})
+}
+
+// readyz reports whether the server should receive traffic: the database
+// must answer and be migrated at least to the version this build expects,
+// and the server must not be shutting down. A newer schema is fine: during a
+// rollout the new build migrates while the old one still serves.
+func (server *Server) readyz(ctx *gin.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	response := healthResponse{
+		Status: statusOK,
+		Checks: map[string]string{
+			"database":   statusOK,
+			"migrations": statusOK,
+			"shutdown":   statusOK,
+		},
+	}
+	fail := func(check string, reason string) {
+		response.Status = statusUnavailable
+		response.Checks[check] = reason
+	}
+
+	select {
+	case <-server.draining:
+		fail("shutdown", "draining")
+	default:
+	}
+
+	if err := server.store.Ping(checkCtx); err != nil {
+		fail("database", err.Error())
+		fail("migrations", "unknown")
+	} else if version, dirty, err := server.store.MigrationVersion(checkCtx); err != nil {
+		fail("migrations", err.Error())
+	} else if dirty {
+		fail("migrations", fmt.Sprintf("version %d is dirty", version))
+	} else if version < server.schemaVersion {
+		fail("migrations", fmt.Sprintf("at version %d, want at least %d", version, server.schemaVersion))
+	}
+
+	if response.Status != statusOK {
+		ctx.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+type createInterestProductRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	AnnualRatePpm int64  `json:"annual_rate_ppm" binding:"required,gt=0,lte=1000000"`
+	DayCount      string `json:"day_count" binding:"required,daycount"`
+	Compounding   string `json:"compounding" binding:"required,compounding"`
+}
+
+func (server *Server) createInterestProduct(ctx *gin.Context) {
+	var req createInterestProductRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	product, err := server.store.CreateInterestProduct(ctx, db.CreateInterestProductParams{
+		Name:          req.Name,
+		Currency:      req.Currency,
+		AnnualRatePpm: req.AnnualRatePpm,
+		DayCount:      req.DayCount,
+		Compounding:   req.Compounding,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+type listInterestProductsRequest struct {
+	PAGE_ID   int32 `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listInterestProducts(ctx *gin.Context) {
+	var req listInterestProductsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	products, err := server.store.ListInterestProducts(ctx, db.ListInterestProductsParams{
+		Limit:  req.PAGE_SIZE,
+		Offset: (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
+}
+
+func (server *Server) checkInterestProduct(ctx context.Context, productID int64, accountType string, currency string) (int, error) {
+	if accountType != util.SavingsAccount {
+		return http.StatusBadRequest, fmt.Errorf("only %s accounts can earn interest", util.SavingsAccount)
+	}
+
+	product, err := server.store.GetInterestProduct(ctx, productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return http.StatusBadRequest, fmt.Errorf("interest product [%d] does not exist", productID)
+		}
+		return http.StatusInternalServerError, err
+	}
+
+	if product.Currency != currency {
+		return http.StatusBadRequest, fmt.Errorf("interest product [%d]: %w: %s and %s", productID, errCurrencyMismatch, product.Currency, currency)
+	}
+
+	return http.StatusOK, nil
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/interest"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateSavingsAccountAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	productID := int64(4)
+	product := db.InterestProduct{ID: productID, Currency: "EUR", AnnualRatePpm: 20_000}
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"currency": "EUR", "account_type": util.SavingsAccount, "interest_product_id": productID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInterestProduct(gomock.Any(), productID).Times(1).Return(product, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:             testUser.Username,
+						Currency:          "EUR",
+						AccountType:       util.SavingsAccount,
+						InterestProductID: &productID,
+					},
+				}).Times(1).Return(db.Account{ID: 1, Owner: testUser.Username, Currency: "EUR", AccountType: util.SavingsAccount, InterestProductID: &productID}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "CheckingWithProduct",
+			body: gin.H{"currency": "EUR", "interest_product_id": productID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{"currency": "USD", "account_type": util.SavingsAccount, "interest_product_id": productID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInterestProduct(gomock.Any(), productID).Times(1).Return(product, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeCurrencyMismatch)
+			},
+		},
+		{
+			name: "UnknownProduct",
+			body: gin.H{"currency": "EUR", "account_type": util.SavingsAccount, "interest_product_id": productID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetInterestProduct(gomock.Any(), productID).Times(1).Return(db.InterestProduct{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestCreateInterestProductAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": "Saver", "currency": "USD", "annual_rate_ppm": 41_250, "day_count": string(interest.Actual365), "compounding": string(interest.CompoundMonthly)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().CreateInterestProduct(gomock.Any(), db.CreateInterestProductParams{
+					Name: "Saver", Currency: "USD", AnnualRatePpm: 41_250, DayCount: "ACT/365", Compounding: "monthly",
+				}).Times(1).Return(db.InterestProduct{ID: 1}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDayCount",
+			body: gin.H{"name": "Saver", "currency": "USD", "annual_rate_ppm": 41_250, "day_count": "ACT/999", "compounding": "monthly"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), admin.Username).Times(1).Return(admin, nil)
+				store.EXPECT().CreateInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/interest-products", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/logging"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+// captureLogs sends the default logger to a buffer for the rest of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelDebug))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestLogsNeverContainSecrets(t *testing.T) {
+	logs := captureLogs(t)
+
+	user, password := randomUser()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user.HashedPassword = hashedPassword
+	account := randomAccount()
+	account.Owner = user.Username
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, args db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: args.ID, Username: args.Username, RefreshToken: args.RefreshToken, ExpiredAt: args.ExpiredAt}, nil
+		})
+	store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.Session{Username: user.Username, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+
+	// Log in with the password in the body.
+	body, err := json.Marshal(loginUserRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body))
+	require.NoError(t, err)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var login loginUserReponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &login))
+	cookies := recorder.Result().Cookies()
+	require.NotEmpty(t, cookies)
+	refreshToken := cookies[0].Value
+
+	// Refresh with the cookie.
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/users/refresh", nil)
+	require.NoError(t, err)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	request.Header.Set(csrfHeader, login.CSRFToken)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Call an authenticated route with the access token and a request id.
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	request.Header.Set(authHeaderKey, authTypeBearer+" "+login.AccessToken)
+	request.Header.Set(requestIDHeader, "test-request-1")
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "test-request-1", recorder.Header().Get(requestIDHeader))
+
+	// A bad token quoted back in an error must not leak either.
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/accounts", nil)
+	require.NoError(t, err)
+	request.Header.Set(authHeaderKey, "Bearer "+refreshToken+"x")
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	requireErrorCode(t, recorder, CodeInvalidToken)
+
+	out := logs.String()
+	for _, secret := range []string{password, user.HashedPassword, login.AccessToken, refreshToken} {
+		require.NotContains(t, out, secret)
+	}
+
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		lines = append(lines, entry)
+	}
+	require.Len(t, lines, 4)
+
+	accountLine := lines[2]
+	require.Equal(t, "test-request-1", accountLine["request_id"])
+	require.Equal(t, user.Username, accountLine["username"])
+	require.Equal(t, "/accounts/:id", accountLine["route"])
+	require.EqualValues(t, http.StatusOK, accountLine["status"])
+
+	for _, line := range lines {
+		require.NotEmpty(t, line["request_id"])
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		header string
+		reused bool
+	}{
+		{name: "Generated", header: "", reused: false},
+		{name: "Reused", header: "abc-123", reused: true},
+		{name: "TooLong", header: strings.Repeat("a", maxRequestIDLength+1), reused: false},
+		{name: "NotPrintable", header: "abc\x01", reused: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				request.Header.Set(requestIDHeader, tc.header)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+
+			id := recorder.Header().Get(requestIDHeader)
+			require.NotEmpty(t, id)
+			if tc.reused {
+				require.Equal(t, tc.header, id)
+			} else {
+				require.NotEqual(t, tc.header, id)
+			}
+		})
+	}
+}
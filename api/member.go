@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var errMemberIsOwner = errors.New("the account owner cannot be added as a member")
+
+type accountMembersUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type addAccountMemberRequest struct {
+	Username   string `json:"username" binding:"required,alphanum"`
+	Permission string `json:"permission" binding:"required,permission"`
+}
+
+// loadAccount fetches the account in the :id path segment and checks that
+// the caller holds permission on it.
+func (server *Server) loadAccount(ctx *gin.Context, accountID int64, username string, permission string) (db.Account, bool) {
+	account, status, err := server.findAccount(ctx, accountID, username, permission)
+	if err != nil {
+		writeError(ctx, status, err)
+		return account, false
+	}
+
+	return account, true
+}
+
+// findAccount is loadAccount without writing a response.
+func (server *Server) findAccount(ctx context.Context, accountID int64, username string, permission string) (db.Account, int, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, http.StatusNotFound, err
+		}
+		return account, http.StatusInternalServerError, err
+	}
+
+	if status, err := server.checkPermission(ctx, account, username, permission); err != nil {
+		return account, status, err
+	}
+
+	return account, http.StatusOK, nil
+}
+
+func (server *Server) addAccountMember(ctx *gin.Context) {
+	var reqUri accountMembersUriRequest
+	var reqJson addAccountMemberRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	account, ok := server.loadAccount(ctx, reqUri.ID, authPayload.Username, util.OwnerPermission)
+	if !ok {
+		return
+	}
+
+	if reqJson.Username == account.Owner {
+		writeError(ctx, http.StatusBadRequest, errMemberIsOwner)
+		return
+	}
+
+	member, err := server.store.AddAccountMember(ctx, db.AddAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   reqJson.Username,
+		Permission: reqJson.Permission,
+		InvitedBy:  authPayload.Username,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var req accountMembersUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	if _, ok := server.loadAccount(ctx, req.ID, authPayload.Username, util.ViewPermission); !ok {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, req.ID)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type removeAccountMemberRequest struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// removeAccountMember revokes a member's access. Members may always remove
+// themselves; only the owner may remove anyone else.
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var req removeAccountMemberRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	permission := util.OwnerPermission
+	if req.Username == authPayload.Username {
+		permission = util.ViewPermission
+	}
+
+	if _, ok := server.loadAccount(ctx, req.ID, authPayload.Username, permission); !ok {
+		return
+	}
+
+	removed, err := server.store.RemoveAccountMember(ctx, db.RemoveAccountMemberParams{
+		AccountID: req.ID,
+		Username:  req.Username,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if removed == 0 {
+		writeError(ctx, http.StatusNotFound, sql.ErrNoRows)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
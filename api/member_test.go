@@ -0,0 +1,355 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccountMembersAPI(t *testing.T) {
+	owner, _ := randomUser()
+	partner, _ := randomUser()
+	account := randomAccount()
+	account.Owner = owner.Username
+
+	membership := func(permission string) db.AccountMember {
+		return db.AccountMember{AccountID: account.ID, Username: partner.Username, Permission: permission, InvitedBy: owner.Username}
+	}
+
+	testCases := []struct {
+		name         string
+		username     string
+		method       string
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "InviteOK",
+			username: owner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": partner.Username, "permission": util.TransferPermission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), db.AddAccountMemberParams{
+					AccountID:  account.ID,
+					Username:   partner.Username,
+					Permission: util.TransferPermission,
+					InvitedBy:  owner.Username,
+				}).Times(1).Return(membership(util.TransferPermission), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			// Managing an account lets a member rename it, not invite others.
+			name:     "InviteByManager",
+			username: partner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": "accountant", "permission": util.ViewPermission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, Username: partner.Username}).
+					Times(1).Return(membership(util.ManagePermission), nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "InviteWithoutManage",
+			username: partner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": "accountant", "permission": util.ViewPermission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.TransferPermission), nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "InviteOwner",
+			username: owner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": owner.Username, "permission": util.ViewPermission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "InviteUnknownUser",
+			username: owner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": partner.Username, "permission": util.ViewPermission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountMember{}, &pq.Error{Code: "23503"})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:     "InvalidPermission",
+			username: owner.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			body:     gin.H{"username": partner.Username, "permission": "owner"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:     "ListByViewer",
+			username: partner.Username,
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.ViewPermission), nil)
+				store.EXPECT().ListAccountMembers(gomock.Any(), account.ID).Times(1).
+					Return([]db.AccountMember{membership(util.ViewPermission)}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ListByStranger",
+			username: "stranger",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/accounts/%d/members", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListAccountMembers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "RemoveByOwner",
+			username: owner.Username,
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/accounts/%d/members/%s", account.ID, partner.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), db.RemoveAccountMemberParams{AccountID: account.ID, Username: partner.Username}).
+					Times(1).Return(int64(1), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RemoveByManager",
+			username: partner.Username,
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/accounts/%d/members/%s", account.ID, "accountant"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.ManagePermission), nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "DeleteAccountByManager",
+			username: partner.Username,
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.ManagePermission), nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "RenameByManager",
+			username: partner.Username,
+			method:   http.MethodPut,
+			url:      fmt.Sprintf("/accounts/%d/nickname", account.ID),
+			body:     gin.H{"nickname": "Shared"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.ManagePermission), nil)
+				store.EXPECT().UpdateAccountNickname(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "LeaveAccount",
+			username: partner.Username,
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/accounts/%d/members/%s", account.ID, partner.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(util.ViewPermission), nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RemoveUnknownMember",
+			username: owner.Username,
+			method:   http.MethodDelete,
+			url:      fmt.Sprintf("/accounts/%d/members/%s", account.ID, "nobody"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestTransferByMember(t *testing.T) {
+	owner, _ := randomUser()
+	partner, _ := randomUser()
+	fromAccount := randomAccount()
+	fromAccount.Owner = owner.Username
+	fromAccount.Currency = "USD"
+	fromAccount.Balance = 100
+	toAccount := randomAccount()
+	toAccount.Currency = "USD"
+
+	testCases := []struct {
+		name         string
+		permission   string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "TransferPermission",
+			permission: util.TransferPermission,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "ViewPermission",
+			permission: util.ViewPermission,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: fromAccount.ID, Username: partner.Username}).
+				Times(1).Return(db.AccountMember{AccountID: fromAccount.ID, Username: partner.Username, Permission: tc.permission}, nil)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(createTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        1,
+				Currency:      "USD",
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, partner.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/logging"
 	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/util"
 )
 
 const (
@@ -18,34 +22,69 @@ const (
 
 func AuthMiddleware(tokenGenerator token.Generator) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		if len(ctx.GetHeader(authHeaderKey)) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		payload, err := verifyAuthorization(tokenGenerator, ctx.GetHeader(authHeaderKey))
+		if err != nil {
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
-		fields := strings.Fields(ctx.GetHeader(authHeaderKey))
-		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		ctx.Set(authPayloadKey, payload)
+		ctx.Request = ctx.Request.WithContext(logging.WithUsername(ctx.Request.Context(), payload.Username))
+		ctx.Next()
+	}
+}
+
+// verifyAuthorization checks an "authorization" header value and returns the
+// payload of its bearer token. The gRPC server applies it to metadata.
+func verifyAuthorization(tokenGenerator token.Generator, header string) (*token.Payload, error) {
+	if len(header) == 0 {
+		return nil, errors.New("authorization header is not provided")
+	}
+
+	fields := strings.Fields(header)
+	if len(fields) < 2 {
+		return nil, errors.New("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authType)
+	}
+
+	return tokenGenerator.Verify(fields[1])
+}
+
+// AdminMiddleware must run after AuthMiddleware. The role is read from the
+// database on every request, so demoting an admin takes effect immediately.
+func AdminMiddleware(store db.Store) gin.HandlerFunc {
+	return roleMiddleware(store, token.ErrAdminOnly, util.AdminRole)
+}
+
+// RoleMiddleware lets through users holding any of roles. Like
+// AdminMiddleware it must run after AuthMiddleware.
+func RoleMiddleware(store db.Store, roles ...string) gin.HandlerFunc {
+	return roleMiddleware(store, token.ErrActionForbidden, roles...)
+}
+
+func roleMiddleware(store db.Store, forbidden error, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload, err := GetAuthPayload(ctx)
+		if err != nil {
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
-		authType := strings.ToLower(fields[0])
-		if authType != authTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		user, err := store.GetUser(ctx, authPayload.Username)
+		if err != nil {
+			abortWithError(ctx, http.StatusInternalServerError, err)
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenGenerator.Verify(accessToken)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		if !slices.Contains(roles, user.Role) {
+			abortWithError(ctx, http.StatusForbidden, forbidden)
 			return
 		}
 
-		ctx.Set(authPayloadKey, payload)
 		ctx.Next()
 	}
 }
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -47,6 +49,7 @@ func TestAuthMiddleware(t *testing.T){
 			setupAuth: func(t *testing.T, request *http.Request, tokenGenerator token.Generator){},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder, CodeUnauthenticated)
 			},
 		},
 		{
@@ -56,6 +59,7 @@ func TestAuthMiddleware(t *testing.T){
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder){
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder, CodeTokenExpired)
 			},
 		},
 	}
@@ -83,4 +87,29 @@ func TestAuthMiddleware(t *testing.T){
 			tc.checkResponse(t, recorder)
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestAuthMiddlewareLogsUsername checks that what handlers log carries the
+// username, not only the request line.
+func TestAuthMiddlewareLogsUsername(t *testing.T) {
+	logs := captureLogs(t)
+
+	server, err := NewServer(nil)
+	require.NoError(t, err)
+	server.router.GET("/auth", AuthMiddleware(server.tokenGenerator), func(ctx *gin.Context) {
+		slog.InfoContext(ctx, "handler")
+		ctx.JSON(http.StatusOK, gin.H{})
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/auth", nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenGenerator, authTypeBearer, "alice", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var line map[string]any
+	require.NoError(t, json.NewDecoder(logs).Decode(&line))
+	require.Equal(t, "handler", line["msg"])
+	require.Equal(t, "alice", line["username"])
+}
@@ -0,0 +1,617 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/interest"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+	"github.com/ulunnuha-h/simple_bank/statement"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// apiOperation describes one route for the OpenAPI document. Parameters and
+// bodies are derived from the same structs the handler binds, so their names,
+// types and binding rules can't drift from the code.
+type apiOperation struct {
+	method  string
+	path    string
+	id      string
+	summary string
+	tag     string
+	// public operations need no bearer token.
+	public bool
+	// roles, when set, are the user roles allowed besides the bearer token.
+	roles []string
+
+	uri   any
+	query any
+	body  any
+	// form is a multipart body; file fields are listed separately.
+	form      any
+	formFiles []string
+
+	response any
+	// contentTypes replace application/json for responses that are files.
+	contentTypes []string
+	// status is the status of a successful response when it isn't 200.
+	status int
+	// deprecated operations still work but have moved or are going away.
+	deprecated bool
+}
+
+// messageResponse and deletedResponse describe the gin.H bodies some
+// handlers write.
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type deletedResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+var adminOnly = []string{util.AdminRole}
+
+var apiOperations = []apiOperation{
+	{method: http.MethodGet, path: "/healthz", id: "healthz", summary: "Liveness: the process is up", tag: "health", public: true,
+		response: healthResponse{}},
+	{method: http.MethodGet, path: "/readyz", id: "readyz", summary: "Readiness: the database is reachable and migrated; 503 otherwise", tag: "health", public: true,
+		response: healthResponse{}},
+
+	{method: http.MethodPost, path: "/users", id: "createUser", summary: "Register a user", tag: "users", public: true,
+		body: createUserRequest{}, response: UserReponse{}},
+	{method: http.MethodPost, path: "/users/login", id: "loginUser", summary: "Log in and start a session; the refresh and CSRF tokens are set as cookies", tag: "users", public: true,
+		body: loginUserRequest{}, response: loginUserReponse{}},
+	{method: http.MethodPost, path: "/users/refresh", id: "refreshToken", summary: "Issue a new access token from the refresh_token cookie; the X-CSRF-Token header must repeat the csrf_token cookie", tag: "users", public: true,
+		response: RefreshTokenResponse{}},
+	{method: http.MethodPut, path: "/users/discoverable", id: "updateDiscoverable", summary: "Opt in to or out of receiving transfers by username or email", tag: "users",
+		body: updateDiscoverableRequest{}, response: UserReponse{}},
+
+	{method: http.MethodPost, path: "/accounts", id: "createAccount", summary: "Open an account", tag: "accounts",
+		body: createAccountRequest{}, response: db.Account{}},
+	{method: http.MethodGet, path: "/accounts/lookup", id: "lookupAccount", summary: "Look up the masked holder of an account number", tag: "accounts",
+		query: lookupAccountRequest{}, response: recipientResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id", id: "getAccount", summary: "Get an account", tag: "accounts",
+		uri: getAccountRequest{}, response: db.Account{}},
+	{method: http.MethodGet, path: "/accounts/:id/balance", id: "getAccountBalance", summary: "Get the balance of an account, now or at a point in time", tag: "accounts",
+		uri: getBalanceUriRequest{}, query: getBalanceQueryRequest{}, response: snapshot.Balance{}},
+	{method: http.MethodGet, path: "/accounts/:id/statement", id: "getAccountStatement", summary: "Download a statement for a date range", tag: "accounts",
+		uri: getStatementUriRequest{}, query: getStatementQueryRequest{},
+		contentTypes: []string{statement.ContentType(statement.FormatCSV), statement.ContentType(statement.FormatOFX), statement.ContentType(statement.FormatCamt053)}},
+	{method: http.MethodGet, path: "/accounts", id: "listAccounts", summary: "List the accounts of the logged user", tag: "accounts",
+		query: listAccountRequest{}, response: []db.Account{}},
+	{method: http.MethodDelete, path: "/accounts/:id", id: "deleteAccount", summary: "Delete an account; owner only", tag: "accounts",
+		uri: deleteAccountRequest{}, response: messageResponse{}},
+	{method: http.MethodPut, path: "/accounts/:id", id: "updateAccountLegacy", summary: "Overwrite the balance of an account; moved to /admin/accounts/{id}/balance", tag: "accounts",
+		deprecated: true, roles: adminOnly, uri: updateAccountUriRequest{}, body: updateAccountJsonRequest{}, response: db.Account{}},
+	{method: http.MethodPut, path: "/accounts/:id/nickname", id: "updateAccountNickname", summary: "Rename an account", tag: "accounts",
+		uri: updateAccountUriRequest{}, body: updateAccountNicknameJsonRequest{}, response: db.Account{}},
+	{method: http.MethodPost, path: "/accounts/:id/members", id: "addAccountMember", summary: "Grant another user access to an account; owner only", tag: "accounts",
+		uri: accountMembersUriRequest{}, body: addAccountMemberRequest{}, response: db.AccountMember{}},
+	{method: http.MethodGet, path: "/accounts/:id/members", id: "listAccountMembers", summary: "List the members of an account", tag: "accounts",
+		uri: accountMembersUriRequest{}, response: []db.AccountMember{}},
+	{method: http.MethodDelete, path: "/accounts/:id/members/:username", id: "removeAccountMember", summary: "Revoke a member's access to an account; owner only, or the member leaving", tag: "accounts",
+		uri: removeAccountMemberRequest{}, response: messageResponse{}},
+
+	{method: http.MethodPost, path: "/payees", id: "createPayee", summary: "Save a payee", tag: "payees",
+		body: createPayeeRequest{}, response: payeeResponse{}},
+	{method: http.MethodGet, path: "/payees", id: "listPayees", summary: "List saved payees", tag: "payees",
+		response: []payeeResponse{}},
+	{method: http.MethodDelete, path: "/payees/:id", id: "deletePayee", summary: "Delete a payee", tag: "payees",
+		uri: deletePayeeRequest{}, response: messageResponse{}},
+
+	{method: http.MethodPost, path: "/payment-requests", id: "createPaymentRequest", summary: "Ask another user for money", tag: "payment-requests",
+		body: createPaymentRequestRequest{}, response: db.PaymentRequest{}},
+	{method: http.MethodGet, path: "/payment-requests/incoming", id: "listIncomingPaymentRequests", summary: "List payment requests addressed to the logged user", tag: "payment-requests",
+		query: listPaymentRequestsRequest{}, response: []db.PaymentRequest{}},
+	{method: http.MethodGet, path: "/payment-requests/outgoing", id: "listOutgoingPaymentRequests", summary: "List payment requests made by the logged user", tag: "payment-requests",
+		query: listPaymentRequestsRequest{}, response: []db.PaymentRequest{}},
+	{method: http.MethodGet, path: "/payment-requests/:id", id: "getPaymentRequest", summary: "Get a payment request", tag: "payment-requests",
+		uri: paymentRequestUriRequest{}, response: db.PaymentRequest{}},
+	{method: http.MethodPost, path: "/payment-requests/:id/accept", id: "acceptPaymentRequest", summary: "Pay a payment request", tag: "payment-requests",
+		uri: paymentRequestUriRequest{}, body: acceptPaymentRequestRequest{}, response: db.AcceptPaymentRequestTxResult{}},
+	{method: http.MethodPost, path: "/payment-requests/:id/decline", id: "declinePaymentRequest", summary: "Decline a payment request", tag: "payment-requests",
+		uri: paymentRequestUriRequest{}, response: db.PaymentRequest{}},
+	{method: http.MethodPost, path: "/payment-requests/:id/cancel", id: "cancelPaymentRequest", summary: "Cancel a payment request", tag: "payment-requests",
+		uri: paymentRequestUriRequest{}, response: db.PaymentRequest{}},
+
+	{method: http.MethodPost, path: "/transfers", id: "createTransfer", summary: "Send money", tag: "transfers",
+		body: createTransferRequest{}, response: db.TransferTxResult{}},
+	{method: http.MethodGet, path: "/transfers", id: "listTransfers", summary: "List the transfers of an account", tag: "transfers",
+		query: listTransfersRequest{}, response: []db.Transfer{}},
+	{method: http.MethodPost, path: "/transfers/quote", id: "quoteTransfer", summary: "Preview the fee of a transfer", tag: "transfers",
+		body: quoteTransferRequest{}, response: quoteTransferResponse{}},
+
+	{method: http.MethodPost, path: "/transfer-batches", id: "createTransferBatch", summary: "Upload a CSV or pain.001 file of transfers", tag: "transfer-batches",
+		form: createTransferBatchRequest{}, formFiles: []string{"file"}, response: transferBatchResponse{}},
+	{method: http.MethodGet, path: "/transfer-batches", id: "listTransferBatches", summary: "List uploaded transfer batches", tag: "transfer-batches",
+		query: listTransferBatchesRequest{}, response: []db.TransferBatch{}},
+	{method: http.MethodGet, path: "/transfer-batches/:id", id: "getTransferBatch", summary: "Get a transfer batch and its items", tag: "transfer-batches",
+		uri: transferBatchUriRequest{}, response: transferBatchResponse{}},
+	{method: http.MethodPost, path: "/transfer-batches/:id/approve", id: "approveTransferBatch", summary: "Approve a transfer batch; its rows are run in the background", tag: "transfer-batches",
+		uri: transferBatchUriRequest{}, response: transferBatchResponse{}, status: http.StatusAccepted},
+	{method: http.MethodGet, path: "/transfer-batches/:id/result", id: "getTransferBatchResult", summary: "Download the per-row result of a transfer batch", tag: "transfer-batches",
+		uri: transferBatchUriRequest{}, contentTypes: []string{"text/csv"}},
+
+	{method: http.MethodPost, path: "/webhooks", id: "createWebhookSubscription", summary: "Subscribe a URL to events; the secret is only returned here", tag: "webhooks",
+		body: createWebhookRequest{}, response: webhookResponse{}},
+	{method: http.MethodGet, path: "/webhooks", id: "listWebhookSubscriptions", summary: "List webhook subscriptions", tag: "webhooks",
+		response: []webhookResponse{}},
+	{method: http.MethodDelete, path: "/webhooks/:id", id: "deleteWebhookSubscription", summary: "Delete a webhook subscription", tag: "webhooks",
+		uri: webhookUriRequest{}, response: deletedResponse{}},
+	{method: http.MethodGet, path: "/webhooks/:id/deliveries", id: "listWebhookDeliveries", summary: "List deliveries of a webhook subscription", tag: "webhooks",
+		uri: webhookUriRequest{}, query: listWebhookDeliveriesRequest{}, response: []db.WebhookDelivery{}},
+	{method: http.MethodPost, path: "/webhooks/:id/deliveries/:delivery_id/retry", id: "retryWebhookDelivery", summary: "Queue a dead delivery again", tag: "webhooks",
+		uri: retryWebhookDeliveryUriRequest{}, response: db.WebhookDelivery{}},
+
+	{method: http.MethodGet, path: "/interest-products", id: "listInterestProducts", summary: "List interest products", tag: "interest",
+		query: listInterestProductsRequest{}, response: []db.InterestProduct{}},
+
+	{method: http.MethodGet, path: "/events", id: "listEvents", summary: "Read the ledger event stream after a cursor, optionally long-polling", tag: "events",
+		roles: []string{util.AdminRole, util.ServiceRole}, query: listEventsRequest{}, response: listEventsResponse{}},
+
+	{method: http.MethodPost, path: "/admin/reconciliation/runs", id: "runReconciliation", summary: "Run a ledger reconciliation", tag: "admin",
+		roles: adminOnly, response: db.ReconciliationRun{}},
+	{method: http.MethodGet, path: "/admin/reconciliation/runs", id: "listReconciliationRuns", summary: "List reconciliation runs", tag: "admin",
+		roles: adminOnly, query: listReconciliationRunsRequest{}, response: []db.ReconciliationRun{}},
+	{method: http.MethodGet, path: "/admin/reconciliation/runs/:id", id: "getReconciliationRun", summary: "Get a reconciliation run", tag: "admin",
+		roles: adminOnly, uri: getReconciliationRunRequest{}, response: db.ReconciliationRun{}},
+	{method: http.MethodPost, path: "/admin/snapshots", id: "runBalanceSnapshot", summary: "Take balance snapshots for a day", tag: "admin",
+		roles: adminOnly, body: runSnapshotRequest{}, response: runSnapshotResponse{}},
+	{method: http.MethodPost, path: "/admin/interest-products", id: "createInterestProduct", summary: "Create an interest product", tag: "admin",
+		roles: adminOnly, body: createInterestProductRequest{}, response: db.InterestProduct{}},
+	{method: http.MethodPut, path: "/admin/fee-schedules", id: "upsertFeeSchedule", summary: "Create or replace a fee schedule", tag: "admin",
+		roles: adminOnly, body: upsertFeeScheduleRequest{}, response: db.FeeSchedule{}},
+	{method: http.MethodGet, path: "/admin/fee-schedules", id: "listFeeSchedules", summary: "List fee schedules", tag: "admin",
+		roles: adminOnly, response: []db.FeeSchedule{}},
+	{method: http.MethodPut, path: "/admin/accounts/:id/tier", id: "updateAccountTier", summary: "Change the fee tier of an account", tag: "admin",
+		roles: adminOnly, uri: updateAccountTierUriRequest{}, body: updateAccountTierJsonRequest{}, response: db.Account{}},
+	{method: http.MethodPut, path: "/admin/accounts/:id/frozen", id: "setAccountFrozen", summary: "Freeze or unfreeze an account", tag: "admin",
+		roles: adminOnly, uri: setAccountFrozenUriRequest{}, body: setAccountFrozenRequest{}, response: db.Account{}},
+	{method: http.MethodPut, path: "/admin/accounts/:id/balance", id: "updateAccount", summary: "Correct the balance of an account outside the ledger", tag: "admin",
+		roles: adminOnly, uri: updateAccountUriRequest{}, body: updateAccountJsonRequest{}, response: db.Account{}},
+}
+
+// bindingEnums lists the values accepted by the custom validators.
+var bindingEnums = map[string][]string{
+	"currency":     util.ValidCurrency,
+	"permission":   {util.ViewPermission, util.TransferPermission, util.ManagePermission},
+	"webhookevent": util.WebhookEvents,
+	"daycount":     {string(interest.Actual365), string(interest.Actual360), string(interest.ActualActual), string(interest.Thirty360)},
+	"compounding":  {string(interest.CompoundDaily), string(interest.CompoundMonthly)},
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPIJSON []byte
+	openAPIErr  error
+)
+
+// openAPIDocument returns the OpenAPI 3 document of the HTTP API.
+func openAPIDocument() ([]byte, error) {
+	openAPIOnce.Do(func() {
+		openAPIJSON, openAPIErr = json.MarshalIndent(buildOpenAPI(apiOperations), "", "  ")
+	})
+	return openAPIJSON, openAPIErr
+}
+
+func (server *Server) getOpenAPI(ctx *gin.Context) {
+	document, err := openAPIDocument()
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+type jsonObject = map[string]any
+
+func buildOpenAPI(operations []apiOperation) jsonObject {
+	schemas := newSchemaBuilder()
+	paths := jsonObject{}
+
+	for _, op := range operations {
+		path := openAPIPath(op.path)
+		item, ok := paths[path].(jsonObject)
+		if !ok {
+			item = jsonObject{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.method)] = schemas.operation(op)
+	}
+
+	return jsonObject{
+		"openapi": "3.0.3",
+		"info": jsonObject{
+			"title":   "Simple Bank API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": jsonObject{
+			"schemas": schemas.components,
+			"securitySchemes": jsonObject{
+				"bearerAuth": jsonObject{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// openAPIPath turns a gin path such as /accounts/:id into /accounts/{id}.
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type schemaBuilder struct {
+	components jsonObject
+	types      map[string]reflect.Type
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: jsonObject{}, types: map[string]reflect.Type{}}
+}
+
+func (b *schemaBuilder) operation(op apiOperation) jsonObject {
+	operation := jsonObject{
+		"operationId": op.id,
+		"summary":     op.summary,
+		"tags":        []string{op.tag},
+	}
+
+	if op.deprecated {
+		operation["deprecated"] = true
+	}
+	if !op.public {
+		operation["security"] = []jsonObject{{"bearerAuth": []string{}}}
+	}
+	if len(op.roles) > 0 {
+		operation["description"] = "Requires one of the roles: " + strings.Join(op.roles, ", ") + "."
+	}
+
+	var parameters []jsonObject
+	if op.uri != nil {
+		parameters = append(parameters, b.parameters(reflect.TypeOf(op.uri), "uri", "path")...)
+	}
+	if op.query != nil {
+		parameters = append(parameters, b.parameters(reflect.TypeOf(op.query), "form", "query")...)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.body != nil {
+		operation["requestBody"] = jsonObject{
+			"required": true,
+			"content": jsonObject{
+				"application/json": jsonObject{"schema": b.schema(reflect.TypeOf(op.body), false)},
+			},
+		}
+	}
+	if op.form != nil {
+		form := b.formSchema(reflect.TypeOf(op.form), op.formFiles)
+		operation["requestBody"] = jsonObject{
+			"required": true,
+			"content": jsonObject{
+				"multipart/form-data": jsonObject{"schema": form},
+			},
+		}
+	}
+
+	status := http.StatusOK
+	if op.status != 0 {
+		status = op.status
+	}
+
+	ok := jsonObject{"description": http.StatusText(status)}
+	if op.response != nil {
+		ok["content"] = jsonObject{
+			"application/json": jsonObject{"schema": b.schema(reflect.TypeOf(op.response), true)},
+		}
+	} else if len(op.contentTypes) > 0 {
+		content := jsonObject{}
+		for _, contentType := range op.contentTypes {
+			content[contentType] = jsonObject{"schema": jsonObject{"type": "string", "format": "binary"}}
+		}
+		ok["content"] = content
+	}
+
+	operation["responses"] = jsonObject{
+		strconv.Itoa(status): ok,
+		"default": jsonObject{
+			"description": "Error",
+			"content": jsonObject{
+				"application/json": jsonObject{"schema": b.schema(reflect.TypeOf(APIError{}), true)},
+			},
+		},
+	}
+	return operation
+}
+
+// parameters describes the fields of t tagged with tagKey as parameters in
+// the given location.
+func (b *schemaBuilder) parameters(t reflect.Type, tagKey string, in string) []jsonObject {
+	var parameters []jsonObject
+	for _, field := range structFields(t) {
+		name, _ := tagName(field.Tag.Get(tagKey))
+		if name == "" || name == "-" {
+			continue
+		}
+
+		rules := bindingRules(field)
+		required := in == "path" || hasRule(rules, "required")
+		schema := b.schema(field.Type, false)
+		applyBinding(schema, field.Type, rules)
+
+		parameters = append(parameters, jsonObject{
+			"name":     name,
+			"in":       in,
+			"required": required,
+			"schema":   schema,
+		})
+	}
+	return parameters
+}
+
+func (b *schemaBuilder) formSchema(t reflect.Type, files []string) jsonObject {
+	properties := jsonObject{}
+	required := []string{}
+	for _, file := range files {
+		properties[file] = jsonObject{"type": "string", "format": "binary"}
+		required = append(required, file)
+	}
+
+	for _, field := range structFields(t) {
+		name, _ := tagName(field.Tag.Get("form"))
+		if name == "" || name == "-" {
+			continue
+		}
+
+		rules := bindingRules(field)
+		schema := b.schema(field.Type, false)
+		applyBinding(schema, field.Type, rules)
+		properties[name] = schema
+		if hasRule(rules, "required") {
+			required = append(required, name)
+		}
+	}
+
+	return jsonObject{"type": "object", "properties": properties, "required": required}
+}
+
+// schema describes t. Named structs become components and are referenced.
+// In responses every field without omitempty is required, since the
+// handler always writes it.
+func (b *schemaBuilder) schema(t reflect.Type, response bool) jsonObject {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return jsonObject{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(uuid.UUID{}):
+		return jsonObject{"type": "string", "format": "uuid"}
+	case reflect.TypeOf(json.RawMessage{}):
+		// Any JSON value; a nil RawMessage is written as null.
+		return jsonObject{"nullable": true}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := b.schema(t.Elem(), response)
+		if _, isRef := schema["$ref"]; isRef {
+			return jsonObject{"allOf": []jsonObject{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return jsonObject{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return jsonObject{"type": "integer", "format": "int64"}
+	case reflect.Int32, reflect.Int16, reflect.Int8, reflect.Uint32, reflect.Uint16, reflect.Uint8:
+		return jsonObject{"type": "integer", "format": "int32"}
+	case reflect.Float32, reflect.Float64:
+		return jsonObject{"type": "number"}
+	case reflect.String:
+		return jsonObject{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return jsonObject{"type": "array", "items": b.schema(t.Elem(), response)}
+	case reflect.Map:
+		return jsonObject{"type": "object", "additionalProperties": b.schema(t.Elem(), response)}
+	case reflect.Interface:
+		return jsonObject{}
+	case reflect.Struct:
+		return b.component(t, response)
+	}
+
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func (b *schemaBuilder) component(t reflect.Type, response bool) jsonObject {
+	name := componentName(t)
+	ref := jsonObject{"$ref": "#/components/schemas/" + name}
+
+	if existing, ok := b.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: %s and %s share the schema name %s", existing, t, name))
+		}
+		return ref
+	}
+	b.types[name] = t
+
+	properties := jsonObject{}
+	required := []string{}
+	for _, field := range structFields(t) {
+		name, options := tagName(field.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		rules := bindingRules(field)
+		schema := b.schema(field.Type, response)
+		applyBinding(schema, field.Type, rules)
+		properties[name] = schema
+
+		if response && !strings.Contains(options, "omitempty") || !response && hasRule(rules, "required") {
+			required = append(required, name)
+		}
+	}
+
+	schema := jsonObject{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	b.components[name] = schema
+	return ref
+}
+
+func componentName(t reflect.Type) string {
+	name := []rune(t.Name())
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
+
+// structFields lists the fields encoding/json would see, with embedded
+// structs flattened.
+func structFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			fields = append(fields, structFields(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func tagName(tag string) (string, string) {
+	name, options, _ := strings.Cut(tag, ",")
+	return name, options
+}
+
+func bindingRules(field reflect.StructField) []string {
+	tag := field.Tag.Get("binding")
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBinding adds the constraints of validator rules to schema. Rules
+// after dive apply to the items of a slice; map keys and values are left
+// undocumented.
+func applyBinding(schema jsonObject, t reflect.Type, rules []string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if _, isRef := schema["$ref"]; isRef {
+		return
+	}
+
+	for i, rule := range rules {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			if t.Kind() == reflect.Slice {
+				if items, ok := schema["items"].(jsonObject); ok {
+					applyBinding(items, t.Elem(), rules[i+1:])
+				}
+			}
+			return
+		case "min", "max", "gt", "gte", "lt", "lte", "len":
+			applyLimit(schema, t, name, value)
+		case "oneof":
+			schema["enum"] = strings.Fields(value)
+		case "email":
+			schema["format"] = "email"
+		case "url":
+			schema["format"] = "uri"
+		case "alphanum":
+			schema["pattern"] = "^[a-zA-Z0-9]+$"
+		case "printascii":
+			schema["pattern"] = "^[\\x20-\\x7E]*$"
+		case "unique":
+			schema["uniqueItems"] = true
+		case "datetime":
+			if value == "2006-01-02" {
+				schema["format"] = "date"
+			}
+		default:
+			if enum, ok := bindingEnums[name]; ok {
+				schema["enum"] = enum
+			}
+		}
+	}
+}
+
+func applyLimit(schema jsonObject, t reflect.Type, rule string, value string) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+
+	var minKey, maxKey string
+	switch t.Kind() {
+	case reflect.String:
+		minKey, maxKey = "minLength", "maxLength"
+	case reflect.Slice, reflect.Array:
+		minKey, maxKey = "minItems", "maxItems"
+	case reflect.Map:
+		minKey, maxKey = "minProperties", "maxProperties"
+	default:
+		minKey, maxKey = "minimum", "maximum"
+	}
+
+	switch rule {
+	case "min", "gte":
+		schema[minKey] = n
+	case "max", "lte":
+		schema[maxKey] = n
+	case "len":
+		schema[minKey] = n
+		schema[maxKey] = n
+	case "gt":
+		if minKey == "minimum" {
+			schema["minimum"] = n
+			schema["exclusiveMinimum"] = true
+		} else {
+			schema[minKey] = n + 1
+		}
+	case "lt":
+		if maxKey == "maximum" {
+			schema["maximum"] = n
+			schema["exclusiveMaximum"] = true
+		} else {
+			schema[maxKey] = n - 1
+		}
+	}
+}
@@ -0,0 +1,688 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/db/migration"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/reconcile"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func fetchOpenAPI(t *testing.T, server *Server) map[string]any {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	require.Equal(t, "3.0.3", document["openapi"])
+	return document
+}
+
+// TestOpenAPIRoutes fails when a route is added to or removed from the
+// router without updating apiOperations.
+func TestOpenAPIRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	document := fetchOpenAPI(t, server)
+	paths := document["paths"].(map[string]any)
+
+	var routes, documented []string
+	for _, route := range server.router.Routes() {
+		if route.Path == "/openapi.json" {
+			continue
+		}
+		routes = append(routes, route.Method+" "+openAPIPath(route.Path))
+	}
+	for path, item := range paths {
+		for method := range item.(map[string]any) {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	require.Equal(t, routes, documented)
+}
+
+// loadOpenAPI parses and validates the document. Object schemas are closed
+// so that a field the handler writes but the spec doesn't list fails too.
+func loadOpenAPI(t *testing.T) *openapi3.T {
+	data, err := openAPIDocument()
+	require.NoError(t, err)
+
+	loader := openapi3.NewLoader()
+	document, err := loader.LoadFromData(data)
+	require.NoError(t, err)
+	require.NoError(t, document.Validate(loader.Context))
+
+	closed := false
+	for _, schema := range document.Components.Schemas {
+		if schema.Value.Type.Is("object") && schema.Value.AdditionalProperties.Schema == nil {
+			schema.Value.AdditionalProperties.Has = &closed
+		}
+	}
+	return document
+}
+
+func TestOpenAPIValid(t *testing.T) {
+	loadOpenAPI(t)
+}
+
+func TestOpenAPIValidatorRejectsDrift(t *testing.T) {
+	schema := loadOpenAPI(t).Components.Schemas["Account"].Value
+
+	account := roundTrip(t, randomAccount()).(map[string]any)
+	require.NoError(t, schema.VisitJSON(account))
+
+	renamed := copyObject(account)
+	renamed["account_owner"] = renamed["owner"]
+	delete(renamed, "owner")
+	require.Error(t, schema.VisitJSON(renamed))
+
+	retyped := copyObject(account)
+	retyped["balance"] = "100"
+	require.Error(t, schema.VisitJSON(retyped))
+}
+
+func roundTrip(t *testing.T, value any) any {
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	var decoded any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
+
+func copyObject(object map[string]any) map[string]any {
+	copied := make(map[string]any, len(object))
+	for key, value := range object {
+		copied[key] = value
+	}
+	return copied
+}
+
+func init() {
+	for _, contentType := range []string{"application/x-ofx", "application/xml"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.FileBodyDecoder)
+	}
+}
+
+// contractCase is a successful call of one operation. The URL defaults to
+// the operation path and the body, when set, is sent as JSON.
+type contractCase struct {
+	url          string
+	body         any
+	setupRequest func(t *testing.T, server *Server) *http.Request
+	buildStubs   func(store *mockdb.MockStore)
+}
+
+// TestOpenAPIContract calls every operation in apiOperations once through
+// the router, validating the request and the response against the spec.
+func TestOpenAPIContract(t *testing.T) {
+	user, password := randomUser()
+	user.Role = util.AdminRole
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user.HashedPassword = hashedPassword
+
+	account := db.Account{
+		ID:            7,
+		Owner:         user.Username,
+		Balance:       10_000,
+		Currency:      "USD",
+		AccountNumber: validAccountNumber,
+		AccountType:   util.CheckingAccount,
+		CreatedAt:     time.Now(),
+	}
+	holder := db.GetAccountHolderByNumberRow{ID: 8, AccountNumber: validAccountNumber, Currency: "USD", FullName: "Jane Roe"}
+	member := db.AccountMember{AccountID: account.ID, Username: "member", Permission: util.ViewPermission, InvitedBy: user.Username}
+	paymentRequest := db.PaymentRequest{
+		ID:          3,
+		Requester:   "requester",
+		Payer:       user.Username,
+		ToAccountID: 8,
+		Amount:      100,
+		Currency:    "USD",
+		Status:      util.PaymentRequestPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	transfer := db.Transfer{ID: 1, FromAccountID: account.ID, ToAccountID: 8, Amount: 100, Metadata: []byte(`{"order":"42"}`)}
+	transferBatch := db.TransferBatch{ID: 4, Owner: user.Username, Format: "csv", Status: util.TransferBatchPending}
+	batchItem := db.TransferBatchItem{ID: 1, BatchID: transferBatch.ID, LineNo: 1, Status: util.TransferBatchItemValid}
+	subscription := db.WebhookSubscription{ID: 5, Owner: user.Username, Url: "https://example.com/hook", EventTypes: []string{util.WebhookEvents[0]}}
+	delivery := db.WebhookDelivery{ID: 6, SubscriptionID: subscription.ID, EventType: util.WebhookEvents[0], Status: "pending"}
+	run := db.ReconciliationRun{ID: 1, Status: reconcile.StatusBalanced}
+	product := db.InterestProduct{ID: 2, Name: "Saver", Currency: "USD", AnnualRatePpm: 20_000}
+	schedule := db.FeeSchedule{Currency: "USD", Tier: "standard"}
+
+	cases := map[string]contractCase{
+		"healthz": {},
+		"readyz": {
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Ping(gomock.Any()).Times(1).Return(nil)
+				store.EXPECT().MigrationVersion(gomock.Any()).Times(1).DoAndReturn(func(context.Context) (int64, bool, error) {
+					version, err := migration.LatestVersion()
+					return version, false, err
+				})
+			},
+		},
+		"createUser": {
+			body: gin.H{"username": user.Username, "password": password, "full_name": user.FullName, "email": user.Email},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+		},
+		"loginUser": {
+			body: gin.H{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+				store.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: args.ID, Username: args.Username, ExpiredAt: args.ExpiredAt}, nil
+					})
+			},
+		},
+		"refreshToken": {
+			setupRequest: func(t *testing.T, server *Server) *http.Request {
+				request := httptest.NewRequest(http.MethodPost, "/users/refresh", nil)
+				addRefreshCookies(t, server, request, user.Username)
+				return request
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Session{Username: user.Username, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+			},
+		},
+		"updateDiscoverable": {
+			body: gin.H{"discoverable": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserDiscoverable(gomock.Any(), gomock.Any()).Times(1).Return(user, nil)
+			},
+		},
+
+		"createAccount": {
+			body: gin.H{"currency": "USD", "nickname": "Bills"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+		},
+		"lookupAccount": {
+			url: "/accounts/lookup?account_number=" + validAccountNumber,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).Return(holder, nil)
+			},
+		},
+		"getAccount": {
+			url: fmt.Sprintf("/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+			},
+		},
+		"getAccountBalance": {
+			url: fmt.Sprintf("/accounts/%d/balance?at=%s", account.ID, "2026-03-01T12:00:00Z"),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AccountBalanceSnapshot{AccountID: account.ID, Balance: 100}, nil)
+				store.EXPECT().SumEntriesInRange(gomock.Any(), gomock.Any()).Times(1).Return(int64(25), nil)
+			},
+		},
+		"getAccountStatement": {
+			url: fmt.Sprintf("/accounts/%d/statement?format=csv&from=2026-03-01&to=2026-03-31", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(2).
+					Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+				store.EXPECT().SumEntriesSince(gomock.Any(), gomock.Any()).Times(2).Return(int64(0), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.ListStatementEntriesRow{{ID: 3, Amount: 1000, Memo: "Salary"}}, nil)
+			},
+		},
+		"listAccounts": {
+			url: "/accounts?page_id=1&page_size=5&currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{account}, nil)
+			},
+		},
+		"deleteAccount": {
+			url: fmt.Sprintf("/accounts/%d", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().DeleteAccount(gomock.Any(), account.ID).Times(1).Return(nil)
+			},
+		},
+		"updateAccountNickname": {
+			url:  fmt.Sprintf("/accounts/%d/nickname", account.ID),
+			body: gin.H{"nickname": "Rent"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountNickname(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+		},
+		"addAccountMember": {
+			url:  fmt.Sprintf("/accounts/%d/members", account.ID),
+			body: gin.H{"username": member.Username, "permission": member.Permission},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().AddAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+			},
+		},
+		"listAccountMembers": {
+			url: fmt.Sprintf("/accounts/%d/members", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountMembers(gomock.Any(), account.ID).Times(1).Return([]db.AccountMember{member}, nil)
+			},
+		},
+		"removeAccountMember": {
+			url: fmt.Sprintf("/accounts/%d/members/%s", account.ID, member.Username),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().RemoveAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+
+		"createPayee": {
+			body: gin.H{"account_number": validAccountNumber, "nickname": "Landlord"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).Return(holder, nil)
+				store.EXPECT().CreatePayee(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Payee{ID: 1, Owner: user.Username, AccountID: holder.ID, Nickname: "Landlord"}, nil)
+			},
+		},
+		"listPayees": {
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPayees(gomock.Any(), user.Username).Times(1).
+					Return([]db.ListPayeesRow{{ID: 1, Nickname: "Landlord", AccountNumber: validAccountNumber, Currency: "USD", FullName: "Jane Roe"}}, nil)
+			},
+		},
+		"deletePayee": {
+			url: "/payees/1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeletePayee(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+
+		"createPaymentRequest": {
+			body: gin.H{"payer": "payer", "to_account_id": account.ID, "amount": 100, "currency": "USD", "memo": "Dinner"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(paymentRequest, nil)
+			},
+		},
+		"listIncomingPaymentRequests": {
+			url: "/payment-requests/incoming?page_id=1&page_size=5&status=pending",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListIncomingPaymentRequests(gomock.Any(), gomock.Any()).Times(1).Return([]db.PaymentRequest{paymentRequest}, nil)
+			},
+		},
+		"listOutgoingPaymentRequests": {
+			url: "/payment-requests/outgoing?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOutgoingPaymentRequests(gomock.Any(), gomock.Any()).Times(1).Return([]db.PaymentRequest{paymentRequest}, nil)
+			},
+		},
+		"getPaymentRequest": {
+			url: fmt.Sprintf("/payment-requests/%d", paymentRequest.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), paymentRequest.ID).Times(1).Return(paymentRequest, nil)
+			},
+		},
+		"acceptPaymentRequest": {
+			url:  fmt.Sprintf("/payment-requests/%d/accept", paymentRequest.ID),
+			body: gin.H{"from_account_id": account.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), paymentRequest.ID).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AcceptPaymentRequestTxResult{Request: paymentRequest, Transfer: &db.TransferTxResult{Transfer: transfer}}, nil)
+			},
+		},
+		"declinePaymentRequest": {
+			url: fmt.Sprintf("/payment-requests/%d/decline", paymentRequest.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), paymentRequest.ID).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().ResolvePaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(paymentRequest, nil)
+			},
+		},
+		"cancelPaymentRequest": {
+			url: fmt.Sprintf("/payment-requests/%d/cancel", paymentRequest.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				outgoing := paymentRequest
+				outgoing.Requester, outgoing.Payer = user.Username, "payer"
+				store.EXPECT().GetPaymentRequest(gomock.Any(), paymentRequest.ID).Times(1).Return(outgoing, nil)
+				store.EXPECT().ResolvePaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(outgoing, nil)
+			},
+		},
+
+		"createTransfer": {
+			body: gin.H{"from_account_id": account.ID, "to_account_id": 8, "amount": 100, "currency": "USD", "metadata": gin.H{"order": "42"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), int64(8)).Times(1).Return(db.Account{ID: 8, Owner: "payee", Currency: "USD"}, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+		},
+		"listTransfers": {
+			url: fmt.Sprintf("/transfers?account_id=%d&page_id=1&page_size=5", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(1).Return([]db.Transfer{transfer}, nil)
+			},
+		},
+		"quoteTransfer": {
+			body: gin.H{"from_account_id": account.ID, "to_account_number": validAccountNumber, "amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).Return(holder, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+			},
+		},
+
+		"createTransferBatch": {
+			setupRequest: func(t *testing.T, server *Server) *http.Request {
+				return newBatchUploadRequest(t, "payouts.csv", "from_account,to_account,amount,currency,reference\n"+
+					"999999999994,"+validAccountNumber+",12.50,USD,SAL-1\n", "csv")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), "999999999994").Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferBatchTxResult{Batch: transferBatch, Items: []db.TransferBatchItem{batchItem}}, nil)
+			},
+		},
+		"listTransferBatches": {
+			url: "/transfer-batches?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+			},
+		},
+		"getTransferBatch": {
+			url: fmt.Sprintf("/transfer-batches/%d", transferBatch.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return([]db.TransferBatchItem{batchItem}, nil)
+			},
+		},
+		"approveTransferBatch": {
+			url: fmt.Sprintf("/transfer-batches/%d/approve", transferBatch.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				started := transferBatch
+				started.Status = util.TransferBatchProcessing
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+				store.EXPECT().StartTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(started, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return([]db.TransferBatchItem{batchItem}, nil)
+			},
+		},
+		"getTransferBatchResult": {
+			url: fmt.Sprintf("/transfer-batches/%d/result", transferBatch.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				completed := transferBatch
+				completed.Status = util.TransferBatchCompleted
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(completed, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return([]db.TransferBatchItem{batchItem}, nil)
+			},
+		},
+
+		"createWebhookSubscription": {
+			body: gin.H{"url": subscription.Url, "event_types": subscription.EventTypes},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(subscription, nil)
+			},
+		},
+		"listWebhookSubscriptions": {
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListWebhookSubscriptions(gomock.Any(), user.Username).Times(1).Return([]db.WebhookSubscription{subscription}, nil)
+			},
+		},
+		"deleteWebhookSubscription": {
+			url: fmt.Sprintf("/webhooks/%d", subscription.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+		"listWebhookDeliveries": {
+			url: fmt.Sprintf("/webhooks/%d/deliveries?status=dead&page_id=1&page_size=5", subscription.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{delivery}, nil)
+			},
+		},
+		"retryWebhookDelivery": {
+			url: fmt.Sprintf("/webhooks/%d/deliveries/%d/retry", subscription.ID, delivery.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().RetryWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil)
+			},
+		},
+
+		"listInterestProducts": {
+			url: "/interest-products?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListInterestProducts(gomock.Any(), gomock.Any()).Times(1).Return([]db.InterestProduct{product}, nil)
+			},
+		},
+
+		"listEvents": {
+			url: "/events?after=40&limit=10",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEvents(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Event{{Seq: 41, EventType: util.WebhookEvents[0], AccountIds: []int64{account.ID}, Payload: []byte(`{"id":1}`)}}, nil)
+			},
+		},
+
+		"runReconciliation": {
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), user.Username).Times(1).
+					Return(db.ReconciliationRun{ID: 1, Status: reconcile.StatusRunning}, nil)
+				store.EXPECT().ListAccountLedgerTotals(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListAccountLedgerTotalsRow{}, nil)
+				store.EXPECT().ListTransferLedgerTotals(gomock.Any(), gomock.Any()).Times(1).Return([]db.ListTransferLedgerTotalsRow{}, nil)
+				store.EXPECT().FinishReconciliationRun(gomock.Any(), gomock.Any()).Times(1).Return(run, nil)
+			},
+		},
+		"listReconciliationRuns": {
+			url: "/admin/reconciliation/runs?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListReconciliationRuns(gomock.Any(), gomock.Any()).Times(1).Return([]db.ReconciliationRun{run}, nil)
+			},
+		},
+		"getReconciliationRun": {
+			url: fmt.Sprintf("/admin/reconciliation/runs/%d", run.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), run.ID).Times(1).Return(run, nil)
+			},
+		},
+		"runBalanceSnapshot": {
+			body: gin.H{"date": "2026-03-01"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertBalanceSnapshots(gomock.Any(), gomock.Any()).Times(1).Return([]int64{account.ID}, nil)
+			},
+		},
+		"createInterestProduct": {
+			body: gin.H{"name": product.Name, "currency": "USD", "annual_rate_ppm": product.AnnualRatePpm, "day_count": bindingEnums["daycount"][0], "compounding": bindingEnums["compounding"][0]},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInterestProduct(gomock.Any(), gomock.Any()).Times(1).Return(product, nil)
+			},
+		},
+		"upsertFeeSchedule": {
+			body: gin.H{"currency": "USD", "tier": schedule.Tier, "flat_fee": 10, "rate_ppm": 1000, "min_fee": 10, "max_fee": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(schedule, nil)
+			},
+		},
+		"listFeeSchedules": {
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListFeeSchedules(gomock.Any()).Times(1).Return([]db.FeeSchedule{schedule}, nil)
+			},
+		},
+		"updateAccountTier": {
+			url:  fmt.Sprintf("/admin/accounts/%d/tier", account.ID),
+			body: gin.H{"tier": "premium"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountTier(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+		},
+		"setAccountFrozen": {
+			url:  fmt.Sprintf("/admin/accounts/%d/frozen", account.ID),
+			body: gin.H{"frozen": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetAccountFrozenTx(gomock.Any(), gomock.Any()).Times(1).Return(db.SetAccountFrozenTxResult{Account: account}, nil)
+			},
+		},
+		"updateAccountLegacy": {
+			url:  fmt.Sprintf("/accounts/%d", account.ID),
+			body: gin.H{"balance": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+		},
+		"updateAccount": {
+			url:  fmt.Sprintf("/admin/accounts/%d/balance", account.ID),
+			body: gin.H{"balance": 500},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+			},
+		},
+	}
+
+	router := newContractRouter(t)
+
+	for _, op := range apiOperations {
+		tc, ok := cases[op.id]
+		require.True(t, ok, "operation %s has no contract case", op.id)
+		delete(cases, op.id)
+
+		t.Run(op.id, func(t *testing.T) {
+			setConfig(t, "WEBHOOK_ALLOW_INSECURE", false)
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			if len(op.roles) > 0 {
+				store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+			}
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			server.webhookURLs.Resolver = webhookResolver
+
+			var request *http.Request
+			switch {
+			case tc.setupRequest != nil:
+				request = tc.setupRequest(t, server)
+			case tc.body != nil:
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				request = httptest.NewRequest(op.method, contractURL(op, tc), bytes.NewReader(data))
+				request.Header.Set("Content-Type", "application/json")
+			default:
+				request = httptest.NewRequest(op.method, contractURL(op, tc), nil)
+			}
+			if !op.public {
+				addAuthorization(t, request, server.tokenGenerator, authTypeBearer, user.Username, time.Minute)
+			}
+
+			checkContract(t, router, server, request, op.id, expectedStatus(op))
+		})
+	}
+	require.Empty(t, cases, "contract cases for operations that no longer exist")
+}
+
+// TestOpenAPIContractErrors checks that error responses match the
+// documented default response.
+func TestOpenAPIContractErrors(t *testing.T) {
+	account := randomAccount()
+
+	router := newContractRouter(t)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(db.Account{}, sql.ErrNoRows)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d", account.ID), nil)
+	addAuthorization(t, request, server.tokenGenerator, authTypeBearer, account.Owner, time.Minute)
+
+	checkContract(t, router, server, request, "getAccount", http.StatusNotFound)
+}
+
+func newContractRouter(t *testing.T) routers.Router {
+	router, err := gorillamux.NewRouter(loadOpenAPI(t))
+	require.NoError(t, err)
+	return router
+}
+
+// checkContract validates request against the operation it routes to, serves
+// it and validates the response.
+func checkContract(t *testing.T, router routers.Router, server *Server, request *http.Request, operationID string, status int) {
+	route, pathParams, err := router.FindRoute(request)
+	require.NoError(t, err)
+	require.Equal(t, operationID, route.Operation.OperationID)
+
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    request,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    options,
+	}
+	require.NoError(t, openapi3filter.ValidateRequest(context.Background(), requestInput))
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, status, recorder.Code, recorder.Body.String())
+
+	require.NoError(t, openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 recorder.Code,
+		Header:                 recorder.Header(),
+		Body:                   io.NopCloser(recorder.Body),
+		Options:                options,
+	}))
+}
+
+func contractURL(op apiOperation, tc contractCase) string {
+	if tc.url != "" {
+		return tc.url
+	}
+	return op.path
+}
+
+func expectedStatus(op apiOperation) int {
+	if op.status != 0 {
+		return op.status
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var errInvalidAccountNumber = errors.New("invalid account number")
+
+// recipientResponse identifies the account behind a public account number
+// without revealing who holds it in full.
+type recipientResponse struct {
+	AccountNumber string `json:"account_number"`
+	Currency      string `json:"currency"`
+	Holder        string `json:"holder"`
+}
+
+func newRecipientResponse(holder db.GetAccountHolderByNumberRow) recipientResponse {
+	return recipientResponse{
+		AccountNumber: holder.AccountNumber,
+		Currency:      holder.Currency,
+		Holder:        util.MaskName(holder.FullName),
+	}
+}
+
+// findAccountHolder resolves a public account number on behalf of username.
+// On failure it writes the response and returns false.
+func (server *Server) findAccountHolder(ctx *gin.Context, username string, accountNumber string) (db.GetAccountHolderByNumberRow, bool) {
+	if !util.IsValidAccountNumber(accountNumber) {
+		writeError(ctx, http.StatusBadRequest, errInvalidAccountNumber)
+		return db.GetAccountHolderByNumberRow{}, false
+	}
+
+	if !server.allowLookup(ctx, username) {
+		return db.GetAccountHolderByNumberRow{}, false
+	}
+
+	holder, err := server.store.GetAccountHolderByNumber(ctx, accountNumber)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return holder, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return holder, false
+	}
+
+	return holder, true
+}
+
+type lookupAccountRequest struct {
+	AccountNumber string `form:"account_number" binding:"required"`
+}
+
+func (server *Server) lookupAccount(ctx *gin.Context) {
+	var req lookupAccountRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	holder, ok := server.findAccountHolder(ctx, authPayload.Username, req.AccountNumber)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newRecipientResponse(holder))
+}
+
+type createPayeeRequest struct {
+	AccountNumber string `json:"account_number" binding:"required"`
+	Nickname      string `json:"nickname" binding:"required,max=64"`
+}
+
+type payeeResponse struct {
+	ID        int64     `json:"id"`
+	Nickname  string    `json:"nickname"`
+	CreatedAt time.Time `json:"created_at"`
+	recipientResponse
+}
+
+func (server *Server) createPayee(ctx *gin.Context) {
+	var req createPayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	holder, ok := server.findAccountHolder(ctx, authPayload.Username, req.AccountNumber)
+	if !ok {
+		return
+	}
+
+	payee, err := server.store.CreatePayee(ctx, db.CreatePayeeParams{
+		Owner:     authPayload.Username,
+		AccountID: holder.ID,
+		Nickname:  req.Nickname,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payeeResponse{
+		ID:                payee.ID,
+		Nickname:          payee.Nickname,
+		CreatedAt:         payee.CreatedAt,
+		recipientResponse: newRecipientResponse(holder),
+	})
+}
+
+func (server *Server) listPayees(ctx *gin.Context) {
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	payees, err := server.store.ListPayees(ctx, authPayload.Username)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]payeeResponse, len(payees))
+	for i, payee := range payees {
+		rsp[i] = payeeResponse{
+			ID:        payee.ID,
+			Nickname:  payee.Nickname,
+			CreatedAt: payee.CreatedAt,
+			recipientResponse: recipientResponse{
+				AccountNumber: payee.AccountNumber,
+				Currency:      payee.Currency,
+				Holder:        util.MaskName(payee.FullName),
+			},
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type deletePayeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deletePayee(ctx *gin.Context) {
+	var req deletePayeeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	deleted, err := server.store.DeletePayee(ctx, db.DeletePayeeParams{
+		ID:    req.ID,
+		Owner: authPayload.Username,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if deleted == 0 {
+		writeError(ctx, http.StatusNotFound, sql.ErrNoRows)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Deleted Succesfully!"})
+}
+
+// payeeAccountID returns the account a saved payee points to, provided the
+// payee belongs to username.
+func (server *Server) payeeAccountID(ctx *gin.Context, payeeID int64, username string) (int64, bool) {
+	payee, err := server.store.GetPayee(ctx, payeeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return 0, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	if payee.Owner != username {
+		writeError(ctx, http.StatusForbidden, token.ErrDoesNotBelong)
+		return 0, false
+	}
+
+	return payee.AccountID, true
+}
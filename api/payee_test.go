@@ -0,0 +1,344 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+const validAccountNumber = "123456789015"
+
+func TestPayeesAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	holder := db.GetAccountHolderByNumberRow{ID: 42, AccountNumber: validAccountNumber, Currency: "USD", FullName: "Jane Roe"}
+
+	testCases := []struct {
+		name         string
+		method       string
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Lookup",
+			method: http.MethodGet,
+			url:    "/accounts/lookup?account_number=" + validAccountNumber,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).Return(holder, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got recipientResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "J*** R**", got.Holder)
+				require.Equal(t, validAccountNumber, got.AccountNumber)
+			},
+		},
+		{
+			name:   "LookupBadChecksum",
+			method: http.MethodGet,
+			url:    "/accounts/lookup?account_number=123456789016",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:   "LookupNotFound",
+			method: http.MethodGet,
+			url:    "/accounts/lookup?account_number=" + validAccountNumber,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).
+					Return(db.GetAccountHolderByNumberRow{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:   "CreatePayee",
+			method: http.MethodPost,
+			url:    "/payees",
+			body:   gin.H{"account_number": validAccountNumber, "nickname": "Landlord"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).Return(holder, nil)
+				store.EXPECT().CreatePayee(gomock.Any(), db.CreatePayeeParams{
+					Owner:     testUser.Username,
+					AccountID: holder.ID,
+					Nickname:  "Landlord",
+				}).Times(1).Return(db.Payee{ID: 1, Owner: testUser.Username, AccountID: holder.ID, Nickname: "Landlord"}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got payeeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "Landlord", got.Nickname)
+				require.Equal(t, "J*** R**", got.Holder)
+			},
+		},
+		{
+			name:   "ListPayees",
+			method: http.MethodGet,
+			url:    "/payees",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPayees(gomock.Any(), testUser.Username).Times(1).
+					Return([]db.ListPayeesRow{{ID: 1, Nickname: "Landlord", AccountNumber: validAccountNumber, FullName: "Jane Roe"}}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []payeeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Len(t, got, 1)
+				require.Equal(t, "J*** R**", got[0].Holder)
+			},
+		},
+		{
+			name:   "DeleteOtherUsersPayee",
+			method: http.MethodDelete,
+			url:    "/payees/7",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeletePayee(gomock.Any(), db.DeletePayeeParams{ID: 7, Owner: testUser.Username}).
+					Times(1).Return(int64(0), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestTransferRecipientAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	fromAccount := randomAccount()
+	fromAccount.Owner = testUser.Username
+	fromAccount.Currency = "USD"
+	fromAccount.Balance = 100
+	toAccount := randomAccount()
+	toAccount.Currency = "USD"
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ByPayee",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_payee_id": 3, "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), int64(3)).Times(1).
+					Return(db.Payee{ID: 3, Owner: testUser.Username, AccountID: toAccount.ID}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountId: fromAccount.ID,
+					ToAccountId:   toAccount.ID,
+					Amount:        10,
+				}).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ByAccountNumber",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_number": validAccountNumber, "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(1).
+					Return(db.GetAccountHolderByNumberRow{ID: toAccount.ID, AccountNumber: validAccountNumber}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "OtherUsersPayee",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_payee_id": 3, "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetPayee(gomock.Any(), int64(3)).Times(1).
+					Return(db.Payee{ID: 3, Owner: "someoneelse", AccountID: toAccount.ID}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "ByHandle",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_handle": "janeroe", "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), "janeroe").Times(1).
+					Return(db.User{Username: "janeroe", Discoverable: true}, nil)
+				store.EXPECT().GetReceivingAccount(gomock.Any(), db.GetReceivingAccountParams{Owner: "janeroe", Currency: "USD"}).
+					Times(1).Return(toAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountId: fromAccount.ID,
+					ToAccountId:   toAccount.ID,
+					Amount:        10,
+				}).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ByEmail",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_handle": "jane@example.com", "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUserByEmail(gomock.Any(), "jane@example.com").Times(1).
+					Return(db.User{Username: "janeroe", Discoverable: true}, nil)
+				store.EXPECT().GetReceivingAccount(gomock.Any(), gomock.Any()).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "HandleNotDiscoverable",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_handle": "janeroe", "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), "janeroe").Times(1).Return(db.User{Username: "janeroe"}, nil)
+				store.EXPECT().GetReceivingAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name: "HandleUnknown",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_handle": "janeroe", "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), "janeroe").Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name: "TwoRecipients",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "to_payee_id": 3, "amount": 10, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestLookupRateLimitAPI(t *testing.T) {
+	testUser, _ := randomUser()
+
+	setConfig(t, "RATE_LIMIT_LOOKUPS", "2/1m")
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccountHolderByNumber(gomock.Any(), validAccountNumber).Times(2).
+		Return(db.GetAccountHolderByNumberRow{AccountNumber: validAccountNumber, FullName: "Jane Roe"}, nil)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+
+	codes := make([]int, 3)
+	for i := range codes {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/accounts/lookup?account_number="+validAccountNumber, nil)
+		require.NoError(t, err)
+
+		addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		codes[i] = recorder.Code
+	}
+
+	require.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+}
@@ -0,0 +1,304 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+const defaultPaymentRequestTTL = 7 * 24 * time.Hour
+
+var errRequestSelf = errors.New("cannot request money from yourself")
+
+type createPaymentRequestRequest struct {
+	Payer       string `json:"payer" binding:"required,alphanum"`
+	ToAccountId int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Memo        string `json:"memo" binding:"max=140"`
+}
+
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	if req.Payer == authPayload.Username {
+		writeError(ctx, http.StatusBadRequest, errRequestSelf)
+		return
+	}
+
+	if _, ok := server.validateAccount(ctx, req.ToAccountId, req.Currency, 0, true, authPayload.Username); !ok {
+		return
+	}
+
+	// An unknown payer surfaces as a foreign key violation, which would let
+	// requests be used to probe for usernames.
+	if !server.allowLookup(ctx, authPayload.Username) {
+		return
+	}
+
+	request, err := server.store.CreatePaymentRequest(ctx, db.CreatePaymentRequestParams{
+		Requester:   authPayload.Username,
+		Payer:       req.Payer,
+		ToAccountID: req.ToAccountId,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Memo:        req.Memo,
+		ExpiresAt:   time.Now().Add(server.paymentRequestTTL),
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+type listPaymentRequestsRequest struct {
+	PAGE_ID   int32  `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending accepted declined cancelled expired"`
+}
+
+func (server *Server) listIncomingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	args := db.ListIncomingPaymentRequestsParams{
+		Payer:  authPayload.Username,
+		Limit:  req.PAGE_SIZE,
+		Offset: (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	}
+	if req.Status != "" {
+		args.Status = &req.Status
+	}
+
+	requests, err := server.store.ListIncomingPaymentRequests(ctx, args)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+func (server *Server) listOutgoingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	args := db.ListOutgoingPaymentRequestsParams{
+		Requester: authPayload.Username,
+		Limit:     req.PAGE_SIZE,
+		Offset:    (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	}
+	if req.Status != "" {
+		args.Status = &req.Status
+	}
+
+	requests, err := server.store.ListOutgoingPaymentRequests(ctx, args)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+type paymentRequestUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadPaymentRequest fetches the request in the :id path segment, provided
+// it was sent to or by username.
+func (server *Server) loadPaymentRequest(ctx *gin.Context, id int64, username string) (db.PaymentRequest, bool) {
+	request, err := server.store.GetPaymentRequest(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return request, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return request, false
+	}
+
+	if request.Payer != username && request.Requester != username {
+		writeError(ctx, http.StatusForbidden, token.ErrDoesNotBelong)
+		return request, false
+	}
+
+	return request, true
+}
+
+func (server *Server) getPaymentRequest(ctx *gin.Context) {
+	var req paymentRequestUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	request, ok := server.loadPaymentRequest(ctx, req.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountId int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+// acceptPaymentRequest pays a request addressed to the logged user. Accepting
+// a request that is already accepted returns it again without paying twice.
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var reqUri paymentRequestUriRequest
+	var reqJson acceptPaymentRequestRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqJson); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	request, ok := server.loadPaymentRequest(ctx, reqUri.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	if request.Payer != authPayload.Username {
+		writeError(ctx, http.StatusForbidden, token.ErrActionForbidden)
+		return
+	}
+
+	if request.Status == util.PaymentRequestAccepted {
+		ctx.JSON(http.StatusOK, db.AcceptPaymentRequestTxResult{Request: request, AlreadyAccepted: true})
+		return
+	}
+
+	if request.Status != util.PaymentRequestPending {
+		writeError(ctx, http.StatusConflict, db.ErrPaymentRequestNotPending)
+		return
+	}
+
+	fromAccount, ok := server.validateAccount(ctx, reqJson.FromAccountId, request.Currency, request.Amount, true, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	if _, ok := server.validateFee(ctx, fromAccount, request.Amount); !ok {
+		return
+	}
+
+	result, err := server.store.AcceptPaymentRequestTx(ctx, db.AcceptPaymentRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrPaymentRequestNotPending) || errors.Is(err, db.ErrPaymentRequestExpired) {
+			writeError(ctx, http.StatusConflict, err)
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	server.resolvePaymentRequest(ctx, util.PaymentRequestDeclined)
+}
+
+func (server *Server) cancelPaymentRequest(ctx *gin.Context) {
+	server.resolvePaymentRequest(ctx, util.PaymentRequestCancelled)
+}
+
+// resolvePaymentRequest closes a pending request without paying it. The
+// payer may decline a request; the requester may cancel it.
+func (server *Server) resolvePaymentRequest(ctx *gin.Context, status string) {
+	var req paymentRequestUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	request, ok := server.loadPaymentRequest(ctx, req.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	party := request.Payer
+	if status == util.PaymentRequestCancelled {
+		party = request.Requester
+	}
+
+	if party != authPayload.Username {
+		writeError(ctx, http.StatusForbidden, token.ErrActionForbidden)
+		return
+	}
+
+	resolved, err := server.store.ResolvePaymentRequest(ctx, db.ResolvePaymentRequestParams{
+		ID:     request.ID,
+		Status: status,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusConflict, fmt.Errorf("payment request [%d]: %w", request.ID, db.ErrPaymentRequestNotPending))
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resolved)
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPaymentRequestsAPI(t *testing.T) {
+	requester, _ := randomUser()
+	payer, _ := randomUser()
+
+	toAccount := randomAccount()
+	toAccount.Owner = requester.Username
+	toAccount.Currency = "EUR"
+
+	fromAccount := randomAccount()
+	fromAccount.Owner = payer.Username
+	fromAccount.Currency = "EUR"
+	fromAccount.Balance = 500
+
+	pending := db.PaymentRequest{
+		ID:          11,
+		Requester:   requester.Username,
+		Payer:       payer.Username,
+		ToAccountID: toAccount.ID,
+		Amount:      120,
+		Currency:    "EUR",
+		Status:      util.PaymentRequestPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	accepted := pending
+	accepted.Status = util.PaymentRequestAccepted
+
+	testCases := []struct {
+		name         string
+		username     string
+		method       string
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Create",
+			username: requester.Username,
+			method:   http.MethodPost,
+			url:      "/payment-requests",
+			body:     gin.H{"payer": payer.Username, "to_account_id": toAccount.ID, "amount": 120, "currency": "EUR", "memo": "Dinner"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, requester.Username, arg.Requester)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, "Dinner", arg.Memo)
+						require.WithinDuration(t, time.Now().Add(defaultPaymentRequestTTL), arg.ExpiresAt, time.Minute)
+						return pending, nil
+					})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CreateFromSelf",
+			username: requester.Username,
+			method:   http.MethodPost,
+			url:      "/payment-requests",
+			body:     gin.H{"payer": requester.Username, "to_account_id": toAccount.ID, "amount": 120, "currency": "EUR"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "CreateUnknownPayer",
+			username: requester.Username,
+			method:   http.MethodPost,
+			url:      "/payment-requests",
+			body:     gin.H{"payer": "nobody", "to_account_id": toAccount.ID, "amount": 120, "currency": "EUR"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.PaymentRequest{}, &pq.Error{Code: "23503"})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:     "ListIncoming",
+			username: payer.Username,
+			method:   http.MethodGet,
+			url:      "/payment-requests/incoming?page_id=1&page_size=5&status=pending",
+			buildStubs: func(store *mockdb.MockStore) {
+				status := util.PaymentRequestPending
+				store.EXPECT().ListIncomingPaymentRequests(gomock.Any(), db.ListIncomingPaymentRequestsParams{
+					Payer:  payer.Username,
+					Status: &status,
+					Limit:  5,
+				}).Times(1).Return([]db.PaymentRequest{pending}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ListOutgoing",
+			username: requester.Username,
+			method:   http.MethodGet,
+			url:      "/payment-requests/outgoing?page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListOutgoingPaymentRequests(gomock.Any(), db.ListOutgoingPaymentRequestsParams{
+					Requester: requester.Username,
+					Limit:     5,
+					Offset:    5,
+				}).Times(1).Return([]db.PaymentRequest{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Accept",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/accept", pending.ID),
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), db.AcceptPaymentRequestTxParams{
+					RequestID:     pending.ID,
+					FromAccountID: fromAccount.ID,
+				}).Times(1).Return(db.AcceptPaymentRequestTxResult{Request: accepted, Transfer: &db.TransferTxResult{}}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "AcceptTwice",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/accept", pending.ID),
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(accepted, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AcceptPaymentRequestTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.True(t, got.AlreadyAccepted)
+				require.Nil(t, got.Transfer)
+			},
+		},
+		{
+			name:     "AcceptExpired",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/accept", pending.ID),
+			body:     gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.AcceptPaymentRequestTxResult{}, db.ErrPaymentRequestExpired)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, CodePaymentRequestExpired)
+			},
+		},
+		{
+			name:     "AcceptByRequester",
+			username: requester.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/accept", pending.ID),
+			body:     gin.H{"from_account_id": toAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "Decline",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/decline", pending.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().ResolvePaymentRequest(gomock.Any(), db.ResolvePaymentRequestParams{
+					ID:     pending.ID,
+					Status: util.PaymentRequestDeclined,
+				}).Times(1).Return(db.PaymentRequest{ID: pending.ID, Status: util.PaymentRequestDeclined}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "DeclineResolved",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/decline", pending.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().ResolvePaymentRequest(gomock.Any(), gomock.Any()).Times(1).Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, CodePaymentRequestNotPending)
+			},
+		},
+		{
+			name:     "CancelByPayer",
+			username: payer.Username,
+			method:   http.MethodPost,
+			url:      fmt.Sprintf("/payment-requests/%d/cancel", pending.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+				store.EXPECT().ResolvePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "GetByStranger",
+			username: "stranger",
+			method:   http.MethodGet,
+			url:      fmt.Sprintf("/payment-requests/%d", pending.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), pending.ID).Times(1).Return(pending, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
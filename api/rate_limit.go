@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/ratelimit"
+)
+
+const (
+	rateLimitHeader          = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	retryAfterHeader         = "Retry-After"
+)
+
+var (
+	errRateLimited    = errors.New("too many requests, try again later")
+	errTooManyLookups = errors.New("too many recipient lookups, try again later")
+)
+
+// rateLimitGroup is a set of routes sharing one limit, set by the
+// RATE_LIMIT_<NAME> key. Groups in front of AuthMiddleware are keyed by
+// client IP, the rest by username. The gRPC server applies the same groups
+// through rpcRateLimitGroups.
+type rateLimitGroup struct {
+	name         string
+	defaultLimit string
+}
+
+var (
+	signupRateLimit   = rateLimitGroup{name: "signup", defaultLimit: "5/1h"}
+	loginRateLimit    = rateLimitGroup{name: "login", defaultLimit: "10/1m"}
+	transferRateLimit = rateLimitGroup{name: "transfers", defaultLimit: "30/1m"}
+	// lookupRateLimit caps how many recipients each user can resolve, so
+	// account numbers and handles cannot be used to enumerate customers.
+	lookupRateLimit = rateLimitGroup{name: "lookups", defaultLimit: "10/1m"}
+)
+
+var rateLimitGroups = []rateLimitGroup{signupRateLimit, loginRateLimit, transferRateLimit, lookupRateLimit}
+
+// rateLimitsFromEnv reads the limit of every group; an unset key keeps the
+// default and "off" turns the group's limit off.
+func rateLimitsFromEnv() (map[string]ratelimit.Limit, error) {
+	limits := make(map[string]ratelimit.Limit, len(rateLimitGroups))
+	for _, group := range rateLimitGroups {
+		key := "RATE_LIMIT_" + strings.ToUpper(group.name)
+		value := viper.GetString(key)
+		if value == "" {
+			value = group.defaultLimit
+		}
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		limits[group.name] = limit
+	}
+	return limits, nil
+}
+
+// newRateLimitStore picks where buckets live from RATE_LIMIT_STORE. Running
+// more than one instance needs "postgres", or each counts on its own.
+func newRateLimitStore(store db.Store, kind string) (ratelimit.Store, error) {
+	switch kind {
+	case "", "memory":
+		return ratelimit.NewMemoryStore(), nil
+	case "postgres":
+		return ratelimit.NewPostgresStore(store), nil
+	}
+	return nil, fmt.Errorf("RATE_LIMIT_STORE must be memory or postgres, got %q", kind)
+}
+
+func rateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+func rateLimitByUsername(ctx *gin.Context) string {
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return rateLimitByIP(ctx)
+	}
+	return "user:" + authPayload.Username
+}
+
+// takeRateLimit takes a token from the bucket of group for the client named
+// by key. It returns nil when there is nothing to enforce: the group's limit
+// is off, or the store failed. An outage of the limiter must not take the API
+// down with it, so such requests go through.
+func (server *Server) takeRateLimit(ctx context.Context, group rateLimitGroup, key string) *ratelimit.Result {
+	limit := server.rateLimits[group.name]
+	if !limit.Enabled() {
+		return nil
+	}
+
+	result, err := server.rateLimitStore.Take(ctx, group.name+":"+key, limit)
+	if err != nil {
+		slog.WarnContext(ctx, "rate limiter unavailable, letting request through",
+			slog.String("group", group.name), slog.Any("error", err))
+		return nil
+	}
+	return &result
+}
+
+// retryAfterSeconds is the Retry-After value for a rejected result.
+func retryAfterSeconds(result *ratelimit.Result) string {
+	retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+	return strconv.Itoa(max(retryAfter, 1))
+}
+
+// rateLimit rejects the request with a 429 and Retry-After once the client
+// named by key has used up the bucket of group.
+func (server *Server) rateLimit(group rateLimitGroup, key func(ctx *gin.Context) string) gin.HandlerFunc {
+	if !server.rateLimits[group.name].Enabled() {
+		return func(ctx *gin.Context) { ctx.Next() }
+	}
+
+	return func(ctx *gin.Context) {
+		result := server.takeRateLimit(ctx, group, key(ctx))
+		if result == nil {
+			ctx.Next()
+			return
+		}
+
+		ctx.Header(rateLimitHeader, strconv.Itoa(server.rateLimits[group.name].Burst))
+		ctx.Header(rateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+		if !result.Allowed {
+			ctx.Header(retryAfterHeader, retryAfterSeconds(result))
+			abortWithError(ctx, http.StatusTooManyRequests, errRateLimited)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// allowLookup writes a 429 and returns false once username has used up its
+// recipient lookups.
+func (server *Server) allowLookup(ctx *gin.Context, username string) bool {
+	result := server.takeRateLimit(ctx, lookupRateLimit, "user:"+username)
+	if result != nil && !result.Allowed {
+		ctx.Header(retryAfterHeader, retryAfterSeconds(result))
+		writeError(ctx, http.StatusTooManyRequests, errTooManyLookups)
+		return false
+	}
+
+	return true
+}
+
+// trustedProxiesFromEnv reads TRUSTED_PROXIES, a comma-separated list of
+// addresses or CIDRs. Unset, no proxy is trusted and ClientIP is the peer
+// address.
+func trustedProxiesFromEnv() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(viper.GetString("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	"github.com/ulunnuha-h/simple_bank/ratelimit"
+	"go.uber.org/mock/gomock"
+)
+
+// setConfig sets a viper key for the rest of the test.
+func setConfig(t *testing.T, key string, value any) {
+	previous := viper.Get(key)
+	viper.Set(key, value)
+	t.Cleanup(func() { viper.Set(key, previous) })
+}
+
+func newRateLimitedServer(t *testing.T) *Server {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	return server
+}
+
+// postEmpty sends a request with an empty JSON body, which the handlers
+// reject with a 400 without touching the store, so any other status comes
+// from the rate limiter.
+func postEmpty(server *Server, path string, remoteAddr string, prepare func(request *http.Request)) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString("{}"))
+	request.RemoteAddr = remoteAddr
+	if prepare != nil {
+		prepare(request)
+	}
+	server.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestRateLimitLoginByIP(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_LOGIN", "2/1m")
+	server := newRateLimitedServer(t)
+
+	for i := 1; i >= 0; i-- {
+		recorder := postEmpty(server, "/users/login", "203.0.113.7:4000", nil)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, "2", recorder.Header().Get(rateLimitHeader))
+		require.Equal(t, strconv.Itoa(i), recorder.Header().Get(rateLimitRemainingHeader))
+	}
+
+	// A new port or a forged X-Forwarded-For is still the same client.
+	recorder := postEmpty(server, "/users/login", "203.0.113.7:4001", func(request *http.Request) {
+		request.Header.Set("X-Forwarded-For", "198.51.100.1")
+	})
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	requireErrorCode(t, recorder, CodeRateLimited)
+	require.Equal(t, "30", recorder.Header().Get(retryAfterHeader))
+
+	recorder = postEmpty(server, "/users/login", "203.0.113.8:4000", nil)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Signup has a bucket of its own.
+	recorder = postEmpty(server, "/users", "203.0.113.7:4000", nil)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRateLimitTrustedProxy(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_LOGIN", "1/1m")
+	setConfig(t, "TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	server := newRateLimitedServer(t)
+
+	forwardedFor := func(ip string) func(request *http.Request) {
+		return func(request *http.Request) {
+			request.Header.Set("X-Forwarded-For", ip)
+		}
+	}
+
+	recorder := postEmpty(server, "/users/login", "10.1.2.3:4000", forwardedFor("198.51.100.1"))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = postEmpty(server, "/users/login", "10.1.2.4:4000", forwardedFor("198.51.100.2"))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = postEmpty(server, "/users/login", "10.1.2.4:4000", forwardedFor("198.51.100.1"))
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestRateLimitTransfersByUsername(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_TRANSFERS", "1/1m")
+	server := newRateLimitedServer(t)
+
+	as := func(username string) func(request *http.Request) {
+		return func(request *http.Request) {
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, username, time.Minute)
+		}
+	}
+
+	recorder := postEmpty(server, "/transfers", "203.0.113.7:4000", as("alice"))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Transfer batches draw from the same bucket.
+	recorder = postEmpty(server, "/transfer-batches", "203.0.113.7:4000", as("alice"))
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	requireErrorCode(t, recorder, CodeRateLimited)
+	require.Equal(t, "60", recorder.Header().Get(retryAfterHeader))
+
+	// Another user behind the same address is not affected.
+	recorder = postEmpty(server, "/transfers", "203.0.113.7:4000", as("bob"))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Without a token the request fails authentication before any limit.
+	recorder = postEmpty(server, "/transfers", "203.0.113.7:4000", nil)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestRateLimitOff(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_LOGIN", "off")
+	server := newRateLimitedServer(t)
+
+	for i := 0; i < 20; i++ {
+		recorder := postEmpty(server, "/users/login", "203.0.113.7:4000", nil)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Empty(t, recorder.Header().Get(rateLimitHeader))
+	}
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestRateLimitStoreDownLetsRequestsThrough(t *testing.T) {
+	setConfig(t, "RATE_LIMIT_LOGIN", "1/1m")
+	server := newRateLimitedServer(t)
+	server.rateLimitStore = failingRateLimitStore{}
+
+	for i := 0; i < 3; i++ {
+		recorder := postEmpty(server, "/users/login", "203.0.113.7:4000", nil)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	}
+}
+
+func TestRateLimitConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "BadLimit", key: "RATE_LIMIT_SIGNUP", value: "lots"},
+		{name: "UnknownStore", key: "RATE_LIMIT_STORE", value: "redis"},
+		{name: "BadProxy", key: "TRUSTED_PROXIES", value: "not-an-ip"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setConfig(t, tc.key, tc.value)
+
+			ctrl := gomock.NewController(t)
+			_, err := NewServer(mockdb.NewMockStore(ctrl))
+			require.ErrorContains(t, err, tc.key)
+		})
+	}
+}
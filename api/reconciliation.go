@@ -0,0 +1,73 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+func (server *Server) runReconciliation(ctx *gin.Context) {
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	// A failed scan still produces a finished run describing the failure.
+	run, err := server.reconciler.Run(ctx, authPayload.Username)
+	if err != nil && run.ID == 0 {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
+
+type getReconciliationRunRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getReconciliationRun(ctx *gin.Context) {
+	var req getReconciliationRunRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	run, err := server.store.GetReconciliationRun(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
+
+type listReconciliationRunsRequest struct {
+	PAGE_ID   int32 `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listReconciliationRuns(ctx *gin.Context) {
+	var req listReconciliationRunsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	runs, err := server.store.ListReconciliationRuns(ctx, db.ListReconciliationRunsParams{
+		Limit:  req.PAGE_SIZE,
+		Offset: (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
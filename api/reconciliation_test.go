@@ -0,0 +1,160 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/reconcile"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRunReconciliationAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+	depositor, _ := randomUser()
+	depositor.Role = util.DepositorRole
+
+	testCases := []struct {
+		name         string
+		user         db.User
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), gomock.Eq(admin.Username)).Times(1).
+					Return(db.ReconciliationRun{ID: 1, Status: reconcile.StatusRunning}, nil)
+				store.EXPECT().ListAccountLedgerTotals(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.ListAccountLedgerTotalsRow{{ID: 1, Balance: 10, EntriesTotal: 10}}, nil)
+				store.EXPECT().ListTransferLedgerTotals(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.ListTransferLedgerTotalsRow{}, nil)
+				store.EXPECT().FinishReconciliationRun(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReconciliationRun{ID: 1, Status: reconcile.StatusBalanced, AccountsChecked: 1}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var run db.ReconciliationRun
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &run))
+				require.Equal(t, reconcile.StatusBalanced, run.Status)
+				require.Equal(t, int64(1), run.AccountsChecked)
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: depositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(depositor.Username)).Times(1).Return(depositor, nil)
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name: "StartError",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ReconciliationRun{}, sql.ErrConnDone)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/reconciliation/runs", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestGetReconciliationRunAPI(t *testing.T) {
+	admin, _ := randomUser()
+	admin.Role = util.AdminRole
+
+	testCases := []struct {
+		name         string
+		runID        int64
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			runID: 3,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(int64(3))).Times(1).
+					Return(db.ReconciliationRun{ID: 3, Status: reconcile.StatusDrift}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			runID: 4,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(int64(4))).Times(1).
+					Return(db.ReconciliationRun{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/reconciliation/runs/%d", tc.runID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
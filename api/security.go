@@ -0,0 +1,231 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	refreshCookieName = "refresh_token"
+	refreshCookiePath = "/users/refresh"
+	csrfCookieName    = "csrf_token"
+	csrfHeader        = "X-CSRF-Token"
+
+	defaultCORSMaxAge = 10 * time.Minute
+	hstsMaxAge        = 2 * 365 * 24 * time.Hour
+)
+
+var (
+	errCSRFToken        = errors.New("missing or invalid CSRF token")
+	errOriginNotAllowed = errors.New("origin is not allowed")
+)
+
+var (
+	corsAllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete}
+	corsAllowedHeaders = []string{"Authorization", "Content-Type", csrfHeader, requestIDHeader, "traceparent", "tracestate"}
+	corsExposedHeaders = []string{requestIDHeader, retryAfterHeader, rateLimitHeader, rateLimitRemainingHeader, "traceparent"}
+)
+
+// CORSConfig lists the browser origins allowed to call the API with
+// credentials, such as "https://app.example.com".
+type CORSConfig struct {
+	AllowedOrigins []string
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// CookieConfig controls the attributes of the session cookies.
+type CookieConfig struct {
+	// Domain is empty for a host-only cookie.
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// corsConfigFromEnv reads CORS_ALLOWED_ORIGINS, a comma-separated list of
+// origins, and CORS_MAX_AGE. With no origins, browsers on other sites get
+// no access.
+func corsConfigFromEnv() (CORSConfig, error) {
+	config := CORSConfig{MaxAge: orDefault(viper.GetDuration("CORS_MAX_AGE"), defaultCORSMaxAge)}
+	for _, origin := range strings.Split(viper.GetString("CORS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+
+		// Credentials are allowed, so a wildcard would hand every site the
+		// user's session.
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || strings.Contains(origin, "*") {
+			return CORSConfig{}, fmt.Errorf("CORS_ALLOWED_ORIGINS: %q is not an origin such as https://app.example.com", origin)
+		}
+		config.AllowedOrigins = append(config.AllowedOrigins, strings.ToLower(origin))
+	}
+	return config, nil
+}
+
+// cookieConfigFromEnv reads COOKIE_DOMAIN, COOKIE_SECURE and COOKIE_SAMESITE.
+// Cookies are Secure and SameSite=Strict unless configured otherwise.
+func cookieConfigFromEnv() (CookieConfig, error) {
+	config := CookieConfig{Domain: viper.GetString("COOKIE_DOMAIN"), Secure: true, SameSite: http.SameSiteStrictMode}
+
+	if value := viper.GetString("COOKIE_SECURE"); value != "" {
+		secure, err := strconv.ParseBool(value)
+		if err != nil {
+			return CookieConfig{}, fmt.Errorf("COOKIE_SECURE: %w", err)
+		}
+		config.Secure = secure
+	}
+
+	switch strings.ToLower(viper.GetString("COOKIE_SAMESITE")) {
+	case "", "strict":
+	case "lax":
+		config.SameSite = http.SameSiteLaxMode
+	case "none":
+		if !config.Secure {
+			return CookieConfig{}, errors.New("COOKIE_SAMESITE: none requires COOKIE_SECURE")
+		}
+		config.SameSite = http.SameSiteNoneMode
+	default:
+		return CookieConfig{}, fmt.Errorf("COOKIE_SAMESITE must be strict, lax or none, got %q", viper.GetString("COOKIE_SAMESITE"))
+	}
+	return config, nil
+}
+
+// SecurityHeaders sets headers telling browsers to use HTTPS only, not to
+// guess content types and not to frame, cache or render the responses.
+func SecurityHeaders() gin.HandlerFunc {
+	hsts := fmt.Sprintf("max-age=%d; includeSubDomains", int(hstsMaxAge.Seconds()))
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		header.Set("Strict-Transport-Security", hsts)
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Cache-Control", "no-store")
+		ctx.Next()
+	}
+}
+
+// CORSMiddleware answers preflight requests and grants allowed origins
+// access to responses. A request carrying any other cross-site Origin is
+// refused before it reaches a handler, so a page elsewhere cannot make the
+// browser act with the user's cookies even where it can't read the answer.
+// Requests without an Origin, from clients other than browsers, pass.
+func CORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	allowedMethods := strings.Join(corsAllowedMethods, ", ")
+	allowedHeaders := strings.Join(corsAllowedHeaders, ", ")
+	exposedHeaders := strings.Join(corsExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		header := ctx.Writer.Header()
+		header.Add("Vary", "Origin")
+		preflight := ctx.Request.Method == http.MethodOptions && ctx.GetHeader("Access-Control-Request-Method") != ""
+
+		if !slices.Contains(config.AllowedOrigins, strings.ToLower(origin)) {
+			if sameOrigin(ctx.Request, origin) && !preflight {
+				ctx.Next()
+				return
+			}
+			abortWithError(ctx, http.StatusForbidden, errOriginNotAllowed)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowedMethods)
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+			header.Set("Access-Control-Max-Age", maxAge)
+			ctx.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		header.Set("Access-Control-Expose-Headers", exposedHeaders)
+		ctx.Next()
+	}
+}
+
+// sameOrigin reports whether origin is the API's own host.
+func sameOrigin(request *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, request.Host)
+}
+
+// setSessionCookies hands the browser the refresh token, readable only by
+// the refresh endpoint, and the CSRF token that must be echoed back in the
+// X-CSRF-Token header when refreshing.
+func (server *Server) setSessionCookies(ctx *gin.Context, refreshToken string, csrfToken string, maxAge time.Duration) {
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    refreshToken,
+		Path:     refreshCookiePath,
+		Domain:   server.cookies.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   server.cookies.Secure,
+		HttpOnly: true,
+		SameSite: server.cookies.SameSite,
+	})
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		Domain:   server.cookies.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   server.cookies.Secure,
+		SameSite: server.cookies.SameSite,
+	})
+}
+
+// csrfToken derives the CSRF token of a session from its id, so a token
+// planted in the cookie by another site does not match the session.
+func (server *Server) csrfToken(sessionID uuid.UUID) string {
+	mac := hmac.New(sha256.New, server.csrfKey)
+	mac.Write(sessionID[:])
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkCSRF makes sure the X-CSRF-Token header repeats the csrf_token
+// cookie and that both belong to the session of refreshToken.
+func (server *Server) checkCSRF(ctx *gin.Context, refreshToken string) error {
+	submitted := ctx.GetHeader(csrfHeader)
+	cookie, err := ctx.Cookie(csrfCookieName)
+	if err != nil || submitted == "" || subtle.ConstantTimeCompare([]byte(submitted), []byte(cookie)) != 1 {
+		return errCSRFToken
+	}
+
+	payload, err := server.tokenGenerator.Verify(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(server.csrfToken(payload.ID))) != 1 {
+		return errCSRFToken
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+const allowedOrigin = "https://app.example.com"
+
+func TestSecurityHeaders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	header := recorder.Header()
+	require.Equal(t, "max-age=63072000; includeSubDomains", header.Get("Strict-Transport-Security"))
+	require.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", header.Get("X-Frame-Options"))
+	require.Equal(t, "default-src 'none'; frame-ancestors 'none'", header.Get("Content-Security-Policy"))
+	require.Equal(t, "no-referrer", header.Get("Referrer-Policy"))
+	require.Equal(t, "no-store", header.Get("Cache-Control"))
+}
+
+func TestCORS(t *testing.T) {
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		origin       string
+		preflight    bool
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "AllowedPreflight",
+			method:    http.MethodOptions,
+			path:      "/transfers",
+			origin:    allowedOrigin,
+			preflight: true,
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+				require.Equal(t, allowedOrigin, recorder.Header().Get("Access-Control-Allow-Origin"))
+				require.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+				require.Equal(t, "GET, POST, PUT, DELETE", recorder.Header().Get("Access-Control-Allow-Methods"))
+				require.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), csrfHeader)
+				require.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+				require.Contains(t, recorder.Header().Values("Vary"), "Origin")
+			},
+		},
+		{
+			name:      "BlockedPreflight",
+			method:    http.MethodOptions,
+			path:      "/transfers",
+			origin:    "https://evil.example.net",
+			preflight: true,
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeOriginNotAllowed)
+				require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+				require.Empty(t, recorder.Header().Get("Access-Control-Allow-Methods"))
+			},
+		},
+		{
+			name:   "AllowedRequest",
+			method: http.MethodGet,
+			path:   "/healthz",
+			origin: allowedOrigin,
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, allowedOrigin, recorder.Header().Get("Access-Control-Allow-Origin"))
+				require.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+				require.Contains(t, recorder.Header().Get("Access-Control-Expose-Headers"), requestIDHeader)
+				require.Contains(t, recorder.Header().Get("Access-Control-Expose-Headers"), retryAfterHeader)
+			},
+		},
+		{
+			// The login never runs, so a page elsewhere cannot log the
+			// browser in or probe passwords through it.
+			name:   "BlockedRequest",
+			method: http.MethodPost,
+			path:   "/users/login",
+			origin: "https://evil.example.net",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeOriginNotAllowed)
+				require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+			},
+		},
+		{
+			name:   "SameOrigin",
+			method: http.MethodGet,
+			path:   "/healthz",
+			origin: "http://example.com",
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+			},
+		},
+		{
+			name:   "NoOrigin",
+			method: http.MethodGet,
+			path:   "/healthz",
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+				require.Empty(t, recorder.Header().Values("Vary"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setConfig(t, "CORS_ALLOWED_ORIGINS", "https://other.example.com, "+allowedOrigin)
+
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString("{}"))
+			if tc.origin != "" {
+				request.Header.Set("Origin", tc.origin)
+			}
+			if tc.preflight {
+				request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+				request.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func loginForCookies(t *testing.T) (*httptest.ResponseRecorder, loginUserReponse) {
+	user, password := randomUser()
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+	user.HashedPassword = hashedPassword
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, args db.CreateSessionParams) (db.Session, error) {
+			return db.Session{ID: args.ID, Username: args.Username}, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(loginUserRequest{Username: user.Username, Password: password})
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var login loginUserReponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &login))
+	return recorder, login
+}
+
+func findCookie(t *testing.T, recorder *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	require.FailNow(t, "cookie not set", name)
+	return nil
+}
+
+func TestLoginCookies(t *testing.T) {
+	recorder, login := loginForCookies(t)
+
+	refresh := findCookie(t, recorder, refreshCookieName)
+	require.True(t, refresh.HttpOnly)
+	require.True(t, refresh.Secure)
+	require.Equal(t, http.SameSiteStrictMode, refresh.SameSite)
+	require.Equal(t, refreshCookiePath, refresh.Path)
+	require.Empty(t, refresh.Domain)
+	require.Equal(t, 3600, refresh.MaxAge)
+
+	csrf := findCookie(t, recorder, csrfCookieName)
+	require.False(t, csrf.HttpOnly)
+	require.True(t, csrf.Secure)
+	require.Equal(t, http.SameSiteStrictMode, csrf.SameSite)
+	require.Equal(t, "/", csrf.Path)
+	require.NotEmpty(t, login.CSRFToken)
+	require.Equal(t, login.CSRFToken, csrf.Value)
+}
+
+func TestLoginCookiesConfigured(t *testing.T) {
+	setConfig(t, "COOKIE_DOMAIN", "bank.example.com")
+	setConfig(t, "COOKIE_SECURE", "false")
+	setConfig(t, "COOKIE_SAMESITE", "lax")
+
+	recorder, _ := loginForCookies(t)
+	for _, name := range []string{refreshCookieName, csrfCookieName} {
+		cookie := findCookie(t, recorder, name)
+		require.Equal(t, "bank.example.com", cookie.Domain)
+		require.False(t, cookie.Secure)
+		require.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	}
+}
+
+func TestSecurityConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config map[string]string
+		key    string
+	}{
+		{name: "WildcardOrigin", config: map[string]string{"CORS_ALLOWED_ORIGINS": "*"}, key: "CORS_ALLOWED_ORIGINS"},
+		{name: "OriginWithPath", config: map[string]string{"CORS_ALLOWED_ORIGINS": "https://app.example.com/login"}, key: "CORS_ALLOWED_ORIGINS"},
+		{name: "OriginWithoutScheme", config: map[string]string{"CORS_ALLOWED_ORIGINS": "app.example.com"}, key: "CORS_ALLOWED_ORIGINS"},
+		{name: "BadSecure", config: map[string]string{"COOKIE_SECURE": "maybe"}, key: "COOKIE_SECURE"},
+		{name: "BadSameSite", config: map[string]string{"COOKIE_SAMESITE": "loose"}, key: "COOKIE_SAMESITE"},
+		{name: "SameSiteNoneInsecure", config: map[string]string{"COOKIE_SAMESITE": "none", "COOKIE_SECURE": "false"}, key: "COOKIE_SAMESITE"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.config {
+				setConfig(t, key, value)
+			}
+
+			ctrl := gomock.NewController(t)
+			_, err := NewServer(mockdb.NewMockStore(ctrl))
+			require.ErrorContains(t, err, tc.key)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 15 * time.Second
+	defaultIdleTimeout       = 2 * time.Minute
+	defaultMaxHeaderBytes    = 1 << 20
+	defaultShutdownTimeout   = 20 * time.Second
+)
+
+// Routes that legitimately outlast the server-wide timeouts set their own
+// deadlines with withDeadlines.
+const (
+	// longPollWriteTimeout leaves room for the longest /events wait.
+	longPollWriteTimeout = 45 * time.Second
+	// downloadWriteTimeout bounds streamed files such as statements, which
+	// grow with the date range asked for.
+	downloadWriteTimeout = 10 * time.Minute
+	// uploadReadTimeout gives a batch file time to arrive over a slow link.
+	uploadReadTimeout = 2 * time.Minute
+)
+
+// ServeConfig configures the listeners started by Serve. Zero durations and
+// sizes fall back to the defaults above.
+type ServeConfig struct {
+	Address     string
+	GRPCAddress string
+	// InternalAddress serves the operational endpoints, /debug/vars and
+	// /metrics, which must not be reachable from the internet. Left
+	// empty, they are not served at all.
+	InternalAddress   string
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout and WriteTimeout hold for every route but the uploads,
+	// downloads and long-polls that set their own with withDeadlines.
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	MaxHeaderBytes int
+	// ShutdownTimeout bounds how long in-flight requests may drain.
+	ShutdownTimeout time.Duration
+}
+
+// ServeConfigFromEnv reads ServeConfig from the loaded configuration.
+func ServeConfigFromEnv() ServeConfig {
+	return ServeConfig{
+		Address:           viper.GetString("SERVER_ADDRESS"),
+		GRPCAddress:       viper.GetString("GRPC_SERVER_ADDRESS"),
+		InternalAddress:   viper.GetString("INTERNAL_SERVER_ADDRESS"),
+		ReadHeaderTimeout: viper.GetDuration("HTTP_READ_HEADER_TIMEOUT"),
+		ReadTimeout:       viper.GetDuration("HTTP_READ_TIMEOUT"),
+		WriteTimeout:      viper.GetDuration("HTTP_WRITE_TIMEOUT"),
+		IdleTimeout:       viper.GetDuration("HTTP_IDLE_TIMEOUT"),
+		MaxHeaderBytes:    viper.GetInt("HTTP_MAX_HEADER_BYTES"),
+		ShutdownTimeout:   viper.GetDuration("SHUTDOWN_TIMEOUT"),
+	}
+}
+
+func orDefault[T int | time.Duration](value T, fallback T) T {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// withDeadlines replaces the server's read and write timeouts for one route,
+// counting from when its handlers start. A zero duration keeps the server's.
+func withDeadlines(read, write time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		rc := http.NewResponseController(ctx.Writer)
+		now := time.Now()
+		if read > 0 {
+			setDeadline(ctx, "read", rc.SetReadDeadline, now.Add(read))
+		}
+		if write > 0 {
+			setDeadline(ctx, "write", rc.SetWriteDeadline, now.Add(write))
+		}
+		ctx.Next()
+	}
+}
+
+// setDeadline logs a failure to set a deadline rather than failing the
+// request: the route then runs under the server's timeout. Writers that
+// aren't backed by a connection, as in tests, don't support deadlines.
+func setDeadline(ctx *gin.Context, kind string, set func(time.Time) error, deadline time.Time) {
+	if err := set(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(ctx, "cannot set "+kind+" deadline", slog.Any("error", err))
+	}
+}
+
+// NewHTTPServer returns the http.Server that serves the HTTP API.
+func (server *Server) NewHTTPServer(config ServeConfig) *http.Server {
+	return newHTTPServer(config, config.Address, server.router)
+}
+
+// NewInternalServer returns the http.Server that serves the operational
+// endpoints on config.InternalAddress.
+func (server *Server) NewInternalServer(config ServeConfig) *http.Server {
+	return newHTTPServer(config, config.InternalAddress, server.internalRouter)
+}
+
+func newHTTPServer(config ServeConfig, address string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: orDefault(config.ReadHeaderTimeout, defaultReadHeaderTimeout),
+		ReadTimeout:       orDefault(config.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      orDefault(config.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       orDefault(config.IdleTimeout, defaultIdleTimeout),
+		MaxHeaderBytes:    orDefault(config.MaxHeaderBytes, defaultMaxHeaderBytes),
+	}
+}
+
+// Serve runs the HTTP API, the gRPC API when config.GRPCAddress is set and
+// the internal endpoints when config.InternalAddress is set, until ctx is
+// cancelled. It then stops accepting connections and waits up
+// to config.ShutdownTimeout for in-flight requests, so a transfer that has
+// started is not cut off. Serve returns nil after a clean shutdown.
+func (server *Server) Serve(ctx context.Context, config ServeConfig) error {
+	httpListener, err := net.Listen("tcp", config.Address)
+	if err != nil {
+		return err
+	}
+
+	var grpcListener net.Listener
+	if config.GRPCAddress != "" {
+		grpcListener, err = net.Listen("tcp", config.GRPCAddress)
+		if err != nil {
+			httpListener.Close()
+			return err
+		}
+	}
+
+	var internalListener net.Listener
+	if config.InternalAddress != "" {
+		internalListener, err = net.Listen("tcp", config.InternalAddress)
+		if err != nil {
+			httpListener.Close()
+			if grpcListener != nil {
+				grpcListener.Close()
+			}
+			return err
+		}
+	}
+
+	return server.serve(ctx, config, httpListener, grpcListener, internalListener)
+}
+
+func (server *Server) serve(ctx context.Context, config ServeConfig, httpListener net.Listener, grpcListener net.Listener, internalListener net.Listener) error {
+	httpServer := server.NewHTTPServer(config)
+	httpServer.RegisterOnShutdown(server.drain)
+
+	errs := make(chan error, 3)
+	go func() {
+		if err := httpServer.Serve(httpListener); !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+	}()
+
+	internalServer := server.NewInternalServer(config)
+	if internalListener != nil {
+		go func() {
+			if err := internalServer.Serve(internalListener); !errors.Is(err, http.ErrServerClosed) {
+				errs <- err
+			}
+		}()
+	}
+
+	grpcServer := server.NewGRPCServer()
+	if grpcListener != nil {
+		go func() {
+			errs <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case serveErr = <-errs:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), orDefault(config.ShutdownTimeout, defaultShutdownTimeout))
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	err := httpServer.Shutdown(shutdownCtx)
+	// Nothing on the internal listener is worth waiting for.
+	internalServer.Close()
+
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	if serveErr != nil {
+		return serveErr
+	}
+	return err
+}
+
+// drain ends long-polls early so they don't hold up shutdown.
+func (server *Server) drain() {
+	server.drainOnce.Do(func() { close(server.draining) })
+}
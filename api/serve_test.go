@@ -0,0 +1,263 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+type serveResult struct {
+	response *http.Response
+	err      error
+}
+
+// startServe runs server.serve on a random port until SIGTERM is received
+// and returns its base URL and the channel Serve's result is sent on.
+func startServe(t *testing.T, server *Server, config ServeConfig) (string, <-chan error) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	t.Cleanup(stop)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serve(ctx, config, listener, nil, nil)
+	}()
+
+	return "http://" + listener.Addr().String(), done
+}
+
+func sendRequest(t *testing.T, server *Server, url string, username string) <-chan serveResult {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenGenerator, authTypeBearer, username, time.Minute)
+
+	result := make(chan serveResult, 1)
+	go func() {
+		response, err := http.DefaultClient.Do(request)
+		if err == nil {
+			response.Body.Close()
+		}
+		result <- serveResult{response, err}
+	}()
+	return result
+}
+
+func sendSIGTERM(t *testing.T) {
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+}
+
+func TestServeDrainsOnSIGTERM(t *testing.T) {
+	account := randomAccount()
+
+	inFlight := make(chan struct{})
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).
+		DoAndReturn(func(_ context.Context, _ int64) (db.Account, error) {
+			close(inFlight)
+			time.Sleep(200 * time.Millisecond)
+			return account, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	baseURL, done := startServe(t, server, ServeConfig{ShutdownTimeout: 5 * time.Second})
+
+	result := sendRequest(t, server, fmt.Sprintf("%s/accounts/%d", baseURL, account.ID), account.Owner)
+	<-inFlight
+	sendSIGTERM(t)
+
+	res := <-result
+	require.NoError(t, res.err)
+	require.Equal(t, http.StatusOK, res.response.StatusCode)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after SIGTERM")
+	}
+
+	_, err = http.Get(baseURL + "/openapi.json")
+	require.Error(t, err)
+}
+
+func TestServeShutdownTimeout(t *testing.T) {
+	account := randomAccount()
+
+	inFlight := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).
+		DoAndReturn(func(_ context.Context, _ int64) (db.Account, error) {
+			close(inFlight)
+			<-release
+			return account, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	baseURL, done := startServe(t, server, ServeConfig{ShutdownTimeout: 50 * time.Millisecond})
+
+	sendRequest(t, server, fmt.Sprintf("%s/accounts/%d", baseURL, account.ID), account.Owner)
+	<-inFlight
+	sendSIGTERM(t)
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not give up after the shutdown timeout")
+	}
+}
+
+func TestServeEndsLongPollOnSIGTERM(t *testing.T) {
+	user, _ := randomUser()
+	user.Role = util.ServiceRole
+
+	polling := make(chan struct{}, 1)
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), user.Username).Times(1).Return(user, nil)
+	store.EXPECT().ListEvents(gomock.Any(), gomock.Any()).MinTimes(1).
+		DoAndReturn(func(_ context.Context, _ db.ListEventsParams) ([]db.Event, error) {
+			select {
+			case polling <- struct{}{}:
+			default:
+			}
+			return []db.Event{}, nil
+		})
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	baseURL, done := startServe(t, server, ServeConfig{ShutdownTimeout: 5 * time.Second})
+
+	start := time.Now()
+	result := sendRequest(t, server, baseURL+"/events?wait=30", user.Username)
+	<-polling
+	sendSIGTERM(t)
+
+	res := <-result
+	require.NoError(t, res.err)
+	require.Equal(t, http.StatusOK, res.response.StatusCode)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.NoError(t, <-done)
+}
+
+func TestServeInternalEndpoints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(mockdb.NewMockStore(ctrl))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	internalListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serve(ctx, ServeConfig{}, listener, nil, internalListener)
+	}()
+
+	for _, path := range []string{"/debug/vars", "/metrics"} {
+		response, err := http.Get("http://" + internalListener.Addr().String() + path)
+		require.NoError(t, err)
+		response.Body.Close()
+		require.Equal(t, http.StatusOK, response.StatusCode, path)
+
+		// The public listener does not serve them, not even to a logged-in user.
+		response = (<-sendRequest(t, server, "http://"+listener.Addr().String()+path, "alice")).response
+		require.Equal(t, http.StatusNotFound, response.StatusCode, path)
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestServeStreamsPastWriteTimeout(t *testing.T) {
+	account := randomAccount()
+	account.Currency = "USD"
+	slow := func() { time.Sleep(300 * time.Millisecond) }
+	var slowAccount atomic.Bool
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	// The client retries the GET that gets cut off, so it may be called again.
+	store.EXPECT().GetAccount(gomock.Any(), account.ID).MinTimes(2).
+		DoAndReturn(func(_ context.Context, _ int64) (db.Account, error) {
+			if slowAccount.Load() {
+				slow()
+			}
+			return account, nil
+		})
+	store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).AnyTimes().
+		Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+	store.EXPECT().SumEntriesSince(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+	gomock.InOrder(
+		store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ db.ListStatementEntriesParams) ([]db.ListStatementEntriesRow, error) {
+				slow()
+				return []db.ListStatementEntriesRow{{ID: 1, Amount: 500, Memo: "Salary"}}, nil
+			}),
+		store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).AnyTimes().
+			Return([]db.ListStatementEntriesRow{}, nil),
+	)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	baseURL, done := startServe(t, server, ServeConfig{WriteTimeout: 100 * time.Millisecond})
+	t.Cleanup(func() {
+		sendSIGTERM(t)
+		<-done
+	})
+
+	get := func(path string) (*http.Response, []byte, error) {
+		request, err := http.NewRequest(http.MethodGet, baseURL+path, nil)
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenGenerator, authTypeBearer, account.Owner, time.Minute)
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		return response, body, err
+	}
+
+	// The statement download outlasts the server's write timeout and still
+	// arrives whole.
+	response, body, err := get(fmt.Sprintf("/accounts/%d/statement?format=csv&from=2026-03-01&to=2026-03-31", account.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Contains(t, string(body), "Salary")
+	require.Contains(t, string(body), "Closing balance")
+
+	// Other routes keep the tight server-wide timeout.
+	slowAccount.Store(true)
+	_, _, err = get(fmt.Sprintf("/accounts/%d", account.ID))
+	require.Error(t, err)
+}
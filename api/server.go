@@ -1,64 +1,222 @@
 package api
 
 import (
+	"crypto/sha256"
+	"expvar"
 	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
+	"github.com/ulunnuha-h/simple_bank/db/migration"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/metrics"
+	"github.com/ulunnuha-h/simple_bank/ratelimit"
+	"github.com/ulunnuha-h/simple_bank/reconcile"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
 	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/tracing"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"github.com/ulunnuha-h/simple_bank/webhook"
 )
 
 type Server struct{
 	store db.Store
 	router *gin.Engine
+	internalRouter *gin.Engine
 	tokenGenerator token.Generator
+	reconciler *reconcile.Reconciler
+	snapshotJob *snapshot.Job
+	maxAccountsPerUser int64
+	rateLimitStore ratelimit.Store
+	rateLimits map[string]ratelimit.Limit
+	cors CORSConfig
+	cookies CookieConfig
+	csrfKey []byte
+	paymentRequestTTL time.Duration
+	eventPollInterval time.Duration
+	webhookURLs webhook.URLChecker
+	draining chan struct{}
+	drainOnce sync.Once
+	schemaVersion int64
 }
 
 func NewServer(store db.Store) (*Server, error){
-	tokenGenerator, err := token.NewPasetoGenerator(viper.GetString("SECRET_KEY"))
+	secretKey := viper.GetString("SECRET_KEY")
+	tokenGenerator, err := token.NewPasetoGenerator(secretKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token generator: %w", err)
 	}
 
+	paymentRequestTTL := viper.GetDuration("PAYMENT_REQUEST_TTL")
+	if paymentRequestTTL <= 0 {
+		paymentRequestTTL = defaultPaymentRequestTTL
+	}
+
+	schemaVersion, err := migration.LatestVersion()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read migrations: %w", err)
+	}
+
+	eventPollInterval := viper.GetDuration("EVENT_POLL_INTERVAL")
+	if eventPollInterval <= 0 {
+		eventPollInterval = defaultEventPollInterval
+	}
+
+	rateLimitStore, err := newRateLimitStore(store, viper.GetString("RATE_LIMIT_STORE"))
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimits, err := rateLimitsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cors, err := corsConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cookies, err := cookieConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	// CSRF tokens are keyed apart from the token generator, so one secret
+	// never signs two kinds of thing.
+	csrfKey := sha256.Sum256([]byte("csrf:" + secretKey))
+
 	server := &Server{
 		store: store,
 		tokenGenerator: tokenGenerator,
+		reconciler: reconcile.NewReconciler(store, viper.GetInt32("RECONCILIATION_CHUNK_SIZE")),
+		snapshotJob: snapshot.NewJob(store, viper.GetInt32("SNAPSHOT_CHUNK_SIZE")),
+		maxAccountsPerUser: viper.GetInt64("MAX_ACCOUNTS_PER_USER"),
+		rateLimitStore: rateLimitStore,
+		rateLimits: rateLimits,
+		cors: cors,
+		cookies: cookies,
+		csrfKey: csrfKey[:],
+		paymentRequestTTL: paymentRequestTTL,
+		eventPollInterval: eventPollInterval,
+		webhookURLs: webhook.URLChecker{AllowInsecure: viper.GetBool("WEBHOOK_ALLOW_INSECURE")},
+		draining: make(chan struct{}),
+		schemaVersion: schemaVersion,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", currencyValidator)
+		v.RegisterValidation("daycount", dayCountValidator)
+		v.RegisterValidation("compounding", compoundingValidator)
+		v.RegisterValidation("permission", permissionValidator)
+		v.RegisterValidation("webhookevent", webhookEventValidator)
+		v.RegisterTagNameFunc(jsonFieldName)
 	}
 
 	server.router = setupRouter(server)
+	server.internalRouter = setupInternalRouter()
+
+	// Per-IP rate limits are only as good as ClientIP, which believes
+	// X-Forwarded-For from these proxies alone.
+	if err := server.router.SetTrustedProxies(trustedProxiesFromEnv()); err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
 	return server, nil
 }
 
 func setupRouter(server *Server) *gin.Engine{
-	router := gin.Default()
+	logger := slog.Default()
+	router := gin.New()
+	// Handlers pass *gin.Context on as a context.Context; fall back to the
+	// request context so the trace span and log fields set there reach the
+	// store.
+	router.ContextWithFallback = true
+	router.Use(RequestIDMiddleware(), tracing.GinMiddleware(), RequestLogger(logger), recoveryLogger(logger), metrics.GinMiddleware())
+	router.Use(SecurityHeaders(), CORSMiddleware(server.cors))
+
+	router.POST("/users", server.rateLimit(signupRateLimit, rateLimitByIP), server.createUser)
+	router.POST("/users/login", server.rateLimit(loginRateLimit, rateLimitByIP), server.loginUser)
+	router.POST("/users/refresh", server.refreshToken)
 
-	router.POST("/users", server.createUser)
-	router.POST("/users/login", server.loginUser)
-	router.GET("/users/refresh", server.refreshToken)
+	router.GET("/openapi.json", server.getOpenAPI)
+	router.GET("/healthz", server.healthz)
+	router.GET("/readyz", server.readyz)
 
 	router.Use(AuthMiddleware(server.tokenGenerator))
 
+	router.PUT("/users/discoverable", server.updateDiscoverable)
+
 	router.POST("/accounts", server.createAccount)
+	router.GET("/accounts/lookup", server.lookupAccount)
 	router.GET("/accounts/:id", server.getAccount)
+	router.GET("/accounts/:id/balance", server.getAccountBalance)
+	router.GET("/accounts/:id/statement", withDeadlines(0, downloadWriteTimeout), server.getAccountStatement)
 	router.GET("/accounts", server.listAccount)
 	router.DELETE("/accounts/:id", server.deleteAccount)
-	router.PUT("/accounts/:id", server.updateAccount)
+	router.PUT("/accounts/:id", server.updateAccountLegacy)
+	router.PUT("/accounts/:id/nickname", server.updateAccountNickname)
+	router.POST("/accounts/:id/members", server.addAccountMember)
+	router.GET("/accounts/:id/members", server.listAccountMembers)
+	router.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+
+	router.POST("/payees", server.createPayee)
+	router.GET("/payees", server.listPayees)
+	router.DELETE("/payees/:id", server.deletePayee)
 
-	router.POST("/transfers", server.createTransfer)
+	router.POST("/payment-requests", server.createPaymentRequest)
+	router.GET("/payment-requests/incoming", server.listIncomingPaymentRequests)
+	router.GET("/payment-requests/outgoing", server.listOutgoingPaymentRequests)
+	router.GET("/payment-requests/:id", server.getPaymentRequest)
+	router.POST("/payment-requests/:id/accept", server.acceptPaymentRequest)
+	router.POST("/payment-requests/:id/decline", server.declinePaymentRequest)
+	router.POST("/payment-requests/:id/cancel", server.cancelPaymentRequest)
+
+	router.POST("/transfers", server.rateLimit(transferRateLimit, rateLimitByUsername), server.createTransfer)
+	router.GET("/transfers", server.listTransfers)
+	router.POST("/transfers/quote", server.quoteTransfer)
+
+	router.POST("/transfer-batches", withDeadlines(uploadReadTimeout, 0), server.rateLimit(transferRateLimit, rateLimitByUsername), server.createTransferBatch)
+	router.GET("/transfer-batches", server.listTransferBatches)
+	router.GET("/transfer-batches/:id", server.getTransferBatch)
+	router.POST("/transfer-batches/:id/approve", server.approveTransferBatch)
+	router.GET("/transfer-batches/:id/result", withDeadlines(0, downloadWriteTimeout), server.getTransferBatchResult)
+
+	router.POST("/webhooks", server.createWebhookSubscription)
+	router.GET("/webhooks", server.listWebhookSubscriptions)
+	router.DELETE("/webhooks/:id", server.deleteWebhookSubscription)
+	router.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	router.POST("/webhooks/:id/deliveries/:delivery_id/retry", server.retryWebhookDelivery)
+
+	router.GET("/interest-products", server.listInterestProducts)
+
+	router.GET("/events", withDeadlines(0, longPollWriteTimeout), RoleMiddleware(server.store, util.AdminRole, util.ServiceRole), server.listEvents)
+
+	adminRoutes := router.Group("/admin", AdminMiddleware(server.store))
+	adminRoutes.POST("/reconciliation/runs", server.runReconciliation)
+	adminRoutes.GET("/reconciliation/runs", server.listReconciliationRuns)
+	adminRoutes.GET("/reconciliation/runs/:id", server.getReconciliationRun)
+	adminRoutes.POST("/snapshots", server.runBalanceSnapshot)
+	adminRoutes.POST("/interest-products", server.createInterestProduct)
+	adminRoutes.PUT("/fee-schedules", server.upsertFeeSchedule)
+	adminRoutes.GET("/fee-schedules", server.listFeeSchedules)
+	adminRoutes.PUT("/accounts/:id/tier", server.updateAccountTier)
+	adminRoutes.PUT("/accounts/:id/frozen", server.setAccountFrozen)
+	adminRoutes.PUT("/accounts/:id/balance", server.updateAccount)
 	return router
 }
 
-func (server *Server) Start(address string) error{
-	return server.router.Run(address)
+// setupInternalRouter serves what operators need but clients must never see.
+// It listens on an address of its own and has no authentication. Application
+// metrics are on /metrics; /debug/vars only has the Go runtime's.
+func setupInternalRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	return router
 }
-
-func errorResponse(err error) gin.H{	
-	return gin.H{"error": err.Error()}
-}
\ No newline at end of file
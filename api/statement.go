@@ -0,0 +1,72 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ulunnuha-h/simple_bank/statement"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+type getStatementUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// From and To are inclusive calendar days in UTC.
+type getStatementQueryRequest struct {
+	Format string `form:"format" binding:"required,oneof=csv ofx camt053"`
+	From   string `form:"from" binding:"required,datetime=2006-01-02"`
+	To     string `form:"to" binding:"required,datetime=2006-01-02"`
+}
+
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var reqUri getStatementUriRequest
+	var reqQuery getStatementQueryRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&reqQuery); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	from, _ := time.Parse(time.DateOnly, reqQuery.From)
+	to, _ := time.Parse(time.DateOnly, reqQuery.To)
+	if to.Before(from) {
+		writeError(ctx, http.StatusBadRequest, errors.New("to must not be before from"))
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	account, ok := server.loadAccount(ctx, reqUri.ID, authPayload.Username, util.ViewPermission)
+	if !ok {
+		return
+	}
+
+	st, err := statement.New(ctx, server.store, account, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", statement.ContentType(reqQuery.Format))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, statement.Filename(reqQuery.Format, st)))
+	ctx.Status(http.StatusOK)
+
+	// Rows are already on their way to the client by the time a later chunk
+	// can fail, so the status can't change anymore; record the error and
+	// leave the document truncated.
+	if err := statement.NewWriter(server.store, 0).Write(ctx, ctx.Writer, reqQuery.Format, st); err != nil {
+		ctx.Error(err)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAccountStatementAPI(t *testing.T) {
+	account := randomAccount()
+	testUser, _ := randomUser()
+	account.Owner = testUser.Username
+	account.Balance = 5000
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		query        string
+		username     string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    "format=csv&from=2026-03-01&to=2026-03-31",
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(2).
+					Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+				store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{AccountID: account.ID, FromTime: from}).
+					Times(1).Return(int64(1000), nil)
+				store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{AccountID: account.ID, FromTime: to}).
+					Times(1).Return(int64(0), nil)
+				store.EXPECT().ListStatementEntries(gomock.Any(), db.ListStatementEntriesParams{
+					AccountID: account.ID, FromTime: from, ToTime: to, AfterID: 0, Limit: 500,
+				}).Times(1).Return([]db.ListStatementEntriesRow{
+					{ID: 3, Amount: 1000, CreatedAt: from.Add(time.Hour), Memo: "Salary"},
+				}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "20260301-20260331.csv")
+
+				body := recorder.Body.String()
+				require.Contains(t, body, "Opening balance,,40.00")
+				require.Contains(t, body, "Salary,10.00,50.00")
+				require.Contains(t, body, "Closing balance,,50.00")
+			},
+		},
+		{
+			name:     "Forbidden",
+			query:    "format=ofx&from=2026-03-01&to=2026-03-31",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), db.GetAccountMemberParams{AccountID: account.ID, Username: "someoneelse"}).
+					Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "NotFound",
+			query:    "format=camt053&from=2026-03-01&to=2026-03-31",
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:     "InvalidFormat",
+			query:    "format=pdf&from=2026-03-01&to=2026-03-31",
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:     "ToBeforeFrom",
+			query:    "format=csv&from=2026-03-31&to=2026-03-01",
+			username: testUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
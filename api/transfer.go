@@ -1,25 +1,68 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/fee"
+	"github.com/ulunnuha-h/simple_bank/util"
 )
 
+var (
+	errRecipient         = errors.New("exactly one of to_account_id, to_payee_id, to_account_number or to_handle is required")
+	errRecipientNotFound = errors.New("no discoverable user matches the handle")
+	errAccountFrozen     = errors.New("account is frozen")
+)
+
+// createTransferRequest names the recipient in one of four ways: the raw
+// account id, a saved payee, a public account number or the username or
+// email of a user who opted in to being discoverable.
 type createTransferRequest struct {
-	FromAccountId int64 `json:"from_account_id" binding:"required,min=1"`
-	ToAccountId   int64 `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64 `json:"amount" binding:"required,gt=0"`
-	Currency string `json:"currency" binding:"required,currency"`
+	FromAccountId   int64             `json:"from_account_id" binding:"required,min=1"`
+	ToAccountId     int64             `json:"to_account_id,omitempty" binding:"omitempty,min=1"`
+	ToPayeeId       int64             `json:"to_payee_id,omitempty" binding:"omitempty,min=1"`
+	ToAccountNumber string            `json:"to_account_number,omitempty"`
+	ToHandle        string            `json:"to_handle,omitempty"`
+	Amount          int64             `json:"amount" binding:"required,gt=0"`
+	Currency        string            `json:"currency" binding:"required,currency"`
+	Memo            string            `json:"memo,omitempty" binding:"max=140"`
+	Reference       string            `json:"reference,omitempty" binding:"omitempty,max=64,printascii"`
+	Metadata        map[string]string `json:"metadata,omitempty" binding:"omitempty,max=20,dive,keys,min=1,max=40,endkeys,max=500"`
+}
+
+// maxMetadataBytes caps the encoded size of transfer metadata, on top of the
+// per-key and per-value limits checked when binding the request.
+const maxMetadataBytes = 4096
+
+func validateMetadata(metadata map[string]string) error {
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	if len(encoded) > maxMetadataBytes {
+		return fmt.Errorf("metadata is %d bytes, the limit is %d", len(encoded), maxMetadataBytes)
+	}
+
+	return nil
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req createTransferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateMetadata(req.Metadata); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -28,51 +71,284 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validateAccount(ctx, req.FromAccountId, req.Currency, req.Amount, true, authPayload.Username) ||
-		!server.validateAccount(ctx, req.ToAccountId, req.Currency, 0, false, "") {
+	fromAccount, ok := server.validateAccount(ctx, req.FromAccountId, req.Currency, req.Amount, true, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	toAccountID, ok := server.resolveRecipient(ctx, req, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	if _, ok := server.validateAccount(ctx, toAccountID, req.Currency, 0, false, ""); !ok {
+		return
+	}
+
+	if _, ok := server.validateFee(ctx, fromAccount, req.Amount); !ok {
 		return
 	}
 
 	args := db.TransferTxParams{
 		FromAccountId: req.FromAccountId,
-		ToAccountId:   req.ToAccountId,
+		ToAccountId:   toAccountID,
 		Amount:        req.Amount,
+		Memo:          req.Memo,
+		Reference:     req.Reference,
+		Metadata:      req.Metadata,
 	}
 
 	result, err := server.store.TransferTx(ctx, args)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
-func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string, amount int64, checkBalance bool, username string) bool {
+// resolveRecipient returns the id of the account the transfer is addressed to.
+func (server *Server) resolveRecipient(ctx *gin.Context, req createTransferRequest, username string) (int64, bool) {
+	given := 0
+	for _, set := range []bool{req.ToAccountId != 0, req.ToPayeeId != 0, req.ToAccountNumber != "", req.ToHandle != ""} {
+		if set {
+			given++
+		}
+	}
+
+	if given != 1 {
+		writeError(ctx, http.StatusBadRequest, errRecipient)
+		return 0, false
+	}
+
+	switch {
+	case req.ToPayeeId != 0:
+		return server.payeeAccountID(ctx, req.ToPayeeId, username)
+	case req.ToAccountNumber != "":
+		holder, ok := server.findAccountHolder(ctx, username, req.ToAccountNumber)
+		return holder.ID, ok
+	case req.ToHandle != "":
+		return server.resolveHandle(ctx, username, req.ToHandle, req.Currency)
+	default:
+		return req.ToAccountId, true
+	}
+}
+
+// resolveHandle finds the account that receives currency for the user whose
+// username or email is handle. Users who have not opted in to being
+// discoverable look exactly like users who do not exist.
+func (server *Server) resolveHandle(ctx *gin.Context, username string, handle string, currency string) (int64, bool) {
+	if !server.allowLookup(ctx, username) {
+		return 0, false
+	}
+
+	var user db.User
+	var err error
+	if strings.Contains(handle, "@") {
+		user, err = server.store.GetUserByEmail(ctx, handle)
+	} else {
+		user, err = server.store.GetUser(ctx, handle)
+	}
+	if err != nil && err != sql.ErrNoRows {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	if err == sql.ErrNoRows || !user.Discoverable {
+		writeError(ctx, http.StatusNotFound, errRecipientNotFound)
+		return 0, false
+	}
+
+	account, err := server.store.GetReceivingAccount(ctx, db.GetReceivingAccountParams{
+		Owner:    user.Username,
+		Currency: currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, fmt.Errorf("recipient has no %s account", currency))
+			return 0, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	return account.ID, true
+}
+
+type quoteTransferRequest struct {
+	FromAccountId   int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountNumber string `json:"to_account_number"`
+	Amount          int64  `json:"amount" binding:"required,gt=0"`
+	Currency        string `json:"currency" binding:"required,currency"`
+}
+
+type quoteTransferResponse struct {
+	Amount     int64              `json:"amount"`
+	Currency   string             `json:"currency"`
+	Fee        fee.Breakdown      `json:"fee"`
+	TotalDebit int64              `json:"total_debit"`
+	Recipient  *recipientResponse `json:"recipient,omitempty"`
+}
+
+// quoteTransfer previews the fee of a transfer without moving any money.
+// Given an account number it also returns the masked name of the recipient
+// so the sender can confirm it before sending.
+func (server *Server) quoteTransfer(ctx *gin.Context) {
+	var req quoteTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	fromAccount, ok := server.validateAccount(ctx, req.FromAccountId, req.Currency, 0, true, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	var recipient *recipientResponse
+	if req.ToAccountNumber != "" {
+		holder, ok := server.findAccountHolder(ctx, authPayload.Username, req.ToAccountNumber)
+		if !ok {
+			return
+		}
+		rsp := newRecipientResponse(holder)
+		recipient = &rsp
+	}
+
+	breakdown, err := db.QuoteFee(ctx, server.store, fromAccount, req.Amount)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quoteTransferResponse{
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Fee:        breakdown,
+		TotalDebit: req.Amount + breakdown.Total,
+		Recipient:  recipient,
+	})
+}
+
+func (server *Server) validateAccount(ctx *gin.Context, accountID int64, currency string, amount int64, checkBalance bool, username string) (db.Account, bool) {
+	account, status, err := server.findCheckedAccount(ctx, accountID, currency, amount, checkBalance, username)
+	if err != nil {
+		writeError(ctx, status, err)
+		return account, false
+	}
+
+	return account, true
+}
+
+// findCheckedAccount is validateAccount without writing a response.
+func (server *Server) findCheckedAccount(ctx context.Context, accountID int64, currency string, amount int64, checkBalance bool, username string) (db.Account, int, error) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
-		status := http.StatusInternalServerError
 		if err == sql.ErrNoRows {
-			status = http.StatusNotFound
+			return account, http.StatusNotFound, err
+		}
+		return account, http.StatusInternalServerError, err
+	}
+
+	status, err := server.checkAccount(ctx, account, currency, amount, checkBalance, username)
+	return account, status, err
+}
+
+// checkAccount applies the rules of validateAccount to an account that has
+// already been loaded, without writing a response. On failure it returns the
+// HTTP status the error maps to.
+func (server *Server) checkAccount(ctx context.Context, account db.Account, currency string, amount int64, checkBalance bool, username string) (int, error) {
+	if checkBalance {
+		if status, err := server.checkPermission(ctx, account, username, util.TransferPermission); err != nil {
+			return status, err
 		}
-		ctx.JSON(status, errorResponse(err))
-		return false
 	}
 
-	if(account.Owner != username && checkBalance) {
-		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("user [%s] does not have access to account with ID %d", username, accountID)))
-		return false
+	if account.Frozen {
+		return http.StatusForbidden, fmt.Errorf("account [%d]: %w", account.ID, errAccountFrozen)
 	}
 
 	if account.Currency != currency {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("account [%d] currency mismatch: %s and %s", accountID, account.Currency, currency)))
-		return false
+		return http.StatusBadRequest, fmt.Errorf("account [%d]: %w: %s and %s", account.ID, errCurrencyMismatch, account.Currency, currency)
 	}
 
 	if checkBalance && account.Balance < amount {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("account [%d] has insufficient balance: required %d, available %d", accountID, amount, account.Balance)))
-		return false
+		return http.StatusBadRequest, fmt.Errorf("account [%d]: %w: required %d, available %d", account.ID, errInsufficientBalance, amount, account.Balance)
+	}
+
+	return http.StatusOK, nil
+}
+
+// validateFee checks that account can cover amount plus the fee charged on it.
+func (server *Server) validateFee(ctx *gin.Context, account db.Account, amount int64) (fee.Breakdown, bool) {
+	breakdown, status, err := server.checkFee(ctx, account, amount)
+	if err != nil {
+		writeError(ctx, status, err)
+		return breakdown, false
+	}
+
+	return breakdown, true
+}
+
+// checkFee is validateFee without writing a response.
+func (server *Server) checkFee(ctx context.Context, account db.Account, amount int64) (fee.Breakdown, int, error) {
+	breakdown, err := db.QuoteFee(ctx, server.store, account, amount)
+	if err != nil {
+		return breakdown, http.StatusInternalServerError, err
+	}
+
+	if account.Balance < amount+breakdown.Total {
+		return breakdown, http.StatusBadRequest, fmt.Errorf("account [%d]: %w: required %d including fee %d, available %d", account.ID, errInsufficientBalance, amount+breakdown.Total, breakdown.Total, account.Balance)
+	}
+
+	return breakdown, http.StatusOK, nil
+}
+
+type listTransfersRequest struct {
+	AccountID int64  `form:"account_id" binding:"required,min=1"`
+	Reference string `form:"reference" binding:"omitempty,max=64"`
+	PAGE_ID   int32  `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransfers returns the transfers into or out of an account, optionally
+// only those carrying the given client reference.
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	if _, ok := server.loadAccount(ctx, req.AccountID, authPayload.Username, util.ViewPermission); !ok {
+		return
+	}
+
+	args := db.ListTransfersParams{
+		FromAccountID: req.AccountID,
+		ToAccountID:   req.AccountID,
+		Limit:         req.PAGE_SIZE,
+		Offset:        (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	}
+	if req.Reference != "" {
+		args.Reference = &req.Reference
+	}
+
+	transfers, err := server.store.ListTransfers(ctx, args)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
 	}
 
-	return true
+	ctx.JSON(http.StatusOK, transfers)
 }
@@ -0,0 +1,520 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/ulunnuha-h/simple_bank/batch"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/token"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+const (
+	// maxBatchFileSize is the largest transfer batch file accepted for upload.
+	maxBatchFileSize = 2 << 20
+	// transferBatchRunLimit caps the batches RunApprovedTransferBatches takes
+	// on in one run.
+	transferBatchRunLimit = 10
+)
+
+var (
+	errBatchFormat      = errors.New("format must be csv or pain001, or the file name must end in .csv or .xml")
+	errBatchFileSize    = fmt.Errorf("file is larger than %d bytes", maxBatchFileSize)
+	errBatchNotPending  = errors.New("transfer batch has already been approved")
+	errBatchAccountGone = errors.New("account no longer exists")
+	errBatchInterrupted = errors.New("stopped by an internal error; rows not yet run were not paid")
+)
+
+type createTransferBatchRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv pain001"`
+}
+
+type transferBatchResponse struct {
+	Batch db.TransferBatch       `json:"batch"`
+	Items []db.TransferBatchItem `json:"items"`
+}
+
+// createTransferBatch parses an uploaded CSV or pain.001 file and stores it
+// as a pending batch. Every row is checked like a single transfer would be;
+// rows that fail are kept, marked invalid, and skipped on approval.
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req createTransferBatchRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if header.Size > maxBatchFileSize {
+		writeError(ctx, http.StatusRequestEntityTooLarge, errBatchFileSize)
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		var ok bool
+		if format, ok = batch.DetectFormat(header.Filename); !ok {
+			writeError(ctx, http.StatusBadRequest, errBatchFormat)
+			return
+		}
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	rows, err := parseBatchFile(format, io.LimitReader(file, maxBatchFileSize))
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	args := db.CreateTransferBatchTxParams{
+		Batch: db.CreateTransferBatchParams{
+			Owner:     authPayload.Username,
+			Format:    format,
+			Filename:  header.Filename,
+			ItemCount: int32(len(rows)),
+		},
+		Items: make([]db.CreateTransferBatchItemParams, 0, len(rows)),
+	}
+	for _, row := range rows {
+		item, err := server.validateBatchRow(ctx, row, authPayload.Username)
+		if err != nil {
+			writeError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if item.Status == util.TransferBatchItemValid {
+			args.Batch.ValidCount++
+		}
+		args.Items = append(args.Items, item)
+	}
+
+	result, err := server.store.CreateTransferBatchTx(ctx, args)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferBatchResponse{Batch: result.Batch, Items: result.Items})
+}
+
+func parseBatchFile(format string, r io.Reader) ([]batch.Row, error) {
+	if format == batch.FormatPain001 {
+		return batch.ParsePain001(r)
+	}
+	return batch.ParseCSV(r)
+}
+
+// validateBatchRow resolves the account numbers of row and applies the
+// checks createTransfer makes. Rule violations mark the returned item
+// invalid; only unexpected failures are returned as errors.
+func (server *Server) validateBatchRow(ctx context.Context, row batch.Row, username string) (db.CreateTransferBatchItemParams, error) {
+	item := db.CreateTransferBatchItemParams{
+		LineNo:      int32(row.Line),
+		FromAccount: row.FromAccount,
+		ToAccount:   row.ToAccount,
+		Amount:      row.Amount,
+		Currency:    row.Currency,
+		Reference:   row.Reference,
+		Memo:        row.Memo,
+		Status:      util.TransferBatchItemValid,
+	}
+	invalid := func(reason string) (db.CreateTransferBatchItemParams, error) {
+		item.Status = util.TransferBatchItemInvalid
+		item.Error = reason
+		return item, nil
+	}
+
+	if row.Error != "" {
+		return invalid(row.Error)
+	}
+
+	from, err := server.store.GetAccountByNumber(ctx, row.FromAccount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalid("from account not found")
+		}
+		return item, err
+	}
+	item.FromAccountID = &from.ID
+
+	to, err := server.store.GetAccountByNumber(ctx, row.ToAccount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return invalid("to account not found")
+		}
+		return item, err
+	}
+	item.ToAccountID = &to.ID
+
+	reason, err := server.checkBatchTransfer(ctx, from, to, row.Currency, row.Amount, username)
+	if err != nil {
+		return item, err
+	}
+	if reason != "" {
+		return invalid(reason)
+	}
+
+	return item, nil
+}
+
+// checkBatchTransfer runs the transfer checks for one row. It returns why the
+// transfer is not allowed, or an error if the checks themselves failed.
+func (server *Server) checkBatchTransfer(ctx context.Context, from, to db.Account, currency string, amount int64, username string) (string, error) {
+	if status, err := server.checkAccount(ctx, from, currency, amount, true, username); err != nil {
+		if status == http.StatusInternalServerError {
+			return "", err
+		}
+		return batchItemError(status, err), nil
+	}
+
+	if status, err := server.checkAccount(ctx, to, currency, 0, false, ""); err != nil {
+		if status == http.StatusInternalServerError {
+			return "", err
+		}
+		return batchItemError(status, err), nil
+	}
+
+	if _, status, err := server.checkFee(ctx, from, amount); err != nil {
+		if status == http.StatusInternalServerError {
+			return "", err
+		}
+		return batchItemError(status, err), nil
+	}
+
+	return "", nil
+}
+
+// batchItemError is the reason stored for a row that failed, worded the way
+// the API would report err to a client, so driver messages with constraint
+// names and SQL never end up in the batch or its result file.
+func batchItemError(status int, err error) string {
+	_, apiErr := newAPIError(status, err)
+	return apiErr.Message
+}
+
+// retryableBatchError reports whether err is worth running the batch again
+// for on the next run: contention the transaction gave up on, or a database
+// connection that was lost, refused or shut down.
+func retryableBatchError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return true
+		}
+		return pqDomainError(pqErr) == errTxContention
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.As(err, &netErr)
+}
+
+type listTransferBatchesRequest struct {
+	PAGE_ID   int32 `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listTransferBatches(ctx *gin.Context) {
+	var req listTransferBatchesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	batches, err := server.store.ListTransferBatches(ctx, db.ListTransferBatchesParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PAGE_SIZE,
+		Offset: (req.PAGE_ID - 1) * req.PAGE_SIZE,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, batches)
+}
+
+type transferBatchUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// loadTransferBatch fetches a batch uploaded by username.
+func (server *Server) loadTransferBatch(ctx *gin.Context, id int64, username string) (db.TransferBatch, bool) {
+	transferBatch, err := server.store.GetTransferBatch(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return transferBatch, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return transferBatch, false
+	}
+
+	if transferBatch.Owner != username {
+		writeError(ctx, http.StatusForbidden, token.ErrDoesNotBelong)
+		return transferBatch, false
+	}
+
+	return transferBatch, true
+}
+
+func (server *Server) getTransferBatch(ctx *gin.Context) {
+	var req transferBatchUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	transferBatch, ok := server.loadTransferBatch(ctx, req.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, transferBatch.ID)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferBatchResponse{Batch: transferBatch, Items: items})
+}
+
+// approveTransferBatch queues a pending batch for execution. The rows are
+// run in the background by RunApprovedTransferBatches, so a large batch
+// doesn't hold the request open; clients poll the batch for its outcome.
+func (server *Server) approveTransferBatch(ctx *gin.Context) {
+	var req transferBatchUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	if _, ok := server.loadTransferBatch(ctx, req.ID, authPayload.Username); !ok {
+		return
+	}
+
+	transferBatch, err := server.store.StartTransferBatch(ctx, db.StartTransferBatchParams{
+		ID:         req.ID,
+		ApprovedBy: &authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusConflict, errBatchNotPending)
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, transferBatch.ID)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, transferBatchResponse{Batch: transferBatch, Items: items})
+}
+
+// RunApprovedTransferBatches executes the batches waiting in processing,
+// oldest approval first. Rows are checked again first, since balances and
+// permissions may have changed since the upload; each row succeeds or fails
+// on its own. A batch cut short by a shutdown, contention or a lost database
+// connection is resumed on the next run, skipping rows already paid or
+// failed. Any other error marks the batch failed so it isn't retried forever.
+func (server *Server) RunApprovedTransferBatches(ctx context.Context) error {
+	batches, err := server.store.ListProcessingTransferBatches(ctx, transferBatchRunLimit)
+	if err != nil {
+		return err
+	}
+
+	for _, transferBatch := range batches {
+		err := server.runTransferBatch(ctx, transferBatch)
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if retryableBatchError(err) {
+			slog.WarnContext(ctx, "transfer batch interrupted, resuming on the next run",
+				slog.Int64("batch_id", transferBatch.ID), slog.Any("error", err))
+			continue
+		}
+
+		slog.ErrorContext(ctx, "transfer batch failed", slog.Int64("batch_id", transferBatch.ID), slog.Any("error", err))
+		if _, err := server.store.FailTransferBatch(ctx, db.FailTransferBatchParams{
+			ID:    transferBatch.ID,
+			Error: errBatchInterrupted.Error(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runTransferBatch executes the rows of one approved batch that are still
+// valid and completes it.
+func (server *Server) runTransferBatch(ctx context.Context, transferBatch db.TransferBatch) error {
+	approvedBy := transferBatch.Owner
+	if transferBatch.ApprovedBy != nil {
+		approvedBy = *transferBatch.ApprovedBy
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, transferBatch.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Status != util.TransferBatchItemValid {
+			continue
+		}
+		if err := server.executeBatchItem(ctx, transferBatch, item, approvedBy); err != nil {
+			return err
+		}
+	}
+
+	_, err = server.store.CompleteTransferBatch(ctx, transferBatch.ID)
+	return err
+}
+
+// executeBatchItem pays one row or records why it could not be paid.
+func (server *Server) executeBatchItem(ctx context.Context, transferBatch db.TransferBatch, item db.TransferBatchItem, username string) error {
+	fail := func(reason string) error {
+		_, err := server.store.MarkTransferBatchItemFailed(ctx, db.MarkTransferBatchItemFailedParams{
+			ID:    item.ID,
+			Error: reason,
+		})
+		return err
+	}
+
+	if item.FromAccountID == nil || item.ToAccountID == nil {
+		return fail(errBatchAccountGone.Error())
+	}
+
+	from, err := server.store.GetAccount(ctx, *item.FromAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fail(errBatchAccountGone.Error())
+		}
+		return err
+	}
+
+	to, err := server.store.GetAccount(ctx, *item.ToAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fail(errBatchAccountGone.Error())
+		}
+		return err
+	}
+
+	reason, err := server.checkBatchTransfer(ctx, from, to, item.Currency, item.Amount, username)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return fail(reason)
+	}
+
+	reference := item.Reference
+	if reference == "" {
+		reference = fmt.Sprintf("batch-%d-%d", transferBatch.ID, item.LineNo)
+	}
+
+	_, err = server.store.ExecuteTransferBatchItemTx(ctx, db.ExecuteTransferBatchItemTxParams{
+		ItemID: item.ID,
+		Transfer: db.TransferTxParams{
+			FromAccountId: from.ID,
+			ToAccountId:   to.ID,
+			Amount:        item.Amount,
+			Memo:          item.Memo,
+			Reference:     reference,
+		},
+	})
+	switch {
+	case err == nil, errors.Is(err, db.ErrBatchItemNotValid):
+		return nil
+	case retryableBatchError(err):
+		// The row stays valid and is paid when the batch is resumed.
+		return err
+	default:
+		return fail(batchItemError(http.StatusInternalServerError, err))
+	}
+}
+
+// getTransferBatchResult downloads the outcome of every row as CSV.
+func (server *Server) getTransferBatchResult(ctx *gin.Context) {
+	var req transferBatchUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	transferBatch, ok := server.loadTransferBatch(ctx, req.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, transferBatch.ID)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="transfer-batch-%d-result.csv"`, transferBatch.ID))
+	ctx.Status(http.StatusOK)
+
+	if err := batch.WriteResult(ctx.Writer, items); err != nil {
+		ctx.Error(err)
+	}
+}
@@ -0,0 +1,470 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func newBatchUploadRequest(t *testing.T, filename string, content string, format string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = part.Write([]byte(content))
+	require.NoError(t, err)
+	if format != "" {
+		require.NoError(t, writer.WriteField("format", format))
+	}
+	require.NoError(t, writer.Close())
+
+	request, err := http.NewRequest(http.MethodPost, "/transfer-batches", &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	return request
+}
+
+func TestCreateTransferBatchAPI(t *testing.T) {
+	user, _ := randomUser()
+	from := db.Account{ID: 1, Owner: user.Username, Balance: 10000, Currency: "USD", AccountNumber: "123456789015"}
+	to := db.Account{ID: 2, Owner: "payee", Balance: 0, Currency: "USD", AccountNumber: "000000000018"}
+
+	file := "from_account,to_account,amount,currency,reference\n" +
+		"123456789015,000000000018,12.50,USD,SAL-1\n" +
+		"123456789015,999999999994,1,USD,SAL-2\n" +
+		"123456789015,000000000018,nope,USD,SAL-3\n"
+
+	testCases := []struct {
+		name         string
+		filename     string
+		content      string
+		format       string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			filename: "payouts.csv",
+			content:  file,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), from.AccountNumber).Times(2).Return(from, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), to.AccountNumber).Times(1).Return(to, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), "999999999994").Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.CreateTransferBatchTxParams) (db.TransferBatchTxResult, error) {
+						require.Equal(t, user.Username, args.Batch.Owner)
+						require.Equal(t, "csv", args.Batch.Format)
+						require.Equal(t, int32(3), args.Batch.ItemCount)
+						require.Equal(t, int32(1), args.Batch.ValidCount)
+						require.Len(t, args.Items, 3)
+
+						require.Equal(t, util.TransferBatchItemValid, args.Items[0].Status)
+						require.Equal(t, int64(1250), args.Items[0].Amount)
+						require.Equal(t, to.ID, *args.Items[0].ToAccountID)
+						require.Equal(t, "to account not found", args.Items[1].Error)
+						require.Equal(t, util.TransferBatchItemInvalid, args.Items[2].Status)
+						require.Equal(t, int32(4), args.Items[2].LineNo)
+
+						return db.TransferBatchTxResult{Batch: db.TransferBatch{ID: 5, Owner: user.Username}}, nil
+					})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotPermitted",
+			filename: "payouts.csv",
+			content:  "from_account,to_account,amount,currency\n000000000018,123456789015,1,USD\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), to.AccountNumber).Times(1).Return(to, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), from.AccountNumber).Times(1).Return(from, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.CreateTransferBatchTxParams) (db.TransferBatchTxResult, error) {
+						require.Equal(t, int32(0), args.Batch.ValidCount)
+						require.Equal(t, util.TransferBatchItemInvalid, args.Items[0].Status)
+						return db.TransferBatchTxResult{}, nil
+					})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ExplicitFormat",
+			filename: "payouts.txt",
+			content:  "from_account,to_account,amount,currency\n123456789015,000000000018,1,EUR\n",
+			format:   "csv",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountByNumber(gomock.Any(), from.AccountNumber).Times(1).Return(from, nil)
+				store.EXPECT().GetAccountByNumber(gomock.Any(), to.AccountNumber).Times(1).Return(to, nil)
+
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.CreateTransferBatchTxParams) (db.TransferBatchTxResult, error) {
+						require.Contains(t, args.Items[0].Error, "currency mismatch")
+						return db.TransferBatchTxResult{}, nil
+					})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnknownFormat",
+			filename: "payouts.xlsx",
+			content:  file,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "MalformedFile",
+			filename: "payouts.xml",
+			content:  "<Document>",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request := newBatchUploadRequest(t, tc.filename, tc.content, tc.format)
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransferBatchAPI(t *testing.T) {
+	user, _ := randomUser()
+	from := db.Account{ID: 1, Owner: user.Username, Balance: 10000, Currency: "USD"}
+	to := db.Account{ID: 2, Owner: "payee", Currency: "USD"}
+	transferBatch := db.TransferBatch{ID: 5, Owner: user.Username, Status: util.TransferBatchPending}
+
+	items := []db.TransferBatchItem{
+		{ID: 11, BatchID: 5, LineNo: 2, FromAccountID: &from.ID, ToAccountID: &to.ID, Amount: 1250, Currency: "USD", Status: util.TransferBatchItemValid},
+		{ID: 12, BatchID: 5, LineNo: 3, FromAccountID: &from.ID, ToAccountID: &to.ID, Amount: 20000, Currency: "USD", Status: util.TransferBatchItemValid},
+		{ID: 13, BatchID: 5, LineNo: 4, Status: util.TransferBatchItemInvalid, Error: "to account not found"},
+	}
+
+	testCases := []struct {
+		name         string
+		username     string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+				store.EXPECT().StartTransferBatch(gomock.Any(), db.StartTransferBatchParams{ID: transferBatch.ID, ApprovedBy: &user.Username}).
+					Times(1).Return(db.TransferBatch{ID: 5, Owner: user.Username, Status: util.TransferBatchProcessing}, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return(items, nil)
+
+				// The rows are run later by RunApprovedTransferBatches.
+				store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CompleteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var response transferBatchResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, util.TransferBatchProcessing, response.Batch.Status)
+				require.Len(t, response.Items, 3)
+			},
+		},
+		{
+			name:     "AlreadyApproved",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+				store.EXPECT().StartTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+				store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, CodeBatchNotPending)
+			},
+		},
+		{
+			name:     "Forbidden",
+			username: "someoneelse",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+				store.EXPECT().StartTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfer-batches/%d/approve", transferBatch.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestRunApprovedTransferBatches(t *testing.T) {
+	user, _ := randomUser()
+	from := db.Account{ID: 1, Owner: user.Username, Balance: 10000, Currency: "USD"}
+	to := db.Account{ID: 2, Owner: "payee", Currency: "USD"}
+	transferBatch := db.TransferBatch{ID: 5, Owner: user.Username, Status: util.TransferBatchProcessing, ApprovedBy: &user.Username}
+
+	items := []db.TransferBatchItem{
+		{ID: 11, BatchID: 5, LineNo: 2, FromAccountID: &from.ID, ToAccountID: &to.ID, Amount: 1250, Currency: "USD", Status: util.TransferBatchItemValid},
+		{ID: 12, BatchID: 5, LineNo: 3, FromAccountID: &from.ID, ToAccountID: &to.ID, Amount: 20000, Currency: "USD", Status: util.TransferBatchItemValid},
+		{ID: 13, BatchID: 5, LineNo: 4, Status: util.TransferBatchItemInvalid, Error: "to account not found"},
+		{ID: 14, BatchID: 5, LineNo: 5, FromAccountID: &from.ID, ToAccountID: &to.ID, Amount: 100, Currency: "USD", Status: util.TransferBatchItemSucceeded},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			// Row 5 was paid before a restart and is not paid again.
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return(items, nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(2).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(2).Return(to, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+
+				store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), db.ExecuteTransferBatchItemTxParams{
+					ItemID: 11,
+					Transfer: db.TransferTxParams{
+						FromAccountId: from.ID,
+						ToAccountId:   to.ID,
+						Amount:        1250,
+						Reference:     "batch-5-2",
+					},
+				}).Times(1).Return(db.ExecuteTransferBatchItemTxResult{}, nil)
+				store.EXPECT().MarkTransferBatchItemFailed(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.MarkTransferBatchItemFailedParams) (db.TransferBatchItem, error) {
+						require.Equal(t, int64(12), args.ID)
+						require.Contains(t, args.Error, "insufficient balance")
+						return db.TransferBatchItem{}, nil
+					})
+
+				store.EXPECT().CompleteTransferBatch(gomock.Any(), transferBatch.ID).Times(1).
+					Return(db.TransferBatch{ID: 5, Status: util.TransferBatchCompleted, SucceededCount: 2, FailedCount: 1}, nil)
+				store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "HardError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return(items, nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CompleteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+				store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.FailTransferBatchParams) (db.TransferBatch, error) {
+						require.Equal(t, transferBatch.ID, args.ID)
+						require.Equal(t, errBatchInterrupted.Error(), args.Error)
+						require.NotContains(t, args.Error, sql.ErrConnDone.Error())
+						return db.TransferBatch{ID: 5, Status: util.TransferBatchFailed}, nil
+					})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			// Contention the transaction gave up on leaves the row valid and
+			// the batch processing, to be resumed on the next run.
+			name: "Contention",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return(items[:1], nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(to, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ExecuteTransferBatchItemTxResult{}, fmt.Errorf("tx failed after 5 attempts: %w", &pq.Error{Code: "40001"}))
+
+				store.EXPECT().MarkTransferBatchItemFailed(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CompleteTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			// Driver errors are stored the way the API would report them.
+			name: "DriverError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+				store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return(items[:1], nil)
+				store.EXPECT().GetAccount(gomock.Any(), from.ID).Times(1).Return(from, nil)
+				store.EXPECT().GetAccount(gomock.Any(), to.ID).Times(1).Return(to, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ExecuteTransferBatchItemTxResult{}, &pq.Error{
+						Code:       "23514",
+						Message:    `new row for relation "accounts" violates check constraint "accounts_balance_check"`,
+						Constraint: "accounts_balance_check",
+					})
+				store.EXPECT().MarkTransferBatchItemFailed(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.MarkTransferBatchItemFailedParams) (db.TransferBatchItem, error) {
+						require.Equal(t, int64(11), args.ID)
+						require.Equal(t, errConstraint.Error(), args.Error)
+						return db.TransferBatchItem{}, nil
+					})
+
+				store.EXPECT().CompleteTransferBatch(gomock.Any(), transferBatch.ID).Times(1).
+					Return(db.TransferBatch{ID: 5, Status: util.TransferBatchCompleted, FailedCount: 1}, nil)
+				store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "ListError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+				store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+
+			tc.checkError(t, server.RunApprovedTransferBatches(context.Background()))
+		})
+	}
+}
+
+func TestRunApprovedTransferBatchesShutdown(t *testing.T) {
+	transferBatch := db.TransferBatch{ID: 5, Owner: "alice", Status: util.TransferBatchProcessing}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListProcessingTransferBatches(gomock.Any(), gomock.Any()).Times(1).Return([]db.TransferBatch{transferBatch}, nil)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).
+		DoAndReturn(func(context.Context, int64) ([]db.TransferBatchItem, error) {
+			cancel()
+			return nil, context.Canceled
+		})
+
+	// A batch interrupted by a shutdown stays processing and is resumed.
+	store.EXPECT().FailTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	require.ErrorIs(t, server.RunApprovedTransferBatches(ctx), context.Canceled)
+}
+
+func TestGetTransferBatchResultAPI(t *testing.T) {
+	user, _ := randomUser()
+	transferID := int64(77)
+	transferBatch := db.TransferBatch{ID: 5, Owner: user.Username, Status: util.TransferBatchCompleted}
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferBatch(gomock.Any(), transferBatch.ID).Times(1).Return(transferBatch, nil)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), transferBatch.ID).Times(1).Return([]db.TransferBatchItem{
+		{LineNo: 2, FromAccount: "123456789015", ToAccount: "000000000018", Amount: 1250, Currency: "USD",
+			Status: util.TransferBatchItemSucceeded, TransferID: &transferID},
+	}, nil)
+
+	server, err := NewServer(store)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/transfer-batches/5/result", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenGenerator, authTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Contains(t, recorder.Body.String(), "2,123456789015,000000000018,12.50,USD,,,succeeded,77,\n")
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
@@ -32,16 +35,19 @@ func TestCreateTransferAPI(t *testing.T){
 			FromAccountID: fromAccount.ID,
 			ToAccountID: toAccount.ID,
 			Amount: transferAmount,
+			Metadata: json.RawMessage("{}"),
 		},
 		FromAccount: fromAccount,
 		ToAccount: toAccount,
 		FromEntry: db.Entry{
 			AccountID: fromAccount.ID,
 			Amount: -transferAmount,
+			Metadata: json.RawMessage("{}"),
 		},
 		ToEntry: db.Entry{
 			AccountID: toAccount.ID,
 			Amount: transferAmount,
+			Metadata: json.RawMessage("{}"),
 		},
 	}
 
@@ -76,6 +82,11 @@ func TestCreateTransferAPI(t *testing.T){
 					Times(1).
 					Return(toAccount, nil)
 
+				store.EXPECT().
+					GetFeeSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FeeSchedule{}, sql.ErrNoRows)
+
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
@@ -111,6 +122,11 @@ func TestCreateTransferAPI(t *testing.T){
 					Times(1).
 					Return(toAccount, nil)
 
+				store.EXPECT().
+					GetFeeSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FeeSchedule{}, sql.ErrNoRows)
+
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Eq(args)).
 					Times(1).
@@ -119,6 +135,7 @@ func TestCreateTransferAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -131,6 +148,7 @@ func TestCreateTransferAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 		{
@@ -150,6 +168,7 @@ func TestCreateTransferAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeCurrencyMismatch)
 			},
 		},
 		{
@@ -168,6 +187,71 @@ func TestCreateTransferAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInsufficientFunds)
+			},
+		},
+		{
+			name: "InsufficientBalanceForFee",
+			requestBody: createTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId: toAccount.ID,
+				Amount: fromAccount.Balance,
+				Currency: "IDR",
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+					Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+					Times(1).
+					Return(toAccount, nil)
+
+				store.EXPECT().
+					GetFeeSchedule(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FeeSchedule{Currency: "IDR", FlatFee: 1}, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInsufficientFunds)
+			},
+		},
+		{
+			name: "FrozenRecipient",
+			requestBody: createTransferRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId: toAccount.ID,
+				Amount: 1,
+				Currency: "IDR",
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				frozen := toAccount
+				frozen.Frozen = true
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+					Times(1).
+					Return(fromAccount, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+					Times(1).
+					Return(frozen, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeAccountFrozen)
 			},
 		},
 		{
@@ -186,6 +270,7 @@ func TestCreateTransferAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
 			},
 		},
 	}
@@ -225,4 +310,194 @@ func requireBodyMatchTransfer(t *testing.T, body *bytes.Buffer, account db.Trans
 	err = json.Unmarshal(data, &gotResult)
 	require.NoError(t, err)
 	require.Equal(t, account, gotResult)
-}
\ No newline at end of file
+}
+func TestTransferDetailsAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	fromAccount := randomAccount()
+	fromAccount.Owner = testUser.Username
+	fromAccount.Currency = "USD"
+	fromAccount.Balance = 100
+	toAccount := randomAccount()
+	toAccount.Currency = "USD"
+
+	tooManyKeys := gin.H{}
+	for i := 0; i < 21; i++ {
+		tooManyKeys[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	testCases := []struct {
+		name         string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+				"memo":            "March rent",
+				"reference":       "INV-2026-03",
+				"metadata":        gin.H{"invoice": "2026-03"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), fromAccount.ID).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), toAccount.ID).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetFeeSchedule(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeSchedule{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), db.TransferTxParams{
+					FromAccountId: fromAccount.ID,
+					ToAccountId:   toAccount.ID,
+					Amount:        10,
+					Memo:          "March rent",
+					Reference:     "INV-2026-03",
+					Metadata:      map[string]string{"invoice": "2026-03"},
+				}).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MemoTooLong",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+				"memo":            strings.Repeat("a", 141),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "TooManyMetadataKeys",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+				"metadata":        tooManyKeys,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name: "MetadataValueTooLong",
+			body: gin.H{
+				"from_account_id": fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          10,
+				"currency":        "USD",
+				"metadata":        gin.H{"note": strings.Repeat("a", 501)},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	testUser, _ := randomUser()
+	account := randomAccount()
+	account.Owner = testUser.Username
+
+	testCases := []struct {
+		name         string
+		query        string
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "ByReference",
+			query: fmt.Sprintf("account_id=%d&reference=INV-7&page_id=1&page_size=5", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				reference := "INV-7"
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(account, nil)
+				store.EXPECT().ListTransfers(gomock.Any(), db.ListTransfersParams{
+					FromAccountID: account.ID,
+					ToAccountID:   account.ID,
+					Reference:     &reference,
+					Limit:         5,
+				}).Times(1).Return([]db.Transfer{{ID: 1, Reference: reference}}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NotMember",
+			query: fmt.Sprintf("account_id=%d&page_id=1&page_size=5", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				other := account
+				other.Owner = "someoneelse"
+				store.EXPECT().GetAccount(gomock.Any(), account.ID).Times(1).Return(other, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
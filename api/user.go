@@ -1,16 +1,21 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/metrics"
+	"github.com/ulunnuha-h/simple_bank/token"
 	"github.com/ulunnuha-h/simple_bank/util"
 )
 
+var errSessionExpired = errors.New("session is blocked or expired")
+
 type createUserRequest struct {
 	Username string `json:"username" binding:"required,alphanum"`
 	Password string `json:"password" binding:"required,min=6"`
@@ -22,6 +27,7 @@ type UserReponse struct{
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Discoverable      bool      `json:"discoverable"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -31,6 +37,7 @@ func newUserReponse(user db.User) UserReponse {
 		Username: user.Username,
 		FullName: user.FullName,
 		Email: user.Email,
+		Discoverable: user.Discoverable,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt: user.CreatedAt,
 	}
@@ -39,40 +46,40 @@ func newUserReponse(user db.User) UserReponse {
 func (server *Server) createUser(ctx *gin.Context){
 	var	req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil{
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	hashedPassword, err := util.HashPassword(req.Password)
+	user, status, err := server.registerUser(ctx, req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, status, err)
 		return
 	}
 
-	args := db.CreateUserParams{
+	createUserReponse := newUserReponse(user)
+
+	ctx.JSON(http.StatusOK, createUserReponse)
+}
+
+// registerUser creates the user described by req, which must already be
+// validated. On failure it returns the HTTP status the error maps to.
+func (server *Server) registerUser(ctx context.Context, req createUserRequest) (db.User, int, error) {
+	hashedPassword, err := util.HashPassword(req.Password)
+	if err != nil {
+		return db.User{}, http.StatusInternalServerError, err
+	}
+
+	user, err := server.store.CreateUser(ctx, db.CreateUserParams{
 		Username: req.Username,
 		HashedPassword: hashedPassword,
 		FullName: req.FullName,
 		Email: req.Email,
-	}
-
-	user, err := server.store.CreateUser(ctx, args)
+	})
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return	
-			}
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		return user, http.StatusInternalServerError, err
 	}
 
-	createUserReponse := newUserReponse(user)
-
-	ctx.JSON(http.StatusOK, createUserReponse)
+	return user, http.StatusOK, nil
 }
 
 type loginUserRequest struct {
@@ -82,63 +89,102 @@ type loginUserRequest struct {
 
 type loginUserReponse struct {
 	AccessToken string `json:"access_token"`
+	// CSRFToken must be sent in the X-CSRF-Token header to refresh. It is
+	// also set as the csrf_token cookie, which pages on another origin
+	// cannot read.
+	CSRFToken string `json:"csrf_token"`
 	LoggedUser UserReponse `json:"logged_user"`
 }
 
 func (server *Server) loginUser(ctx *gin.Context){
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil{
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	user, err := server.store.GetUser(ctx, req.Username)
+	user, status, err := server.authenticate(ctx, req.Username, req.Password)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, status, err)
 		return
 	}
 
-	err = util.CheckPassword(req.Password, user.HashedPassword)
+	login, err := server.startSession(ctx, user.Username, ctx.Request.UserAgent(), ctx.ClientIP())
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	_, accessToken, err := server.tokenGenerator.CreateToken(req.Username, 10 * time.Minute)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
+	csrfToken := server.csrfToken(login.refreshPayload.ID)
+	server.setSessionCookies(ctx, login.refreshToken, csrfToken, refreshTokenDuration)
 
-	refreshPayload, refreshToken, err := server.tokenGenerator.CreateToken(req.Username, time.Hour)
+	ctx.JSON(http.StatusOK, loginUserReponse{
+		AccessToken: login.accessToken,
+		CSRFToken: csrfToken,
+		LoggedUser: newUserReponse(user),
+	})
+}
+
+// authenticate loads username and checks password against it. On failure it
+// returns the HTTP status the error maps to.
+func (server *Server) authenticate(ctx context.Context, username string, password string) (db.User, int, error) {
+	user, err := server.store.GetUser(ctx, username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		if err == sql.ErrNoRows {
+			metrics.LoginFailed(metrics.LoginUnknownUser)
+			return user, http.StatusNotFound, err
+		}
+		metrics.LoginFailed(metrics.LoginError)
+		return user, http.StatusInternalServerError, err
 	}
 
-	args := db.CreateSessionParams{
-		ID: string(refreshPayload.ID.String()),
-		Username: req.Username,
-		RefreshToken: refreshToken,
-		UserAgent: ctx.Request.UserAgent(),
-		ClientIp: ctx.ClientIP(),
-		IsBlocked: false,
-		ExpiredAt: time.Now().Add(time.Hour),
+	if err := util.CheckPassword(password, user.HashedPassword); err != nil {
+		metrics.LoginFailed(metrics.LoginWrongPassword)
+		return user, http.StatusUnauthorized, err
 	}
 
-	server.store.CreateSession(ctx, args)
+	return user, http.StatusOK, nil
+}
 
-	ctx.SetCookie("refresh_token",refreshToken, int(time.Hour), "/", "localhost", false, true)
+const (
+	accessTokenDuration  = 10 * time.Minute
+	refreshTokenDuration = time.Hour
+)
 
-	ctx.JSON(http.StatusOK, loginUserReponse{
-		AccessToken: accessToken,
-		LoggedUser: newUserReponse(user),
+type loginSession struct {
+	session        db.Session
+	accessToken    string
+	accessPayload  *token.Payload
+	refreshToken   string
+	refreshPayload *token.Payload
+}
+
+// startSession issues an access and a refresh token to username and records
+// the refresh token as a new session.
+func (server *Server) startSession(ctx context.Context, username string, userAgent string, clientIP string) (loginSession, error) {
+	var login loginSession
+	var err error
+
+	login.accessPayload, login.accessToken, err = server.tokenGenerator.CreateToken(username, accessTokenDuration)
+	if err != nil {
+		return login, err
+	}
+
+	login.refreshPayload, login.refreshToken, err = server.tokenGenerator.CreateToken(username, refreshTokenDuration)
+	if err != nil {
+		return login, err
+	}
+
+	login.session, err = server.store.CreateSessionTx(ctx, db.CreateSessionParams{
+		ID: login.refreshPayload.ID.String(),
+		Username: username,
+		RefreshToken: login.refreshToken,
+		UserAgent: userAgent,
+		ClientIp: clientIP,
+		IsBlocked: false,
+		ExpiredAt: time.Now().Add(refreshTokenDuration),
 	})
+	return login, err
 }
 
 type RefreshTokenResponse struct{
@@ -147,37 +193,94 @@ type RefreshTokenResponse struct{
 }
 
 func (server *Server) refreshToken(ctx *gin.Context){
-	token, err := ctx.Cookie("refresh_token")
+	cookie, err := ctx.Cookie(refreshCookieName)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, token.ErrAuthNotProvided)
 		return	
 	}
 
-	payload, err := server.tokenGenerator.Verify(token)
+	if err := server.checkCSRF(ctx, cookie); err != nil {
+		writeError(ctx, http.StatusForbidden, err)
+		return
+	}
+
+	session, accessToken, _, status, err := server.renewAccessToken(ctx, cookie)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, status, err)
 		return
 	}
 
+	ctx.JSON(http.StatusOK, RefreshTokenResponse{
+		Username: session.Username,
+		AccessToken: accessToken,
+	})
+}
+
+// renewAccessToken issues a new access token for the session that
+// refreshToken belongs to. On failure it returns the HTTP status the error
+// maps to.
+func (server *Server) renewAccessToken(ctx context.Context, refreshToken string) (db.Session, string, *token.Payload, int, error) {
+	session, accessToken, accessPayload, status, err := server.issueAccessToken(ctx, refreshToken)
+	if err != nil {
+		metrics.TokenRefreshed(metrics.OutcomeError)
+	} else {
+		metrics.TokenRefreshed(metrics.OutcomeSuccess)
+	}
+	return session, accessToken, accessPayload, status, err
+}
+
+func (server *Server) issueAccessToken(ctx context.Context, refreshToken string) (db.Session, string, *token.Payload, int, error) {
+	payload, err := server.tokenGenerator.Verify(refreshToken)
+	if err != nil {
+		return db.Session{}, "", nil, http.StatusUnauthorized, err
+	}
+
 	session, err := server.store.GetSession(ctx, payload.ID.String())
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
+		if err == sql.ErrNoRows {
+			return session, "", nil, http.StatusUnauthorized, errSessionExpired
+		}
+		return session, "", nil, http.StatusInternalServerError, err
 	}
 
 	if session.IsBlocked || time.Now().After(session.ExpiredAt) {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return session, "", nil, http.StatusUnauthorized, errSessionExpired
+	}
+
+	accessPayload, accessToken, err := server.tokenGenerator.CreateToken(session.Username, accessTokenDuration)
+	if err != nil {
+		return session, "", nil, http.StatusInternalServerError, err
+	}
+
+	return session, accessToken, accessPayload, http.StatusOK, nil
+}
+
+type updateDiscoverableRequest struct {
+	Discoverable *bool `json:"discoverable" binding:"required"`
+}
+
+// updateDiscoverable lets the logged user opt in to, or out of, receiving
+// transfers addressed to their username or email.
+func (server *Server) updateDiscoverable(ctx *gin.Context){
+	var req updateDiscoverableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil{
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
-	_, accessToken, err := server.tokenGenerator.CreateToken(session.Username, 10 * time.Minute)
+	authPayload, err := GetAuthPayload(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, RefreshTokenResponse{
-		Username: session.Username,
-		AccessToken: accessToken,
+	user, err := server.store.UpdateUserDiscoverable(ctx, db.UpdateUserDiscoverableParams{
+		Username: authPayload.Username,
+		Discoverable: *req.Discoverable,
 	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserReponse(user))
 }
\ No newline at end of file
@@ -10,7 +10,10 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
@@ -101,6 +104,7 @@ func TestCreateUserAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 		{
@@ -125,7 +129,8 @@ func TestCreateUserAPI(t *testing.T){
 					Return(db.User{}, &pq.Error{Code: "23505"})
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorCode(t, recorder, CodeAlreadyExists)
 			},
 		},
 		{
@@ -143,6 +148,7 @@ func TestCreateUserAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
 			},
 		},
 		{
@@ -160,6 +166,7 @@ func TestCreateUserAPI(t *testing.T){
 			},
 			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireErrorCode(t, recorder, CodeInternal)
 			},
 		},
 	}
@@ -206,4 +213,224 @@ func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User){
 	err = json.Unmarshal(data, &gotUser)
 	require.NoError(t, err)
 	require.Equal(t, user, gotUser)
-}
\ No newline at end of file
+}
+func TestUpdateDiscoverableAPI(t *testing.T){
+	testUser, _ := randomUser()
+
+	testCases := []struct{
+		name string
+		body gin.H
+		buildStubs func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OptIn",
+			body: gin.H{"discoverable": true},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					UpdateUserDiscoverable(gomock.Any(), db.UpdateUserDiscoverableParams{Username: testUser.Username, Discoverable: true}).
+					Times(1).
+					Return(db.User{Username: testUser.Username, Discoverable: true}, nil)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got UserReponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.True(t, got.Discoverable)
+			},
+		},
+		{
+			name: "OptOut",
+			body: gin.H{"discoverable": false},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					UpdateUserDiscoverable(gomock.Any(), db.UpdateUserDiscoverableParams{Username: testUser.Username, Discoverable: false}).
+					Times(1).
+					Return(db.User{Username: testUser.Username}, nil)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Missing",
+			body: gin.H{},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					UpdateUserDiscoverable(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+	}
+
+	for i := range testCases{
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			jsonData, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/discoverable", bytes.NewBuffer(jsonData))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, testUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
+
+// addRefreshCookies logs username in on request the way a browser would:
+// with the refresh and CSRF cookies, and the CSRF token in its header.
+func addRefreshCookies(t *testing.T, server *Server, request *http.Request, username string) {
+	payload, refreshToken, err := server.tokenGenerator.CreateToken(username, time.Minute)
+	require.NoError(t, err)
+
+	csrfToken := server.csrfToken(payload.ID)
+	request.AddCookie(&http.Cookie{Name: refreshCookieName, Value: refreshToken})
+	request.AddCookie(&http.Cookie{Name: csrfCookieName, Value: csrfToken})
+	request.Header.Set(csrfHeader, csrfToken)
+}
+
+func TestRefreshTokenAPI(t *testing.T){
+	testUser, _ := randomUser()
+
+	testCases := []struct{
+		name string
+		setupRequest func(t *testing.T, server *Server, request *http.Request)
+		buildStubs func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {
+				addRefreshCookies(t, server, request, testUser.Username)
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{Username: testUser.Username, ExpiredAt: time.Now().Add(time.Hour)}, nil)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoCookie",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder, CodeUnauthenticated)
+			},
+		},
+		{
+			name: "UnknownSession",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {
+				addRefreshCookies(t, server, request, testUser.Username)
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorCode(t, recorder, CodeSessionExpired)
+			},
+		},
+		{
+			name: "NoCSRFHeader",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {
+				addRefreshCookies(t, server, request, testUser.Username)
+				request.Header.Del(csrfHeader)
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeCSRFInvalid)
+			},
+		},
+		{
+			name: "CSRFHeaderDiffersFromCookie",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {
+				addRefreshCookies(t, server, request, testUser.Username)
+				request.Header.Set(csrfHeader, server.csrfToken(uuid.New()))
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeCSRFInvalid)
+			},
+		},
+		{
+			// A site able to plant cookies still can't forge a token for
+			// the victim's session.
+			name: "CSRFTokenOfAnotherSession",
+			setupRequest: func (t *testing.T, server *Server, request *http.Request)  {
+				_, refreshToken, err := server.tokenGenerator.CreateToken(testUser.Username, time.Minute)
+				require.NoError(t, err)
+
+				planted := server.csrfToken(uuid.New())
+				request.AddCookie(&http.Cookie{Name: refreshCookieName, Value: refreshToken})
+				request.AddCookie(&http.Cookie{Name: csrfCookieName, Value: planted})
+				request.Header.Set(csrfHeader, planted)
+			},
+			buildStubs: func (store *mockdb.MockStore)  {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkReposne: func (t *testing.T, recorder *httptest.ResponseRecorder)  {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodeCSRFInvalid)
+			},
+		},
+	}
+
+	for i := range testCases{
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/refresh", nil)
+			require.NoError(t, err)
+			tc.setupRequest(t, server, request)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
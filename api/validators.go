@@ -2,6 +2,7 @@ package api
 
 import (
 	"github.com/go-playground/validator/v10"
+	"github.com/ulunnuha-h/simple_bank/interest"
 	"github.com/ulunnuha-h/simple_bank/util"
 )
 
@@ -12,4 +13,39 @@ var currencyValidator validator.Func = func (fl validator.FieldLevel) bool {
 	}
 
 	return false
-}
\ No newline at end of file
+}
+
+var dayCountValidator validator.Func = func (fl validator.FieldLevel) bool {
+	data, ok := fl.Field().Interface().(string)
+	if ok {
+		return interest.DayCount(data).Valid()
+	}
+
+	return false
+}
+
+var compoundingValidator validator.Func = func (fl validator.FieldLevel) bool {
+	data, ok := fl.Field().Interface().(string)
+	if ok {
+		return interest.Compounding(data).Valid()
+	}
+
+	return false
+}
+var permissionValidator validator.Func = func (fl validator.FieldLevel) bool {
+	data, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsValidPermission(data)
+	}
+
+	return false
+}
+
+var webhookEventValidator validator.Func = func (fl validator.FieldLevel) bool {
+	data, ok := fl.Field().Interface().(string)
+	if ok {
+		return util.IsValidWebhookEvent(data)
+	}
+
+	return false
+}
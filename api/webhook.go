@@ -0,0 +1,247 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/token"
+)
+
+const webhookSecretPrefix = "whsec_"
+
+type createWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,max=10,unique,dive,webhookevent"`
+}
+
+// webhookResponse leaves the secret out except right after creation, which
+// is the only time it is shown.
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:         subscription.ID,
+		URL:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(secret), nil
+}
+
+func (server *Server) createWebhookSubscription(ctx *gin.Context) {
+	var req createWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := server.webhookURLs.Check(ctx, req.URL); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload.Username,
+		Url:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := newWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (server *Server) listWebhookSubscriptions(ctx *gin.Context) {
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, authPayload.Username)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	response := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, newWebhookResponse(subscription))
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type webhookUriRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteWebhookSubscription(ctx *gin.Context) {
+	var req webhookUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	rows, err := server.store.DeleteWebhookSubscription(ctx, db.DeleteWebhookSubscriptionParams{
+		ID:    req.ID,
+		Owner: authPayload.Username,
+	})
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if rows == 0 {
+		writeError(ctx, http.StatusNotFound, sql.ErrNoRows)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"deleted": req.ID})
+}
+
+// loadWebhookSubscription fetches a subscription owned by username.
+func (server *Server) loadWebhookSubscription(ctx *gin.Context, id int64, username string) (db.WebhookSubscription, bool) {
+	subscription, err := server.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, err)
+			return subscription, false
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return subscription, false
+	}
+
+	if subscription.Owner != username {
+		writeError(ctx, http.StatusForbidden, token.ErrDoesNotBelong)
+		return subscription, false
+	}
+
+	return subscription, true
+}
+
+type listWebhookDeliveriesRequest struct {
+	Status    string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+	PAGE_ID   int32  `form:"page_id" binding:"required,min=1"`
+	PAGE_SIZE int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listWebhookDeliveries shows recent deliveries of a subscription; filtering
+// on status=dead lists the dead letters.
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var reqUri webhookUriRequest
+	var reqQuery listWebhookDeliveriesRequest
+
+	if err := ctx.ShouldBindUri(&reqUri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&reqQuery); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	subscription, ok := server.loadWebhookSubscription(ctx, reqUri.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	args := db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          reqQuery.PAGE_SIZE,
+		Offset:         (reqQuery.PAGE_ID - 1) * reqQuery.PAGE_SIZE,
+	}
+	if reqQuery.Status != "" {
+		args.Status = &reqQuery.Status
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, args)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type retryWebhookDeliveryUriRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// retryWebhookDelivery queues a dead delivery again.
+func (server *Server) retryWebhookDelivery(ctx *gin.Context) {
+	var req retryWebhookDeliveryUriRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, err := GetAuthPayload(ctx)
+	if err != nil {
+		return
+	}
+
+	subscription, ok := server.loadWebhookSubscription(ctx, req.ID, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	delivery, err := server.store.RetryWebhookDelivery(ctx, db.RetryWebhookDeliveryParams{
+		ID:             req.DeliveryID,
+		SubscriptionID: subscription.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			writeError(ctx, http.StatusNotFound, fmt.Errorf("no dead delivery %d for webhook %d", req.DeliveryID, subscription.ID))
+			return
+		}
+		writeError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
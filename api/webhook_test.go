@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+type staticResolver map[string]string
+
+func (resolver staticResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ip, ok := resolver[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return []net.IPAddr{{IP: net.ParseIP(ip)}}, nil
+}
+
+var webhookResolver = staticResolver{
+	"example.com":          "93.184.216.34",
+	"metadata.example.com": "169.254.169.254",
+}
+
+func TestWebhookAPI(t *testing.T) {
+	user, _ := randomUser()
+	subscription := db.WebhookSubscription{
+		ID:         3,
+		Owner:      user.Username,
+		Url:        "https://example.com/hooks",
+		EventTypes: []string{util.TransferCreatedEvent},
+		Secret:     "whsec_abc",
+	}
+
+	testCases := []struct {
+		name         string
+		username     string
+		method       string
+		url          string
+		body         gin.H
+		buildStubs   func(store *mockdb.MockStore)
+		checkReposne func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CreateOK",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "https://example.com/hooks", "event_types": []string{"transfer.created", "session.created"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, args db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+						require.Equal(t, user.Username, args.Owner)
+						require.True(t, strings.HasPrefix(args.Secret, webhookSecretPrefix))
+						require.Len(t, args.Secret, len(webhookSecretPrefix)+64)
+						return db.WebhookSubscription{ID: 3, Owner: args.Owner, Url: args.Url, EventTypes: args.EventTypes, Secret: args.Secret}, nil
+					})
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.NotEmpty(t, response.Secret)
+			},
+		},
+		{
+			name:     "CreateUnknownEvent",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "https://example.com/hooks", "event_types": []string{"account.deleted"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeValidationFailed)
+			},
+		},
+		{
+			name:     "CreateBadScheme",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "ftp://example.com/hooks", "event_types": []string{"transfer.created"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "CreatePlainHTTP",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "http://example.com/hooks", "event_types": []string{"transfer.created"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "CreateInternalHost",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks",
+			body:     gin.H{"url": "https://metadata.example.com/latest", "event_types": []string{"transfer.created"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateWebhookSubscription(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorCode(t, recorder, CodeInvalidArgument)
+			},
+		},
+		{
+			name:     "ListHidesSecret",
+			username: user.Username,
+			method:   http.MethodGet,
+			url:      "/webhooks",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListWebhookSubscriptions(gomock.Any(), user.Username).Times(1).
+					Return([]db.WebhookSubscription{subscription}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), subscription.Secret)
+			},
+		},
+		{
+			name:     "DeleteNotFound",
+			username: user.Username,
+			method:   http.MethodDelete,
+			url:      "/webhooks/3",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteWebhookSubscription(gomock.Any(), db.DeleteWebhookSubscriptionParams{ID: 3, Owner: user.Username}).
+					Times(1).Return(int64(0), nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+		{
+			name:     "ListDeadLetters",
+			username: user.Username,
+			method:   http.MethodGet,
+			url:      "/webhooks/3/deliveries?status=dead&page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				dead := util.WebhookDeliveryDead
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), db.ListWebhookDeliveriesParams{
+					SubscriptionID: subscription.ID, Status: &dead, Limit: 5, Offset: 0,
+				}).Times(1).Return([]db.WebhookDelivery{}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "DeliveriesForbidden",
+			username: "someoneelse",
+			method:   http.MethodGet,
+			url:      "/webhooks/3/deliveries?page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().ListWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorCode(t, recorder, CodePermissionDenied)
+			},
+		},
+		{
+			name:     "Retry",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks/3/deliveries/9/retry",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().RetryWebhookDelivery(gomock.Any(), db.RetryWebhookDeliveryParams{ID: 9, SubscriptionID: subscription.ID}).
+					Times(1).Return(db.WebhookDelivery{ID: 9, Status: util.WebhookDeliveryPending}, nil)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RetryNotDead",
+			username: user.Username,
+			method:   http.MethodPost,
+			url:      "/webhooks/3/deliveries/9/retry",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetWebhookSubscription(gomock.Any(), subscription.ID).Times(1).Return(subscription, nil)
+				store.EXPECT().RetryWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows)
+			},
+			checkReposne: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorCode(t, recorder, CodeNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, err := NewServer(store)
+			require.NoError(t, err)
+			server.webhookURLs.Resolver = webhookResolver
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenGenerator, authTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkReposne(t, recorder)
+		})
+	}
+}
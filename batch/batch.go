@@ -0,0 +1,117 @@
+// Package batch parses bulk transfer files into rows that can be validated
+// and stored as a transfer batch.
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// Supported file formats.
+const (
+	FormatCSV     = "csv"
+	FormatPain001 = "pain001"
+)
+
+const (
+	// MaxRows caps how many transfers a single file may contain.
+	MaxRows = 1000
+
+	maxReferenceLength = 64
+	maxMemoLength      = 140
+)
+
+var (
+	ErrNoRows      = errors.New("file contains no transfers")
+	ErrTooManyRows = fmt.Errorf("file contains more than %d transfers", MaxRows)
+)
+
+// Row is one transfer read from a file. Line is where it starts in the file
+// for CSV, or its position among the transactions for pain.001. Rows that
+// can't be used carry the reason in Error; the other fields hold whatever
+// could be read.
+type Row struct {
+	Line        int
+	FromAccount string
+	ToAccount   string
+	Amount      int64
+	Currency    string
+	Reference   string
+	Memo        string
+	Error       string
+}
+
+// IsValidFormat reports whether name is a supported file format.
+func IsValidFormat(name string) bool {
+	return name == FormatCSV || name == FormatPain001
+}
+
+// DetectFormat guesses the format from a file name.
+func DetectFormat(filename string) (string, bool) {
+	switch {
+	case strings.HasSuffix(strings.ToLower(filename), ".csv"):
+		return FormatCSV, true
+	case strings.HasSuffix(strings.ToLower(filename), ".xml"):
+		return FormatPain001, true
+	}
+	return "", false
+}
+
+// ParseAmount reads a positive decimal amount with at most two fraction
+// digits, such as "12", "12.5" or "12.50", and returns it in minor units.
+func ParseAmount(s string) (int64, error) {
+	whole, frac, hasFrac := strings.Cut(strings.TrimSpace(s), ".")
+	if whole == "" || len(frac) > 2 || (hasFrac && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	frac += strings.Repeat("0", 2-len(frac))
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("amount must be greater than zero")
+	}
+	return amount, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// check applies the field rules the transfer API enforces on its request.
+func (row *Row) check() {
+	switch {
+	case row.FromAccount == "":
+		row.Error = "from account is required"
+	case row.ToAccount == "":
+		row.Error = "to account is required"
+	case !util.IsValidCurrency(row.Currency):
+		row.Error = fmt.Sprintf("unsupported currency %q", row.Currency)
+	case len(row.Reference) > maxReferenceLength:
+		row.Error = fmt.Sprintf("reference is longer than %d characters", maxReferenceLength)
+	case !isPrintableASCII(row.Reference):
+		row.Error = "reference must be printable ASCII"
+	case len([]rune(row.Memo)) > maxMemoLength:
+		row.Error = fmt.Sprintf("memo is longer than %d characters", maxMemoLength)
+	}
+}
+
+func isPrintableASCII(s string) bool {
+	for _, c := range s {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+func TestParseAmount(t *testing.T) {
+	valid := map[string]int64{"12": 1200, "12.5": 1250, "12.50": 1250, "0.01": 1, " 7.00 ": 700}
+	for s, want := range valid {
+		amount, err := ParseAmount(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, amount, s)
+	}
+
+	for _, s := range []string{"", "0", "0.00", "-5", "1.234", "1.", ".5", "1,50", "12a", "99999999999999999999"} {
+		_, err := ParseAmount(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, ok := DetectFormat("Payouts.CSV")
+	require.True(t, ok)
+	require.Equal(t, FormatCSV, format)
+
+	format, ok = DetectFormat("payouts.xml")
+	require.True(t, ok)
+	require.Equal(t, FormatPain001, format)
+
+	_, ok = DetectFormat("payouts.xlsx")
+	require.False(t, ok)
+}
+
+func TestParseCSV(t *testing.T) {
+	file := "\ufeffamount,Currency,from_account,to_account,memo,reference\n" +
+		"10.00,usd,123456789015,000000000018,Rent,INV-1\n" +
+		"\"1,000\",USD,123456789015,000000000018,,\n" +
+		"5,XYZ,123456789015,000000000018,,\n" +
+		"5,USD,,000000000018,,\n"
+
+	rows, err := ParseCSV(strings.NewReader(file))
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	require.Equal(t, Row{
+		Line: 2, FromAccount: "123456789015", ToAccount: "000000000018",
+		Amount: 1000, Currency: "USD", Reference: "INV-1", Memo: "Rent",
+	}, rows[0])
+	require.Contains(t, rows[1].Error, "invalid amount")
+	require.Equal(t, 3, rows[1].Line)
+	require.Contains(t, rows[2].Error, "unsupported currency")
+	require.Equal(t, "from account is required", rows[3].Error)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader(""))
+	require.ErrorIs(t, err, ErrNoRows)
+
+	_, err = ParseCSV(strings.NewReader("from_account,to_account,amount,currency\n"))
+	require.ErrorIs(t, err, ErrNoRows)
+
+	_, err = ParseCSV(strings.NewReader("from_account,to_account,amount\n1,2,3\n"))
+	require.ErrorContains(t, err, `missing column "currency"`)
+
+	file := "from_account,to_account,amount,currency\n" +
+		strings.Repeat("123456789015,000000000018,1,USD\n", MaxRows+1)
+	_, err = ParseCSV(strings.NewReader(file))
+	require.ErrorIs(t, err, ErrTooManyRows)
+}
+
+func TestParsePain001(t *testing.T) {
+	file, err := os.Open("testdata/payouts.pain001.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	rows, err := ParsePain001(file)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	require.Equal(t, Row{
+		Line: 1, FromAccount: "123456789015", ToAccount: "000000000018",
+		Amount: 100000, Currency: "USD", Reference: "SAL-0001", Memo: "March salary",
+	}, rows[0])
+	require.Equal(t, int64(25050), rows[1].Amount)
+	require.Empty(t, rows[1].Reference)
+	require.Empty(t, rows[1].Error)
+	require.Contains(t, rows[2].Error, "IBANs are not supported")
+}
+
+func TestParsePain001Errors(t *testing.T) {
+	original, err := os.ReadFile("testdata/payouts.pain001.xml")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		replace [2]string
+		err     string
+	}{
+		{"WrongNamespace", [2]string{"pain.001.001.03", "camt.053.001.02"}, "not a pain.001 document"},
+		{"NumberOfTxs", [2]string{"<NbOfTxs>3", "<NbOfTxs>4"}, "NbOfTxs"},
+		{"ControlSum", [2]string{"<CtrlSum>1260.50", "<CtrlSum>1260.00"}, "CtrlSum"},
+		{"Malformed", [2]string{"</Document>", ""}, "invalid pain.001 document"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := strings.Replace(string(original), tc.replace[0], tc.replace[1], 1)
+			_, err := ParsePain001(strings.NewReader(file))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestWriteResult(t *testing.T) {
+	transferID := int64(42)
+	items := []db.TransferBatchItem{
+		{
+			LineNo: 2, FromAccount: "123456789015", ToAccount: "000000000018", Amount: 1050,
+			Currency: "USD", Reference: "INV-1", Status: "succeeded", TransferID: &transferID,
+		},
+		{
+			LineNo: 3, FromAccount: "123456789015", ToAccount: "000000000026", Amount: 5,
+			Currency: "USD", Memo: "Bonus, March", Status: "invalid", Error: "to account not found",
+		},
+		{
+			LineNo: 4, FromAccount: "123456789015", ToAccount: "=1+1", Amount: 100,
+			Currency: "USD", Reference: "@SUM(A1)", Memo: "=HYPERLINK(\"http://x\")", Status: "invalid",
+			Error: "-to account not found",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteResult(&buf, items))
+	require.Equal(t,
+		"line,from_account,to_account,amount,currency,reference,memo,status,transfer_id,error\n"+
+			"2,123456789015,000000000018,10.50,USD,INV-1,,succeeded,42,\n"+
+			"3,123456789015,000000000026,0.05,USD,,\"Bonus, March\",invalid,,to account not found\n"+
+			"4,123456789015,'=1+1,1.00,USD,'@SUM(A1),\"'=HYPERLINK(\"\"http://x\"\")\",invalid,,'-to account not found\n",
+		buf.String())
+}
@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var csvRequired = []string{"from_account", "to_account", "amount", "currency"}
+
+// ParseCSV reads a CSV file with a header row. from_account, to_account,
+// amount and currency are required columns, reference and memo optional;
+// columns may appear in any order and unknown ones are ignored. Accounts are
+// account numbers and amounts are decimals in the major unit.
+func ParseCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoRows
+		}
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range csvRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == MaxRows {
+			return nil, ErrTooManyRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := Row{
+			Line:        line,
+			FromAccount: field(record, "from_account"),
+			ToAccount:   field(record, "to_account"),
+			Currency:    strings.ToUpper(field(record, "currency")),
+			Reference:   field(record, "reference"),
+			Memo:        field(record, "memo"),
+		}
+
+		row.Amount, err = ParseAmount(field(record, "amount"))
+		if err != nil {
+			row.Error = err.Error()
+		} else {
+			row.check()
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrNoRows
+	}
+	return rows, nil
+}
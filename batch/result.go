@@ -0,0 +1,51 @@
+package batch
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var resultHeader = []string{
+	"line", "from_account", "to_account", "amount", "currency",
+	"reference", "memo", "status", "transfer_id", "error",
+}
+
+// WriteResult writes one CSV line per batch row with its outcome, in the
+// order the rows appeared in the uploaded file. Text that came from the
+// upload is guarded against formula injection.
+func WriteResult(w io.Writer, items []db.TransferBatchItem) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(resultHeader); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		var transferID string
+		if item.TransferID != nil {
+			transferID = strconv.FormatInt(*item.TransferID, 10)
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(int(item.LineNo)),
+			util.CSVText(item.FromAccount),
+			util.CSVText(item.ToAccount),
+			util.FormatAmount(item.Amount),
+			item.Currency,
+			util.CSVText(item.Reference),
+			util.CSVText(item.Memo),
+			item.Status,
+			transferID,
+			util.CSVText(item.Error),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
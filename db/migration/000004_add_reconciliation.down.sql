@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "reconciliation_runs";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,20 @@
+-- 'now()' as a quoted literal is evaluated once when the table is created,
+-- which froze every created_at at migration time. Point-in-time balances
+-- depend on entries.created_at, so switch the defaults to the function.
+ALTER TABLE "accounts" ALTER COLUMN "created_at" SET DEFAULT (now());
+ALTER TABLE "entries" ALTER COLUMN "created_at" SET DEFAULT (now());
+ALTER TABLE "transfers" ALTER COLUMN "created_at" SET DEFAULT (now());
+ALTER TABLE "users" ALTER COLUMN "created_at" SET DEFAULT (now());
+ALTER TABLE "sessions" ALTER COLUMN "created_at" SET DEFAULT (now());
+
+CREATE TABLE "account_balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "snapshot_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "snapshot_date")
+);
+
+ALTER TABLE "account_balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
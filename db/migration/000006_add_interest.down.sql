@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS "interest_postings";
+DROP TABLE IF EXISTS "interest_accruals";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "account_id" FROM "system_accounts")
+  OR "transfer_id" IN (
+    SELECT "id" FROM "transfers"
+    WHERE "from_account_id" IN (SELECT "account_id" FROM "system_accounts")
+      OR "to_account_id" IN (SELECT "account_id" FROM "system_accounts")
+  );
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "account_id" FROM "system_accounts")
+  OR "to_account_id" IN (SELECT "account_id" FROM "system_accounts");
+
+DROP TABLE IF EXISTS "system_accounts";
+
+DELETE FROM "accounts" WHERE "owner" = 'bank_system';
+DELETE FROM "users" WHERE "username" = 'bank_system';
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "interest_product_id";
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "account_type";
+
+DROP TABLE IF EXISTS "interest_products";
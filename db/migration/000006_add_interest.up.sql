@@ -0,0 +1,69 @@
+CREATE TABLE "interest_products" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate_ppm" bigint NOT NULL,
+  "day_count" varchar NOT NULL,
+  "compounding" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "accounts" ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD COLUMN "interest_product_id" bigint;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("interest_product_id") REFERENCES "interest_products" ("id");
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "principal" bigint NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "posting_period" date,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE TABLE "interest_postings" (
+  "account_id" bigint NOT NULL,
+  "period" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "carry_micros" bigint NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "period")
+);
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- System accounts are owned by a user that can never log in (the password
+-- hash is not a valid bcrypt hash) and whose name can't be registered
+-- through the API, which only accepts alphanumeric usernames.
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "role")
+VALUES ('bank_system', '!', 'Simple Bank', 'system@simplebank.invalid', 'system');
+
+CREATE TABLE "system_accounts" (
+  "purpose" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("purpose", "currency")
+);
+
+ALTER TABLE "system_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+-- The system user needs one account per purpose and currency.
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_idx" ON "accounts" ("owner", "currency") WHERE "account_type" <> 'system';
+
+WITH created AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "account_type")
+  SELECT 'bank_system', 0, c, 'system' FROM unnest(ARRAY['USD', 'EUR', 'IDR']) AS c
+  RETURNING "id", "currency"
+)
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id" FROM created;
@@ -0,0 +1,14 @@
+CREATE TEMP TABLE "fee_accounts" AS
+SELECT "account_id" FROM "system_accounts" WHERE "purpose" = 'fee_revenue';
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "account_id" FROM "fee_accounts");
+
+DELETE FROM "system_accounts" WHERE "purpose" = 'fee_revenue';
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "account_id" FROM "fee_accounts");
+
+DROP TABLE IF EXISTS "fee_schedules";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "fee";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "tier";
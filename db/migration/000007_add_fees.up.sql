@@ -0,0 +1,25 @@
+ALTER TABLE "accounts" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "tier" varchar NOT NULL,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "rate_ppm" bigint NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency", "tier");
+
+WITH created AS (
+  INSERT INTO "accounts" ("owner", "balance", "currency", "account_type")
+  SELECT 'bank_system', 0, c, 'system' FROM unnest(ARRAY['USD', 'EUR', 'IDR']) AS c
+  RETURNING "id", "currency"
+)
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'fee_revenue', "currency", "id" FROM created;
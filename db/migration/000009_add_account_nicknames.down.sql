@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_account_type_idx";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "nickname";
+
+CREATE UNIQUE INDEX "accounts_owner_currency_idx" ON "accounts" ("owner", "currency") WHERE "account_type" <> 'system';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "accounts" ("owner", "currency", "account_type");
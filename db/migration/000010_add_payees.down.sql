@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "payees";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "account_number";
+
+DROP FUNCTION IF EXISTS generate_account_number();
+
+DROP FUNCTION IF EXISTS luhn_check_digit(text);
@@ -0,0 +1,47 @@
+-- Public account numbers are 11 random digits followed by a Luhn check digit,
+-- so they can be shared without exposing the internal id sequence.
+CREATE FUNCTION luhn_check_digit(digits text) RETURNS int AS $$
+DECLARE
+  total int := 0;
+  d int;
+  n int := length(digits);
+BEGIN
+  FOR i IN 1..n LOOP
+    d := substr(digits, n - i + 1, 1)::int;
+    IF i % 2 = 1 THEN
+      d := d * 2;
+      IF d > 9 THEN
+        d := d - 9;
+      END IF;
+    END IF;
+    total := total + d;
+  END LOOP;
+  RETURN (10 - total % 10) % 10;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+CREATE FUNCTION generate_account_number() RETURNS varchar AS $$
+DECLARE
+  body text := lpad(floor(random() * 100000000000)::bigint::text, 11, '0');
+BEGIN
+  RETURN body || luhn_check_digit(body);
+END;
+$$ LANGUAGE plpgsql VOLATILE;
+
+ALTER TABLE "accounts" ADD COLUMN "account_number" varchar NOT NULL DEFAULT generate_account_number();
+
+CREATE UNIQUE INDEX ON "accounts" ("account_number");
+
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "nickname" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "account_id");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "discoverable" boolean NOT NULL DEFAULT false;
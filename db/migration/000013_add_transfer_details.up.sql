@@ -0,0 +1,13 @@
+ALTER TABLE "transfers"
+  ADD COLUMN "memo" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "reference" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "entries"
+  ADD COLUMN "memo" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "reference" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "transfers" ("reference") WHERE "reference" <> '';
+
+CREATE INDEX ON "entries" ("account_id", "reference") WHERE "reference" <> '';
@@ -0,0 +1,48 @@
+CREATE TABLE "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "format" varchar NOT NULL,
+  "filename" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "item_count" int NOT NULL,
+  "valid_count" int NOT NULL,
+  "succeeded_count" int NOT NULL DEFAULT 0,
+  "failed_count" int NOT NULL DEFAULT 0,
+  "approved_by" varchar,
+  "approved_at" timestamptz,
+  "completed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_batch_items" (
+  "id" bigserial PRIMARY KEY,
+  "batch_id" bigint NOT NULL,
+  "line_no" int NOT NULL,
+  "from_account" varchar NOT NULL,
+  "to_account" varchar NOT NULL,
+  "from_account_id" bigint,
+  "to_account_id" bigint,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "reference" varchar NOT NULL DEFAULT '',
+  "memo" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint
+);
+
+CREATE INDEX ON "transfer_batches" ("owner", "created_at");
+
+CREATE UNIQUE INDEX ON "transfer_batch_items" ("batch_id", "line_no");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batches" ADD FOREIGN KEY ("approved_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "transfer_batch_items" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhook_subscriptions";
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "frozen";
@@ -0,0 +1,10 @@
+CREATE TABLE "events" (
+  "seq" bigserial PRIMARY KEY,
+  "event_id" uuid UNIQUE NOT NULL,
+  "event_type" varchar NOT NULL,
+  "account_ids" bigint[] NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "events" ("created_at");
@@ -0,0 +1,2 @@
+-- The backfilled links are correct data; 000004's down drops the column.
+SELECT 1;
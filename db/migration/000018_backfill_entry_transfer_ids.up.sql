@@ -0,0 +1,18 @@
+-- Entries written before 000004 have no transfer_id, so their transfers look
+-- like they have no entries at all. Both entries of such a transfer were
+-- written in its transaction and share its created_at; link an entry only when
+-- exactly one transfer matches it.
+WITH "matches" AS (
+  SELECT e."id" AS "entry_id", min(t."id") AS "transfer_id", count(*) AS "candidates"
+  FROM "entries" e
+  JOIN "transfers" t ON t."created_at" = e."created_at" AND (
+    (e."account_id" = t."from_account_id" AND e."amount" = -t."amount") OR
+    (e."account_id" = t."to_account_id" AND e."amount" = t."amount")
+  )
+  WHERE e."transfer_id" IS NULL
+  GROUP BY e."id"
+)
+UPDATE "entries"
+SET "transfer_id" = "matches"."transfer_id"
+FROM "matches"
+WHERE "entries"."id" = "matches"."entry_id" AND "matches"."candidates" = 1;
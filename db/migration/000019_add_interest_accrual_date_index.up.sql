@@ -0,0 +1 @@
+CREATE INDEX ON "interest_accruals" ("accrual_date");
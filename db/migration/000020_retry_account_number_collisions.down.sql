@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION generate_account_number() RETURNS varchar AS $$
+DECLARE
+  body text := lpad(floor(random() * 100000000000)::bigint::text, 11, '0');
+BEGIN
+  RETURN body || luhn_check_digit(body);
+END;
+$$ LANGUAGE plpgsql VOLATILE;
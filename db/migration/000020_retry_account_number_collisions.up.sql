@@ -0,0 +1,15 @@
+-- Draw again while the number is taken, instead of failing the insert on the
+-- unique index.
+CREATE OR REPLACE FUNCTION generate_account_number() RETURNS varchar AS $$
+DECLARE
+  body text;
+  candidate varchar;
+BEGIN
+  LOOP
+    body := lpad(floor(random() * 100000000000)::bigint::text, 11, '0');
+    candidate := body || luhn_check_digit(body);
+    EXIT WHEN NOT EXISTS (SELECT 1 FROM accounts WHERE account_number = candidate);
+  END LOOP;
+  RETURN candidate;
+END;
+$$ LANGUAGE plpgsql VOLATILE;
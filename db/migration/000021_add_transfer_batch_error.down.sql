@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfer_batches_status_approved_at_idx";
+
+ALTER TABLE "transfer_batches" DROP COLUMN IF EXISTS "error";
@@ -0,0 +1,3 @@
+ALTER TABLE "transfer_batches" ADD COLUMN "error" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfer_batches" ("status", "approved_at");
@@ -0,0 +1,2 @@
+-- The redacted text is gone; nothing to restore.
+SELECT 1;
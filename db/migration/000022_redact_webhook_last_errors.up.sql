@@ -0,0 +1,8 @@
+-- Deliveries used to keep the start of the receiver's response, or the
+-- connection error, in last_error. Keep only the status code.
+UPDATE "webhook_deliveries"
+SET "last_error" = CASE
+  WHEN "last_error" ~ '^[0-9]{3} ' THEN 'status ' || left("last_error", 3)
+  ELSE 'request failed'
+END
+WHERE "last_error" <> '';
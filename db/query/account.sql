@@ -2,9 +2,12 @@
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  account_type,
+  interest_product_id,
+  nickname
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -16,12 +19,31 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetSystemAccount :one
+SELECT a.* FROM accounts a
+JOIN system_accounts s ON s.account_id = a.id
+WHERE s.purpose = $1 AND s.currency = $2
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE (owner = sqlc.arg(owner)
+   OR id IN (SELECT account_id FROM account_members WHERE username = sqlc.arg(owner)))
+  AND (sqlc.narg(currency)::varchar IS NULL OR currency = sqlc.narg(currency))
+  AND (sqlc.narg(account_type)::varchar IS NULL OR account_type = sqlc.narg(account_type))
 ORDER BY id
-LIMIT $2
-OFFSET $3;
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountOwnedAccounts :one
+SELECT count(*) FROM accounts
+WHERE owner = $1;
+
+-- name: UpdateAccountNickname :one
+UPDATE accounts
+SET nickname = $2
+WHERE id = $1
+RETURNING *;
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -29,6 +51,12 @@ SET balance = $2
 WHERE id = $1
 RETURNING *;
 
+-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = $2
+WHERE id = $1
+RETURNING *;
+
 -- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
@@ -37,4 +65,32 @@ RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetAccountHolderByNumber :one
+SELECT a.id, a.account_number, a.currency, u.full_name
+FROM accounts a
+JOIN users u ON u.username = a.owner
+WHERE a.account_number = $1 AND a.account_type <> 'system'
+LIMIT 1;
+
+-- name: GetReceivingAccount :one
+-- Picks the account that receives payments addressed to a user rather than
+-- to one of their accounts: the oldest checking account in the currency,
+-- falling back to the oldest account of any other type.
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND account_type <> 'system'
+ORDER BY account_type = 'checking' DESC, id
+LIMIT 1;
+
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE account_number = $1 AND account_type <> 'system'
+LIMIT 1;
+
+-- name: SetAccountFrozen :one
+-- Returns no row when the account is already in the requested state.
+UPDATE accounts
+SET frozen = sqlc.arg(frozen)
+WHERE id = sqlc.arg(id) AND frozen <> sqlc.arg(frozen)
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: AddAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  permission,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, username) DO UPDATE
+SET permission = EXCLUDED.permission
+RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2
+LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: RemoveAccountMember :execrows
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2;
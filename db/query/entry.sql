@@ -1,9 +1,13 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  memo,
+  reference,
+  metadata
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -12,7 +16,29 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
-WHERE account_id = $1
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(reference)::varchar IS NULL OR reference = sqlc.narg(reference))
 ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.amount,
+  e.transfer_id,
+  e.memo,
+  e.reference,
+  e.created_at,
+  COALESCE(
+    CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END,
+    0
+  )::bigint AS counterparty_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+  AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg('limit');
@@ -0,0 +1,25 @@
+-- name: LockEventStream :exec
+-- Serialises appends to the event stream until the calling transaction ends.
+-- Sequence numbers are handed out while the lock is held, so events commit in
+-- seq order and a reader resuming after a seq can never miss a lower one that
+-- commits late.
+SELECT pg_advisory_xact_lock(7271001);
+
+-- name: AppendEvent :one
+INSERT INTO events (
+  event_id,
+  event_type,
+  account_ids,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListEvents :many
+SELECT * FROM events
+WHERE seq > sqlc.arg(after_seq)
+ORDER BY seq
+LIMIT sqlc.arg('limit');
+
+-- name: GetLastEventSeq :one
+SELECT COALESCE(MAX(seq), 0)::bigint FROM events;
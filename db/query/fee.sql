@@ -0,0 +1,27 @@
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency,
+  tier,
+  flat_fee,
+  rate_ppm,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) ON CONFLICT (currency, tier) DO UPDATE
+SET
+  flat_fee = EXCLUDED.flat_fee,
+  rate_ppm = EXCLUDED.rate_ppm,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE currency = $1 AND tier = $2
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY currency, tier;
@@ -0,0 +1,92 @@
+-- name: CreateInterestProduct :one
+INSERT INTO interest_products (
+  name,
+  currency,
+  annual_rate_ppm,
+  day_count,
+  compounding
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetInterestProduct :one
+SELECT * FROM interest_products
+WHERE id = $1 LIMIT 1;
+
+-- name: ListInterestProducts :many
+SELECT * FROM interest_products
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListInterestBearingAccounts :many
+SELECT sqlc.embed(a), sqlc.embed(p)
+FROM accounts a
+JOIN interest_products p ON p.id = a.interest_product_id
+WHERE a.account_type = 'savings' AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(chunk_size);
+
+-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  principal,
+  amount_micros
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: GetLatestInterestAccrualDate :one
+SELECT accrual_date FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount_micros), 0)::bigint AS total FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id)
+  AND posting_period IS NULL
+  AND accrual_date < sqlc.arg(before_date);
+
+-- name: MarkInterestPosted :execrows
+UPDATE interest_accruals
+SET posting_period = sqlc.arg(period)::date
+WHERE account_id = sqlc.arg(account_id)
+  AND posting_period IS NULL
+  AND accrual_date < sqlc.arg(before_date);
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posting_period IS NULL
+  AND accrual_date < sqlc.arg(before_date)
+  AND account_id > sqlc.arg(after_id)
+ORDER BY account_id
+LIMIT sqlc.arg(chunk_size);
+
+-- name: GetLatestInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT 1;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period,
+  amount,
+  carry_micros
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: GetInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1 AND period = $2
+LIMIT 1;
+
+-- name: SetInterestPostingTransfer :one
+UPDATE interest_postings
+SET transfer_id = sqlc.arg(transfer_id)
+WHERE account_id = sqlc.arg(account_id) AND period = sqlc.arg(period)
+RETURNING *;
@@ -0,0 +1,24 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+  owner,
+  account_id,
+  nickname
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT p.id, p.nickname, p.created_at, a.account_number, a.currency, u.full_name
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+JOIN users u ON u.username = a.owner
+WHERE p.owner = $1
+ORDER BY p.nickname, p.id;
+
+-- name: DeletePayee :execrows
+DELETE FROM payees
+WHERE id = $1 AND owner = $2;
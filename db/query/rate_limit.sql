@@ -0,0 +1,27 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket at key for the time since it was last used, then takes
+-- a token when a whole one is available; allowed records whether it did. A
+-- new bucket starts full. The upsert locks the row, so concurrent takes on
+-- one key queue up instead of spending the same token twice.
+INSERT INTO rate_limit_buckets AS b (
+  key,
+  tokens,
+  allowed,
+  period_seconds,
+  updated_at
+) VALUES (
+  sqlc.arg(key), sqlc.arg(burst)::float8 - 1, true, sqlc.arg(period_seconds)::float8, now()
+)
+ON CONFLICT (key) DO UPDATE SET
+  tokens = LEAST(sqlc.arg(burst)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * sqlc.arg(burst)::float8 / sqlc.arg(period_seconds)::float8)
+    - CASE WHEN LEAST(sqlc.arg(burst)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * sqlc.arg(burst)::float8 / sqlc.arg(period_seconds)::float8) >= 1 THEN 1 ELSE 0 END,
+  allowed = LEAST(sqlc.arg(burst)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * sqlc.arg(burst)::float8 / sqlc.arg(period_seconds)::float8) >= 1,
+  period_seconds = EXCLUDED.period_seconds,
+  updated_at = now()
+RETURNING tokens, allowed;
+
+-- name: DeleteFullRateLimitBuckets :execrows
+-- A bucket left alone for a whole period is full again, which is the same
+-- as having no row.
+DELETE FROM rate_limit_buckets
+WHERE updated_at + make_interval(secs => period_seconds) < now();
@@ -0,0 +1,59 @@
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  triggered_by
+) VALUES (
+  $1
+) RETURNING *;
+
+-- name: FinishReconciliationRun :one
+UPDATE reconciliation_runs
+SET
+  status = sqlc.arg(status),
+  accounts_checked = sqlc.arg(accounts_checked),
+  transfers_checked = sqlc.arg(transfers_checked),
+  discrepancy_count = sqlc.arg(discrepancy_count),
+  findings = sqlc.arg(findings),
+  error = sqlc.arg(error),
+  finished_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListReconciliationRuns :many
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: ListAccountLedgerTotals :many
+SELECT
+  a.id,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id > sqlc.arg(after_id)
+GROUP BY a.id
+ORDER BY a.id
+LIMIT sqlc.arg(chunk_size);
+
+-- name: ListTransferLedgerTotals :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.fee,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS from_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS to_total
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+WHERE t.id > sqlc.arg(after_id)
+GROUP BY t.id
+ORDER BY t.id
+LIMIT sqlc.arg(chunk_size);
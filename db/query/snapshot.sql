@@ -0,0 +1,37 @@
+-- name: UpsertBalanceSnapshots :many
+INSERT INTO account_balance_snapshots (
+  account_id,
+  snapshot_date,
+  balance
+)
+SELECT
+  a.id,
+  sqlc.arg(snapshot_date)::date,
+  a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(cutoff)
+  ), 0)::bigint
+FROM accounts a
+WHERE a.id > sqlc.arg(after_id) AND a.created_at < sqlc.arg(cutoff)
+ORDER BY a.id
+LIMIT sqlc.arg(chunk_size)
+ON CONFLICT (account_id, snapshot_date) DO UPDATE
+SET balance = EXCLUDED.balance, created_at = now()
+RETURNING account_id;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT * FROM account_balance_snapshots
+WHERE account_id = sqlc.arg(account_id) AND snapshot_date < sqlc.arg(before_date)::date
+ORDER BY snapshot_date DESC
+LIMIT 1;
+
+-- name: SumEntriesInRange :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time);
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(from_time);
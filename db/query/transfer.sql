@@ -2,9 +2,13 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  memo,
+  reference,
+  metadata
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -14,8 +18,9 @@ WHERE id = $1 LIMIT 1;
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+    (from_account_id = sqlc.arg(from_account_id) OR
+    to_account_id = sqlc.arg(to_account_id))
+    AND (sqlc.narg(reference)::varchar IS NULL OR reference = sqlc.narg(reference))
 ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
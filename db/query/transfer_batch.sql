@@ -0,0 +1,97 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner,
+  format,
+  filename,
+  item_count,
+  valid_count
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTransferBatches :many
+SELECT * FROM transfer_batches
+WHERE owner = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: StartTransferBatch :one
+-- Moves a pending batch to processing. It returns no row when the batch was
+-- already approved, so only one caller ever executes it.
+UPDATE transfer_batches
+SET status = 'processing', approved_by = sqlc.arg(approved_by), approved_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
+
+-- name: ListProcessingTransferBatches :many
+-- Batches approved but not yet finished, oldest approval first.
+SELECT * FROM transfer_batches
+WHERE status = 'processing'
+ORDER BY approved_at, id
+LIMIT $1;
+
+-- name: CompleteTransferBatch :one
+UPDATE transfer_batches b
+SET status = 'completed',
+  completed_at = now(),
+  succeeded_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'succeeded'),
+  failed_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'failed')
+WHERE b.id = $1
+RETURNING *;
+
+-- name: FailTransferBatch :one
+-- Stops a batch that could not be run. Rows paid before the failure stay
+-- paid; the rest are left valid and unpaid.
+UPDATE transfer_batches b
+SET status = 'failed',
+  error = sqlc.arg(error),
+  completed_at = now(),
+  succeeded_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'succeeded'),
+  failed_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'failed')
+WHERE b.id = sqlc.arg(id) AND b.status = 'processing'
+RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+  batch_id,
+  line_no,
+  from_account,
+  to_account,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  reference,
+  memo,
+  status,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING *;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY line_no;
+
+-- name: GetTransferBatchItemForUpdate :one
+SELECT * FROM transfer_batch_items
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: MarkTransferBatchItemSucceeded :one
+UPDATE transfer_batch_items
+SET status = 'succeeded', transfer_id = sqlc.arg(transfer_id), error = ''
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: MarkTransferBatchItemFailed :one
+UPDATE transfer_batch_items
+SET status = 'failed', error = sqlc.arg(error)
+WHERE id = sqlc.arg(id) AND status = 'valid'
+RETURNING *;
@@ -0,0 +1,90 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner,
+  url,
+  event_types,
+  secret
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1 AND owner = $2;
+
+-- name: EnqueueWebhookEvent :execrows
+-- Queues one delivery per subscription of the given users that listens for
+-- the event type. Called inside the transaction that makes the change.
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+SELECT id, sqlc.arg(event_id), sqlc.arg(event_type), sqlc.arg(payload)
+FROM webhook_subscriptions
+WHERE owner = ANY(sqlc.arg(owners)::varchar[])
+  AND sqlc.arg(event_type)::varchar = ANY(event_types);
+
+-- name: ClaimWebhookDeliveries :many
+-- Picks deliveries that are due and pushes their next attempt out to
+-- lease_until, so other dispatchers skip them while this one is sending. A
+-- dispatcher that dies mid-send simply lets the lease run out.
+WITH claimed AS (
+  UPDATE webhook_deliveries
+  SET next_attempt_at = sqlc.arg(lease_until)
+  WHERE id IN (
+    SELECT id FROM webhook_deliveries
+    WHERE status = 'pending' AND next_attempt_at <= now()
+    ORDER BY id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+  )
+  RETURNING *
+)
+SELECT
+  c.id,
+  c.subscription_id,
+  c.event_id,
+  c.event_type,
+  c.payload,
+  c.attempts,
+  s.url,
+  s.secret
+FROM claimed c
+JOIN webhook_subscriptions s ON s.id = c.subscription_id
+ORDER BY c.id;
+
+-- name: MarkWebhookDelivered :one
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = attempts + 1, delivered_at = now(), last_error = ''
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkWebhookFailed :one
+UPDATE webhook_deliveries
+SET status = sqlc.arg(status),
+  attempts = attempts + 1,
+  next_attempt_at = sqlc.arg(next_attempt_at),
+  last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg(subscription_id)
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: RetryWebhookDelivery :one
+-- Puts a dead delivery back in the queue with a fresh set of attempts.
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''
+WHERE id = sqlc.arg(id) AND subscription_id = sqlc.arg(subscription_id) AND status = 'dead'
+RETURNING *;
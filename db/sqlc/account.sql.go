@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
 `
 
 type AddAccountBalanceParams struct {
@@ -30,28 +30,59 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
 	)
 	return i, err
 }
 
+const countOwnedAccounts = `-- name: CountOwnedAccounts :one
+SELECT count(*) FROM accounts
+WHERE owner = $1
+`
+
+func (q *Queries) CountOwnedAccounts(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOwnedAccounts, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
   balance,
-  currency
+  currency,
+  account_type,
+  interest_product_id,
+  nickname
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner             string `json:"owner"`
+	Balance           int64  `json:"balance"`
+	Currency          string `json:"currency"`
+	AccountType       string `json:"account_type"`
+	InterestProductID *int64 `json:"interest_product_id"`
+	Nickname          string `json:"nickname"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.AccountType,
+		arg.InterestProductID,
+		arg.Nickname,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,6 +90,12 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
 	)
 	return i, err
 }
@@ -74,7 +111,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +124,43 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen FROM accounts
+WHERE account_number = $1 AND account_type <> 'system'
+LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, accountNumber)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +174,135 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
+const getAccountHolderByNumber = `-- name: GetAccountHolderByNumber :one
+SELECT a.id, a.account_number, a.currency, u.full_name
+FROM accounts a
+JOIN users u ON u.username = a.owner
+WHERE a.account_number = $1 AND a.account_type <> 'system'
+LIMIT 1
+`
+
+type GetAccountHolderByNumberRow struct {
+	ID            int64  `json:"id"`
+	AccountNumber string `json:"account_number"`
+	Currency      string `json:"currency"`
+	FullName      string `json:"full_name"`
+}
+
+func (q *Queries) GetAccountHolderByNumber(ctx context.Context, accountNumber string) (GetAccountHolderByNumberRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountHolderByNumber, accountNumber)
+	var i GetAccountHolderByNumberRow
+	err := row.Scan(
+		&i.ID,
+		&i.AccountNumber,
+		&i.Currency,
+		&i.FullName,
+	)
+	return i, err
+}
+
+const getReceivingAccount = `-- name: GetReceivingAccount :one
+SELECT id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen FROM accounts
+WHERE owner = $1 AND currency = $2 AND account_type <> 'system'
+ORDER BY account_type = 'checking' DESC, id
+LIMIT 1
+`
+
+type GetReceivingAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+// Picks the account that receives payments addressed to a user rather than
+// to one of their accounts: the oldest checking account in the currency,
+// falling back to the oldest account of any other type.
+func (q *Queries) GetReceivingAccount(ctx context.Context, arg GetReceivingAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getReceivingAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.account_type, a.interest_product_id, a.tier, a.nickname, a.account_number, a.frozen FROM accounts a
+JOIN system_accounts s ON s.account_id = a.id
+WHERE s.purpose = $1 AND s.currency = $2
+LIMIT 1
+`
+
+type GetSystemAccountParams struct {
+	Purpose  string `json:"purpose"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, arg.Purpose, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+SELECT id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen FROM accounts
+WHERE (owner = $1
+   OR id IN (SELECT account_id FROM account_members WHERE username = $1))
+  AND ($2::varchar IS NULL OR currency = $2)
+  AND ($3::varchar IS NULL OR account_type = $3)
 ORDER BY id
-LIMIT $2
-OFFSET $3
+LIMIT $5
+OFFSET $4
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner       string  `json:"owner"`
+	Currency    *string `json:"currency"`
+	AccountType *string `json:"account_type"`
+	Offset      int32   `json:"offset"`
+	Limit       int32   `json:"limit"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.Owner,
+		arg.Currency,
+		arg.AccountType,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +316,12 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AccountType,
+			&i.InterestProductID,
+			&i.Tier,
+			&i.Nickname,
+			&i.AccountNumber,
+			&i.Frozen,
 		); err != nil {
 			return nil, err
 		}
@@ -153,11 +336,43 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const setAccountFrozen = `-- name: SetAccountFrozen :one
+UPDATE accounts
+SET frozen = $1
+WHERE id = $2 AND frozen <> $1
+RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
+`
+
+type SetAccountFrozenParams struct {
+	Frozen bool  `json:"frozen"`
+	ID     int64 `json:"id"`
+}
+
+// Returns no row when the account is already in the requested state.
+func (q *Queries) SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountFrozen, arg.Frozen, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
 `
 
 type UpdateAccountParams struct {
@@ -174,6 +389,74 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
+const updateAccountNickname = `-- name: UpdateAccountNickname :one
+UPDATE accounts
+SET nickname = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
+`
+
+type UpdateAccountNicknameParams struct {
+	ID       int64  `json:"id"`
+	Nickname string `json:"nickname"`
+}
+
+func (q *Queries) UpdateAccountNickname(ctx context.Context, arg UpdateAccountNicknameParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountNickname, arg.ID, arg.Nickname)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
+	)
+	return i, err
+}
+
+const updateAccountTier = `-- name: UpdateAccountTier :one
+UPDATE accounts
+SET tier = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_type, interest_product_id, tier, nickname, account_number, frozen
+`
+
+type UpdateAccountTierParams struct {
+	ID   int64  `json:"id"`
+	Tier string `json:"tier"`
+}
+
+func (q *Queries) UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountTier, arg.ID, arg.Tier)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.InterestProductID,
+		&i.Tier,
+		&i.Nickname,
+		&i.AccountNumber,
+		&i.Frozen,
 	)
 	return i, err
 }
@@ -0,0 +1,125 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: account_member.sql
+
+package db
+
+import (
+	"context"
+)
+
+const addAccountMember = `-- name: AddAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  permission,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, username) DO UPDATE
+SET permission = EXCLUDED.permission
+RETURNING account_id, username, permission, invited_by, created_at
+`
+
+type AddAccountMemberParams struct {
+	AccountID  int64  `json:"account_id"`
+	Username   string `json:"username"`
+	Permission string `json:"permission"`
+	InvitedBy  string `json:"invited_by"`
+}
+
+func (q *Queries) AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, addAccountMember,
+		arg.AccountID,
+		arg.Username,
+		arg.Permission,
+		arg.InvitedBy,
+	)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.Username,
+		&i.Permission,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountMember = `-- name: GetAccountMember :one
+SELECT account_id, username, permission, invited_by, created_at FROM account_members
+WHERE account_id = $1 AND username = $2
+LIMIT 1
+`
+
+type GetAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+func (q *Queries) GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMember, arg.AccountID, arg.Username)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.Username,
+		&i.Permission,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountMembers = `-- name: ListAccountMembers :many
+SELECT account_id, username, permission, invited_by, created_at FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username
+`
+
+func (q *Queries) ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountMember{}
+	for rows.Next() {
+		var i AccountMember
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.Username,
+			&i.Permission,
+			&i.InvitedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeAccountMember = `-- name: RemoveAccountMember :execrows
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2
+`
+
+type RemoveAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+func (q *Queries) RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeAccountMember, arg.AccountID, arg.Username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestAccountMembers(t *testing.T) {
+	account := CreateRandomAccount(t)
+	partner := CreateRandomUser(t)
+
+	member, err := testQuery.AddAccountMember(context.Background(), AddAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   partner.Username,
+		Permission: util.ViewPermission,
+		InvitedBy:  account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.ViewPermission, member.Permission)
+
+	// Inviting again changes the permission instead of failing.
+	member, err = testQuery.AddAccountMember(context.Background(), AddAccountMemberParams{
+		AccountID:  account.ID,
+		Username:   partner.Username,
+		Permission: util.TransferPermission,
+		InvitedBy:  account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferPermission, member.Permission)
+
+	got, err := testQuery.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  partner.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, member, got)
+
+	shared, err := testQuery.ListAccounts(context.Background(), ListAccountsParams{
+		Owner: partner.Username,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, shared, 1)
+	require.Equal(t, account.ID, shared[0].ID)
+
+	members, err := testQuery.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	removed, err := testQuery.RemoveAccountMember(context.Background(), RemoveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  partner.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+
+	removed, err = testQuery.RemoveAccountMember(context.Background(), RemoveAccountMemberParams{
+		AccountID: account.ID,
+		Username:  partner.Username,
+	})
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
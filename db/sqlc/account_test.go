@@ -17,6 +17,7 @@ func CreateRandomAccount(t *testing.T) Account {
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		AccountType: util.CheckingAccount,
 	}
 
 	account, err := testQuery.CreateAccount(context.Background(), args)
@@ -99,3 +100,123 @@ func TestListAccount(t *testing.T) {
 		require.NotEmpty(t, account)
 	}
 }
+
+func TestListAccountsByCurrencyAndType(t *testing.T) {
+	owner := CreateRandomUser(t)
+
+	create := func(currency, accountType, nickname string) Account {
+		account, err := testQuery.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:       owner.Username,
+			Currency:    currency,
+			AccountType: accountType,
+			Nickname:    nickname,
+		})
+		require.NoError(t, err)
+		require.Equal(t, nickname, account.Nickname)
+		return account
+	}
+
+	// Several accounts in the same currency are allowed.
+	create("USD", util.CheckingAccount, "Bills")
+	pocket := create("USD", util.PocketAccount, "Holiday")
+	create("EUR", util.PocketAccount, "Travel")
+
+	count, err := testQuery.CountOwnedAccounts(context.Background(), owner.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+
+	currency := "USD"
+	accountType := util.PocketAccount
+	accounts, err := testQuery.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:       owner.Username,
+		Currency:    &currency,
+		AccountType: &accountType,
+		Limit:       5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, pocket.ID, accounts[0].ID)
+
+	accounts, err = testQuery.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:    owner.Username,
+		Currency: &currency,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	renamed, err := testQuery.UpdateAccountNickname(context.Background(), UpdateAccountNicknameParams{
+		ID:       pocket.ID,
+		Nickname: "Rainy day",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Rainy day", renamed.Nickname)
+}
+
+func TestGetReceivingAccount(t *testing.T) {
+	owner := CreateRandomUser(t)
+
+	create := func(accountType string) Account {
+		account, err := testQuery.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:       owner.Username,
+			Currency:    "IDR",
+			AccountType: accountType,
+		})
+		require.NoError(t, err)
+		return account
+	}
+
+	pocket := create(util.PocketAccount)
+
+	account, err := testQuery.GetReceivingAccount(context.Background(), GetReceivingAccountParams{Owner: owner.Username, Currency: "IDR"})
+	require.NoError(t, err)
+	require.Equal(t, pocket.ID, account.ID)
+
+	// A checking account wins over older accounts of other types.
+	checking := create(util.CheckingAccount)
+	account, err = testQuery.GetReceivingAccount(context.Background(), GetReceivingAccountParams{Owner: owner.Username, Currency: "IDR"})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+
+	_, err = testQuery.GetReceivingAccount(context.Background(), GetReceivingAccountParams{Owner: owner.Username, Currency: "EUR"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateAccountTxLimit(t *testing.T) {
+	store := NewStore(testDB)
+	user := CreateRandomUser(t)
+
+	args := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:       user.Username,
+			Currency:    "USD",
+			AccountType: util.CheckingAccount,
+		},
+		MaxAccounts: 3,
+	}
+
+	// Ten concurrent requests race for three slots.
+	n := 10
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.CreateAccountTx(context.Background(), args)
+			errs <- err
+		}()
+	}
+
+	var created int
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, ErrAccountLimitReached)
+	}
+	require.Equal(t, 3, created)
+
+	count, err := testQuery.CountOwnedAccounts(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), count)
+}
@@ -7,36 +7,57 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  memo,
+  reference,
+  metadata
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, account_id, amount, created_at, transfer_id, memo, reference, metadata
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64           `json:"account_id"`
+	Amount     int64           `json:"amount"`
+	TransferID *int64          `json:"transfer_id"`
+	Memo       string          `json:"memo"`
+	Reference  string          `json:"reference"`
+	Metadata   json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.Memo,
+		arg.Reference,
+		arg.Metadata,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, memo, reference, metadata FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,26 +69,37 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Memo,
+		&i.Reference,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, memo, reference, metadata FROM entries
 WHERE account_id = $1
+  AND ($2::varchar IS NULL OR reference = $2)
 ORDER BY id
-LIMIT $2
+LIMIT $4
 OFFSET $3
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID int64   `json:"account_id"`
+	Reference *string `json:"reference"`
+	Offset    int32   `json:"offset"`
+	Limit     int32   `json:"limit"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.Reference,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +112,87 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.Memo,
+			&i.Reference,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT
+  e.id,
+  e.amount,
+  e.transfer_id,
+  e.memo,
+  e.reference,
+  e.created_at,
+  COALESCE(
+    CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END,
+    0
+  )::bigint AS counterparty_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+  AND e.id > $4
+ORDER BY e.id
+LIMIT $5
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+	AfterID   int64     `json:"after_id"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListStatementEntriesRow struct {
+	ID                    int64     `json:"id"`
+	Amount                int64     `json:"amount"`
+	TransferID            *int64    `json:"transfer_id"`
+	Memo                  string    `json:"memo"`
+	Reference             string    `json:"reference"`
+	CreatedAt             time.Time `json:"created_at"`
+	CounterpartyAccountID int64     `json:"counterparty_account_id"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries,
+		arg.AccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.TransferID,
+			&i.Memo,
+			&i.Reference,
+			&i.CreatedAt,
+			&i.CounterpartyAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -13,6 +13,7 @@ func CreateRandomEntry(t *testing.T, account_id int64) Entry {
 	args := CreateEntryParams{
 		AccountID: account_id,
 		Amount:    util.RandomMoney(),
+		Metadata:  emptyMetadata,
 	}
 
 	entry, err := testQuery.CreateEntry(context.Background(), args)
@@ -66,3 +67,40 @@ func TestListEntries(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestListStatementEntries(t *testing.T) {
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+	transfer := CreateRandomTransfer(t, account1.ID, account2.ID)
+
+	entry1, err := testQuery.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  account1.ID,
+		Amount:     -transfer.Amount,
+		TransferID: &transfer.ID,
+		Metadata:   emptyMetadata,
+	})
+	require.NoError(t, err)
+	entry2 := CreateRandomEntry(t, account1.ID)
+	CreateRandomEntry(t, account2.ID)
+
+	args := ListStatementEntriesParams{
+		AccountID: account1.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+		Limit:     1,
+	}
+
+	rows, err := testQuery.ListStatementEntries(context.Background(), args)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, entry1.ID, rows[0].ID)
+	require.Equal(t, account2.ID, rows[0].CounterpartyAccountID)
+
+	args.AfterID = rows[0].ID
+	args.Limit = 5
+	rows, err = testQuery.ListStatementEntries(context.Background(), args)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, entry2.ID, rows[0].ID)
+	require.Zero(t, rows[0].CounterpartyAccountID)
+}
@@ -0,0 +1,117 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: event.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const appendEvent = `-- name: AppendEvent :one
+INSERT INTO events (
+  event_id,
+  event_type,
+  account_ids,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING seq, event_id, event_type, account_ids, payload, created_at
+`
+
+type AppendEventParams struct {
+	EventID    uuid.UUID       `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	AccountIds []int64         `json:"account_ids"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) AppendEvent(ctx context.Context, arg AppendEventParams) (Event, error) {
+	row := q.db.QueryRowContext(ctx, appendEvent,
+		arg.EventID,
+		arg.EventType,
+		pq.Array(arg.AccountIds),
+		arg.Payload,
+	)
+	var i Event
+	err := row.Scan(
+		&i.Seq,
+		&i.EventID,
+		&i.EventType,
+		pq.Array(&i.AccountIds),
+		&i.Payload,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastEventSeq = `-- name: GetLastEventSeq :one
+SELECT COALESCE(MAX(seq), 0)::bigint FROM events
+`
+
+func (q *Queries) GetLastEventSeq(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getLastEventSeq)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listEvents = `-- name: ListEvents :many
+SELECT seq, event_id, event_type, account_ids, payload, created_at FROM events
+WHERE seq > $1
+ORDER BY seq
+LIMIT $2
+`
+
+type ListEventsParams struct {
+	AfterSeq int64 `json:"after_seq"`
+	Limit    int32 `json:"limit"`
+}
+
+func (q *Queries) ListEvents(ctx context.Context, arg ListEventsParams) ([]Event, error) {
+	rows, err := q.db.QueryContext(ctx, listEvents, arg.AfterSeq, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Event{}
+	for rows.Next() {
+		var i Event
+		if err := rows.Scan(
+			&i.Seq,
+			&i.EventID,
+			&i.EventType,
+			pq.Array(&i.AccountIds),
+			&i.Payload,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockEventStream = `-- name: LockEventStream :exec
+SELECT pg_advisory_xact_lock(7271001)
+`
+
+// Serialises appends to the event stream until the calling transaction ends.
+// Sequence numbers are handed out while the lock is held, so events commit in
+// seq order and a reader resuming after a seq can never miss a lower one that
+// commits late.
+func (q *Queries) LockEventStream(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, lockEventStream)
+	return err
+}
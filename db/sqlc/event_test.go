@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestTransferAppendsEvent(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := CreateRandomAccount(t)
+	toAccount := CreateRandomAccount(t)
+
+	after, err := store.GetLastEventSeq(context.Background())
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	updated, err := store.UpdateAccountTx(context.Background(), UpdateAccountTxParams{
+		UpdateAccountParams: UpdateAccountParams{ID: toAccount.ID, Balance: 500},
+		SetBy:               "auditor",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), updated.Balance)
+
+	events, err := store.ListEvents(context.Background(), ListEventsParams{AfterSeq: after, Limit: 100})
+	require.NoError(t, err)
+
+	var transferEvent, balanceEvent *Event
+	for i := range events {
+		if i > 0 {
+			require.Greater(t, events[i].Seq, events[i-1].Seq)
+		}
+		if events[i].EventType == util.TransferCreatedEvent && transferEvent == nil {
+			var payload TransferEvent
+			require.NoError(t, json.Unmarshal(events[i].Payload, &payload))
+			if payload.Transfer.ID == result.Transfer.ID {
+				transferEvent = &events[i]
+				require.GreaterOrEqual(t, len(payload.Entries), 2)
+				require.Contains(t, payload.Balances, AccountBalance{AccountID: toAccount.ID, Balance: result.ToAccount.Balance})
+			}
+		}
+		if events[i].EventType == util.BalanceSetEvent && balanceEvent == nil {
+			var payload BalanceSetEvent
+			require.NoError(t, json.Unmarshal(events[i].Payload, &payload))
+			if payload.AccountID == toAccount.ID {
+				balanceEvent = &events[i]
+				require.Equal(t, result.ToAccount.Balance, payload.PreviousBalance)
+				require.Equal(t, int64(500), payload.Balance)
+				require.Equal(t, "auditor", payload.SetBy)
+			}
+		}
+	}
+
+	require.NotNil(t, transferEvent)
+	require.Subset(t, transferEvent.AccountIds, []int64{fromAccount.ID, toAccount.ID})
+	require.NotNil(t, balanceEvent)
+	require.Greater(t, balanceEvent.Seq, transferEvent.Seq)
+}
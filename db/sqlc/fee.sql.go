@@ -0,0 +1,129 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: fee.sql
+
+package db
+
+import (
+	"context"
+)
+
+const getFeeSchedule = `-- name: GetFeeSchedule :one
+SELECT id, currency, tier, flat_fee, rate_ppm, min_fee, max_fee, created_at, updated_at FROM fee_schedules
+WHERE currency = $1 AND tier = $2
+LIMIT 1
+`
+
+type GetFeeScheduleParams struct {
+	Currency string `json:"currency"`
+	Tier     string `json:"tier"`
+}
+
+func (q *Queries) GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRowContext(ctx, getFeeSchedule, arg.Currency, arg.Tier)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Tier,
+		&i.FlatFee,
+		&i.RatePpm,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listFeeSchedules = `-- name: ListFeeSchedules :many
+SELECT id, currency, tier, flat_fee, rate_ppm, min_fee, max_fee, created_at, updated_at FROM fee_schedules
+ORDER BY currency, tier
+`
+
+func (q *Queries) ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeSchedules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FeeSchedule{}
+	for rows.Next() {
+		var i FeeSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Currency,
+			&i.Tier,
+			&i.FlatFee,
+			&i.RatePpm,
+			&i.MinFee,
+			&i.MaxFee,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertFeeSchedule = `-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency,
+  tier,
+  flat_fee,
+  rate_ppm,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) ON CONFLICT (currency, tier) DO UPDATE
+SET
+  flat_fee = EXCLUDED.flat_fee,
+  rate_ppm = EXCLUDED.rate_ppm,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  updated_at = now()
+RETURNING id, currency, tier, flat_fee, rate_ppm, min_fee, max_fee, created_at, updated_at
+`
+
+type UpsertFeeScheduleParams struct {
+	Currency string `json:"currency"`
+	Tier     string `json:"tier"`
+	FlatFee  int64  `json:"flat_fee"`
+	RatePpm  int64  `json:"rate_ppm"`
+	MinFee   int64  `json:"min_fee"`
+	MaxFee   *int64 `json:"max_fee"`
+}
+
+func (q *Queries) UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error) {
+	row := q.db.QueryRowContext(ctx, upsertFeeSchedule,
+		arg.Currency,
+		arg.Tier,
+		arg.FlatFee,
+		arg.RatePpm,
+		arg.MinFee,
+		arg.MaxFee,
+	)
+	var i FeeSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.Currency,
+		&i.Tier,
+		&i.FlatFee,
+		&i.RatePpm,
+		&i.MinFee,
+		&i.MaxFee,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+
+	tier := util.RandomString(8)
+	_, err := testQuery.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		Currency: account1.Currency,
+		Tier:     tier,
+		FlatFee:  1,
+		RatePpm:  100_000,
+	})
+	require.NoError(t, err)
+
+	account1, err = testQuery.UpdateAccountTier(context.Background(), UpdateAccountTierParams{
+		ID:   account1.ID,
+		Tier: tier,
+	})
+	require.NoError(t, err)
+
+	revenue, err := testQuery.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  util.FeeRevenuePurpose,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+
+	amount := int64(10)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(2), result.Fee.Total)
+	require.Equal(t, int64(2), result.Transfer.Fee)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-2), result.FeeEntry.Amount)
+	require.Equal(t, account1.Balance-amount-2, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	updatedRevenue, err := testQuery.GetAccount(context.Background(), revenue.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, updatedRevenue.Balance, revenue.Balance+2)
+}
@@ -0,0 +1,410 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  principal,
+  amount_micros
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, accrual_date) DO NOTHING
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID    int64     `json:"account_id"`
+	AccrualDate  time.Time `json:"accrual_date"`
+	Principal    int64     `json:"principal"`
+	AmountMicros int64     `json:"amount_micros"`
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Principal,
+		arg.AmountMicros,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createInterestPosting = `-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period,
+  amount,
+  carry_micros
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (account_id, period) DO NOTHING
+RETURNING account_id, period, amount, carry_micros, transfer_id, created_at
+`
+
+type CreateInterestPostingParams struct {
+	AccountID   int64     `json:"account_id"`
+	Period      time.Time `json:"period"`
+	Amount      int64     `json:"amount"`
+	CarryMicros int64     `json:"carry_micros"`
+}
+
+func (q *Queries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, createInterestPosting,
+		arg.AccountID,
+		arg.Period,
+		arg.Amount,
+		arg.CarryMicros,
+	)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.Period,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createInterestProduct = `-- name: CreateInterestProduct :one
+INSERT INTO interest_products (
+  name,
+  currency,
+  annual_rate_ppm,
+  day_count,
+  compounding
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, name, currency, annual_rate_ppm, day_count, compounding, created_at
+`
+
+type CreateInterestProductParams struct {
+	Name          string `json:"name"`
+	Currency      string `json:"currency"`
+	AnnualRatePpm int64  `json:"annual_rate_ppm"`
+	DayCount      string `json:"day_count"`
+	Compounding   string `json:"compounding"`
+}
+
+func (q *Queries) CreateInterestProduct(ctx context.Context, arg CreateInterestProductParams) (InterestProduct, error) {
+	row := q.db.QueryRowContext(ctx, createInterestProduct,
+		arg.Name,
+		arg.Currency,
+		arg.AnnualRatePpm,
+		arg.DayCount,
+		arg.Compounding,
+	)
+	var i InterestProduct
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.AnnualRatePpm,
+		&i.DayCount,
+		&i.Compounding,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestPosting = `-- name: GetInterestPosting :one
+SELECT account_id, period, amount, carry_micros, transfer_id, created_at FROM interest_postings
+WHERE account_id = $1 AND period = $2
+LIMIT 1
+`
+
+type GetInterestPostingParams struct {
+	AccountID int64     `json:"account_id"`
+	Period    time.Time `json:"period"`
+}
+
+func (q *Queries) GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, getInterestPosting, arg.AccountID, arg.Period)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.Period,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestProduct = `-- name: GetInterestProduct :one
+SELECT id, name, currency, annual_rate_ppm, day_count, compounding, created_at FROM interest_products
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetInterestProduct(ctx context.Context, id int64) (InterestProduct, error) {
+	row := q.db.QueryRowContext(ctx, getInterestProduct, id)
+	var i InterestProduct
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Currency,
+		&i.AnnualRatePpm,
+		&i.DayCount,
+		&i.Compounding,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLatestInterestAccrualDate = `-- name: GetLatestInterestAccrualDate :one
+SELECT accrual_date FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestInterestAccrualDate(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLatestInterestAccrualDate)
+	var accrual_date time.Time
+	err := row.Scan(&accrual_date)
+	return accrual_date, err
+}
+
+const getLatestInterestPosting = `-- name: GetLatestInterestPosting :one
+SELECT account_id, period, amount, carry_micros, transfer_id, created_at FROM interest_postings
+WHERE account_id = $1
+ORDER BY period DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestInterestPosting(ctx context.Context, accountID int64) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, getLatestInterestPosting, accountID)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.Period,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountsWithUnpostedInterest = `-- name: ListAccountsWithUnpostedInterest :many
+SELECT DISTINCT account_id FROM interest_accruals
+WHERE posting_period IS NULL
+  AND accrual_date < $1
+  AND account_id > $2
+ORDER BY account_id
+LIMIT $3
+`
+
+type ListAccountsWithUnpostedInterestParams struct {
+	BeforeDate time.Time `json:"before_date"`
+	AfterID    int64     `json:"after_id"`
+	ChunkSize  int32     `json:"chunk_size"`
+}
+
+func (q *Queries) ListAccountsWithUnpostedInterest(ctx context.Context, arg ListAccountsWithUnpostedInterestParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsWithUnpostedInterest, arg.BeforeDate, arg.AfterID, arg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.account_type, a.interest_product_id, a.tier, a.nickname, a.account_number, a.frozen, p.id, p.name, p.currency, p.annual_rate_ppm, p.day_count, p.compounding, p.created_at
+FROM accounts a
+JOIN interest_products p ON p.id = a.interest_product_id
+WHERE a.account_type = 'savings' AND a.id > $1
+ORDER BY a.id
+LIMIT $2
+`
+
+type ListInterestBearingAccountsParams struct {
+	AfterID   int64 `json:"after_id"`
+	ChunkSize int32 `json:"chunk_size"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	Account         Account         `json:"account"`
+	InterestProduct InterestProduct `json:"interest_product"`
+}
+
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts, arg.AfterID, arg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(
+			&i.Account.ID,
+			&i.Account.Owner,
+			&i.Account.Balance,
+			&i.Account.Currency,
+			&i.Account.CreatedAt,
+			&i.Account.AccountType,
+			&i.Account.InterestProductID,
+			&i.Account.Tier,
+			&i.Account.Nickname,
+			&i.Account.AccountNumber,
+			&i.Account.Frozen,
+			&i.InterestProduct.ID,
+			&i.InterestProduct.Name,
+			&i.InterestProduct.Currency,
+			&i.InterestProduct.AnnualRatePpm,
+			&i.InterestProduct.DayCount,
+			&i.InterestProduct.Compounding,
+			&i.InterestProduct.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestProducts = `-- name: ListInterestProducts :many
+SELECT id, name, currency, annual_rate_ppm, day_count, compounding, created_at FROM interest_products
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListInterestProductsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListInterestProducts(ctx context.Context, arg ListInterestProductsParams) ([]InterestProduct, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestProducts, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestProduct{}
+	for rows.Next() {
+		var i InterestProduct
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Currency,
+			&i.AnnualRatePpm,
+			&i.DayCount,
+			&i.Compounding,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markInterestPosted = `-- name: MarkInterestPosted :execrows
+UPDATE interest_accruals
+SET posting_period = $1::date
+WHERE account_id = $2
+  AND posting_period IS NULL
+  AND accrual_date < $3
+`
+
+type MarkInterestPostedParams struct {
+	Period     time.Time `json:"period"`
+	AccountID  int64     `json:"account_id"`
+	BeforeDate time.Time `json:"before_date"`
+}
+
+func (q *Queries) MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markInterestPosted, arg.Period, arg.AccountID, arg.BeforeDate)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setInterestPostingTransfer = `-- name: SetInterestPostingTransfer :one
+UPDATE interest_postings
+SET transfer_id = $1
+WHERE account_id = $2 AND period = $3
+RETURNING account_id, period, amount, carry_micros, transfer_id, created_at
+`
+
+type SetInterestPostingTransferParams struct {
+	TransferID *int64    `json:"transfer_id"`
+	AccountID  int64     `json:"account_id"`
+	Period     time.Time `json:"period"`
+}
+
+func (q *Queries) SetInterestPostingTransfer(ctx context.Context, arg SetInterestPostingTransferParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, setInterestPostingTransfer, arg.TransferID, arg.AccountID, arg.Period)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.Period,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const sumUnpostedInterest = `-- name: SumUnpostedInterest :one
+SELECT COALESCE(SUM(amount_micros), 0)::bigint AS total FROM interest_accruals
+WHERE account_id = $1
+  AND posting_period IS NULL
+  AND accrual_date < $2
+`
+
+type SumUnpostedInterestParams struct {
+	AccountID  int64     `json:"account_id"`
+	BeforeDate time.Time `json:"before_date"`
+}
+
+func (q *Queries) SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumUnpostedInterest, arg.AccountID, arg.BeforeDate)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := CreateRandomAccount(t)
+
+	system, err := testQuery.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  util.InterestExpensePurpose,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	may := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	june := may.AddDate(0, 1, 0)
+
+	accrue := func(day time.Time, micros int64) {
+		n, err := testQuery.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  day,
+			Principal:    account.Balance,
+			AmountMicros: micros,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), n)
+	}
+
+	accrue(may, 1_200_000)
+	accrue(may.AddDate(0, 0, 1), 1_500_000)
+	accrue(june, 400_000)
+
+	args := PostInterestTxParams{
+		AccountID:       account.ID,
+		SystemAccountID: system.ID,
+		Period:          may,
+	}
+
+	result, err := store.PostInterestTx(context.Background(), args)
+	require.NoError(t, err)
+	require.False(t, result.AlreadyPosted)
+	require.Equal(t, int64(2), result.Posting.Amount)
+	require.Equal(t, int64(700_000), result.Posting.CarryMicros)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, system.ID, result.Transfer.Transfer.FromAccountID)
+	require.Equal(t, account.Balance+2, result.Transfer.ToAccount.Balance)
+
+	again, err := store.PostInterestTx(context.Background(), args)
+	require.NoError(t, err)
+	require.True(t, again.AlreadyPosted)
+	require.Nil(t, again.Transfer)
+
+	// June only accrued 0.4, but the carried 0.7 tips it over one unit.
+	args.Period = june
+	result, err = store.PostInterestTx(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Posting.Amount)
+	require.Equal(t, int64(100_000), result.Posting.CarryMicros)
+}
+
+func TestGetLatestInterestAccrualDate(t *testing.T) {
+	account := CreateRandomAccount(t)
+	day := time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := testQuery.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:   account.ID,
+		AccrualDate: day,
+		Principal:   account.Balance,
+	})
+	require.NoError(t, err)
+
+	latest, err := testQuery.GetLatestInterestAccrualDate(context.Background())
+	require.NoError(t, err)
+	require.False(t, latest.Before(day))
+}
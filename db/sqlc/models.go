@@ -5,24 +5,146 @@
 package db
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
+	ID                int64     `json:"id"`
+	Owner             string    `json:"owner"`
+	Balance           int64     `json:"balance"`
+	Currency          string    `json:"currency"`
+	CreatedAt         time.Time `json:"created_at"`
+	AccountType       string    `json:"account_type"`
+	InterestProductID *int64    `json:"interest_product_id"`
+	Tier              string    `json:"tier"`
+	Nickname          string    `json:"nickname"`
+	AccountNumber     string    `json:"account_number"`
+	Frozen            bool      `json:"frozen"`
+}
+
+type AccountBalanceSnapshot struct {
+	AccountID    int64     `json:"account_id"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	Balance      int64     `json:"balance"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type AccountMember struct {
+	AccountID  int64     `json:"account_id"`
+	Username   string    `json:"username"`
+	Permission string    `json:"permission"`
+	InvitedBy  string    `json:"invited_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Entry struct {
+	ID         int64           `json:"id"`
+	AccountID  int64           `json:"account_id"`
+	Amount     int64           `json:"amount"`
+	CreatedAt  time.Time       `json:"created_at"`
+	TransferID *int64          `json:"transfer_id"`
+	Memo       string          `json:"memo"`
+	Reference  string          `json:"reference"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+type Event struct {
+	Seq        int64           `json:"seq"`
+	EventID    uuid.UUID       `json:"event_id"`
+	EventType  string          `json:"event_type"`
+	AccountIds []int64         `json:"account_ids"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+type FeeSchedule struct {
 	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
+	Tier      string    `json:"tier"`
+	FlatFee   int64     `json:"flat_fee"`
+	RatePpm   int64     `json:"rate_ppm"`
+	MinFee    int64     `json:"min_fee"`
+	MaxFee    *int64    `json:"max_fee"`
 	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
-type Entry struct {
+type InterestAccrual struct {
+	AccountID     int64      `json:"account_id"`
+	AccrualDate   time.Time  `json:"accrual_date"`
+	Principal     int64      `json:"principal"`
+	AmountMicros  int64      `json:"amount_micros"`
+	PostingPeriod *time.Time `json:"posting_period"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type InterestPosting struct {
+	AccountID   int64     `json:"account_id"`
+	Period      time.Time `json:"period"`
+	Amount      int64     `json:"amount"`
+	CarryMicros int64     `json:"carry_micros"`
+	TransferID  *int64    `json:"transfer_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type InterestProduct struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Currency      string    `json:"currency"`
+	AnnualRatePpm int64     `json:"annual_rate_ppm"`
+	DayCount      string    `json:"day_count"`
+	Compounding   string    `json:"compounding"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type Payee struct {
 	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
 	AccountID int64     `json:"account_id"`
-	Amount    int64     `json:"amount"`
+	Nickname  string    `json:"nickname"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type PaymentRequest struct {
+	ID            int64      `json:"id"`
+	Requester     string     `json:"requester"`
+	Payer         string     `json:"payer"`
+	ToAccountID   int64      `json:"to_account_id"`
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency"`
+	Memo          string     `json:"memo"`
+	Status        string     `json:"status"`
+	FromAccountID *int64     `json:"from_account_id"`
+	TransferID    *int64     `json:"transfer_id"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type RateLimitBucket struct {
+	Key           string    `json:"key"`
+	Tokens        float64   `json:"tokens"`
+	Allowed       bool      `json:"allowed"`
+	PeriodSeconds float64   `json:"period_seconds"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type ReconciliationRun struct {
+	ID               int64           `json:"id"`
+	Status           string          `json:"status"`
+	TriggeredBy      string          `json:"triggered_by"`
+	AccountsChecked  int64           `json:"accounts_checked"`
+	TransfersChecked int64           `json:"transfers_checked"`
+	DiscrepancyCount int64           `json:"discrepancy_count"`
+	Findings         json.RawMessage `json:"findings"`
+	Error            string          `json:"error"`
+	StartedAt        time.Time       `json:"started_at"`
+	FinishedAt       *time.Time      `json:"finished_at"`
+}
+
 type Session struct {
 	ID           string    `json:"id"`
 	Username     string    `json:"username"`
@@ -34,12 +156,56 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type SystemAccount struct {
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
 type Transfer struct {
-	ID            int64     `json:"id"`
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	Amount        int64     `json:"amount"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int64           `json:"id"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Fee           int64           `json:"fee"`
+	Memo          string          `json:"memo"`
+	Reference     string          `json:"reference"`
+	Metadata      json.RawMessage `json:"metadata"`
+}
+
+type TransferBatch struct {
+	ID             int64      `json:"id"`
+	Owner          string     `json:"owner"`
+	Format         string     `json:"format"`
+	Filename       string     `json:"filename"`
+	Status         string     `json:"status"`
+	ItemCount      int32      `json:"item_count"`
+	ValidCount     int32      `json:"valid_count"`
+	SucceededCount int32      `json:"succeeded_count"`
+	FailedCount    int32      `json:"failed_count"`
+	ApprovedBy     *string    `json:"approved_by"`
+	ApprovedAt     *time.Time `json:"approved_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Error          string     `json:"error"`
+}
+
+type TransferBatchItem struct {
+	ID            int64  `json:"id"`
+	BatchID       int64  `json:"batch_id"`
+	LineNo        int32  `json:"line_no"`
+	FromAccount   string `json:"from_account"`
+	ToAccount     string `json:"to_account"`
+	FromAccountID *int64 `json:"from_account_id"`
+	ToAccountID   *int64 `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Reference     string `json:"reference"`
+	Memo          string `json:"memo"`
+	Status        string `json:"status"`
+	Error         string `json:"error"`
+	TransferID    *int64 `json:"transfer_id"`
 }
 
 type User struct {
@@ -49,4 +215,29 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	Discoverable      bool      `json:"discoverable"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastError      string          `json:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret"`
+	CreatedAt  time.Time `json:"created_at"`
 }
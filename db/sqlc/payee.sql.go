@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: payee.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPayee = `-- name: CreatePayee :one
+INSERT INTO payees (
+  owner,
+  account_id,
+  nickname
+) VALUES (
+  $1, $2, $3
+) RETURNING id, owner, account_id, nickname, created_at
+`
+
+type CreatePayeeParams struct {
+	Owner     string `json:"owner"`
+	AccountID int64  `json:"account_id"`
+	Nickname  string `json:"nickname"`
+}
+
+func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, createPayee, arg.Owner, arg.AccountID, arg.Nickname)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Nickname,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePayee = `-- name: DeletePayee :execrows
+DELETE FROM payees
+WHERE id = $1 AND owner = $2
+`
+
+type DeletePayeeParams struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+}
+
+func (q *Queries) DeletePayee(ctx context.Context, arg DeletePayeeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePayee, arg.ID, arg.Owner)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPayee = `-- name: GetPayee :one
+SELECT id, owner, account_id, nickname, created_at FROM payees
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPayee(ctx context.Context, id int64) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, getPayee, id)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Nickname,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listPayees = `-- name: ListPayees :many
+SELECT p.id, p.nickname, p.created_at, a.account_number, a.currency, u.full_name
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+JOIN users u ON u.username = a.owner
+WHERE p.owner = $1
+ORDER BY p.nickname, p.id
+`
+
+type ListPayeesRow struct {
+	ID            int64     `json:"id"`
+	Nickname      string    `json:"nickname"`
+	CreatedAt     time.Time `json:"created_at"`
+	AccountNumber string    `json:"account_number"`
+	Currency      string    `json:"currency"`
+	FullName      string    `json:"full_name"`
+}
+
+func (q *Queries) ListPayees(ctx context.Context, owner string) ([]ListPayeesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPayees, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPayeesRow{}
+	for rows.Next() {
+		var i ListPayeesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Nickname,
+			&i.CreatedAt,
+			&i.AccountNumber,
+			&i.Currency,
+			&i.FullName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestAccountNumber(t *testing.T) {
+	account := CreateRandomAccount(t)
+	require.True(t, util.IsValidAccountNumber(account.AccountNumber))
+
+	holder, err := testQuery.GetAccountHolderByNumber(context.Background(), account.AccountNumber)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, holder.ID)
+	require.NotEmpty(t, holder.FullName)
+}
+
+func TestPayees(t *testing.T) {
+	owner := CreateRandomUser(t)
+	account := CreateRandomAccount(t)
+
+	payee, err := testQuery.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     owner.Username,
+		AccountID: account.ID,
+		Nickname:  "Landlord",
+	})
+	require.NoError(t, err)
+
+	payees, err := testQuery.ListPayees(context.Background(), owner.Username)
+	require.NoError(t, err)
+	require.Len(t, payees, 1)
+	require.Equal(t, payee.ID, payees[0].ID)
+	require.Equal(t, account.AccountNumber, payees[0].AccountNumber)
+
+	deleted, err := testQuery.DeletePayee(context.Background(), DeletePayeeParams{ID: payee.ID, Owner: "someoneelse"})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = testQuery.DeletePayee(context.Background(), DeletePayeeParams{ID: payee.ID, Owner: owner.Username})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
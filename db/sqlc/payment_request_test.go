@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func createRandomPaymentRequest(t *testing.T, expiresAt time.Time) (PaymentRequest, Account) {
+	toAccount := CreateRandomAccount(t)
+	fromAccount := CreateRandomAccount(t)
+
+	request, err := testQuery.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		Requester:   toAccount.Owner,
+		Payer:       fromAccount.Owner,
+		ToAccountID: toAccount.ID,
+		Amount:      5,
+		Currency:    toAccount.Currency,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.PaymentRequestPending, request.Status)
+
+	return request, fromAccount
+}
+
+func TestAcceptPaymentRequestTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	request, fromAccount := createRandomPaymentRequest(t, time.Now().Add(time.Hour))
+
+	n := 5
+	errs := make(chan error, n)
+	results := make(chan AcceptPaymentRequestTxResult, n)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+				RequestID:     request.ID,
+				FromAccountID: fromAccount.ID,
+			})
+			errs <- err
+			results <- result
+		}()
+	}
+
+	paid := 0
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+		result := <-results
+		require.Equal(t, util.PaymentRequestAccepted, result.Request.Status)
+		if !result.AlreadyAccepted {
+			paid++
+			require.NotNil(t, result.Transfer)
+		}
+	}
+	require.Equal(t, 1, paid)
+
+	updated, err := testQuery.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-request.Amount, updated.Balance)
+}
+
+func TestAcceptPaymentRequestTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	request, fromAccount := createRandomPaymentRequest(t, time.Now().Add(-time.Minute))
+
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+
+	expired, err := testQuery.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired, int64(1))
+
+	request, err = testQuery.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.PaymentRequestExpired, request.Status)
+
+	_, err = store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
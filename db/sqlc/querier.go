@@ -6,26 +6,134 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	AcceptPaymentRequest(ctx context.Context, arg AcceptPaymentRequestParams) (PaymentRequest, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountMember(ctx context.Context, arg AddAccountMemberParams) (AccountMember, error)
+	AppendEvent(ctx context.Context, arg AppendEventParams) (Event, error)
+	// Picks deliveries that are due and pushes their next attempt out to
+	// lease_until, so other dispatchers skip them while this one is sending. A
+	// dispatcher that dies mid-send simply lets the lease run out.
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CompleteTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	CountOwnedAccounts(ctx context.Context, owner string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateInterestProduct(ctx context.Context, arg CreateInterestProductParams) (InterestProduct, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreateReconciliationRun(ctx context.Context, triggeredBy string) (ReconciliationRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	// A bucket left alone for a whole period is full again, which is the same
+	// as having no row.
+	DeleteFullRateLimitBuckets(ctx context.Context) (int64, error)
+	DeletePayee(ctx context.Context, arg DeletePayeeParams) (int64, error)
+	DeleteWebhookSubscription(ctx context.Context, arg DeleteWebhookSubscriptionParams) (int64, error)
+	// Queues one delivery per subscription of the given users that listens for
+	// the event type. Called inside the transaction that makes the change.
+	EnqueueWebhookEvent(ctx context.Context, arg EnqueueWebhookEventParams) (int64, error)
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
+	// Stops a batch that could not be run. Rows paid before the failure stay
+	// paid; the rest are left valid and unpaid.
+	FailTransferBatch(ctx context.Context, arg FailTransferBatchParams) (TransferBatch, error)
+	FinishReconciliationRun(ctx context.Context, arg FinishReconciliationRunParams) (ReconciliationRun, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, accountNumber string) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountHolderByNumber(ctx context.Context, accountNumber string) (GetAccountHolderByNumberRow, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error)
+	GetInterestProduct(ctx context.Context, id int64) (InterestProduct, error)
+	GetLastEventSeq(ctx context.Context) (int64, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (AccountBalanceSnapshot, error)
+	GetLatestInterestAccrualDate(ctx context.Context) (time.Time, error)
+	GetLatestInterestPosting(ctx context.Context, accountID int64) (InterestPosting, error)
+	GetPayee(ctx context.Context, id int64) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	// Picks the account that receives payments addressed to a user rather than
+	// to one of their accounts: the oldest checking account in the currency,
+	// falling back to the oldest account of any other type.
+	GetReceivingAccount(ctx context.Context, arg GetReceivingAccountParams) (Account, error)
+	GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error)
 	GetSession(ctx context.Context, id string) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferBatchItemForUpdate(ctx context.Context, id int64) (TransferBatchItem, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	ListAccountLedgerTotals(ctx context.Context, arg ListAccountLedgerTotalsParams) ([]ListAccountLedgerTotalsRow, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsWithUnpostedInterest(ctx context.Context, arg ListAccountsWithUnpostedInterestParams) ([]int64, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEvents(ctx context.Context, arg ListEventsParams) ([]Event, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListInterestProducts(ctx context.Context, arg ListInterestProductsParams) ([]InterestProduct, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPayees(ctx context.Context, owner string) ([]ListPayeesRow, error)
+	// Batches approved but not yet finished, oldest approval first.
+	ListProcessingTransferBatches(ctx context.Context, limit int32) ([]TransferBatch, error)
+	ListReconciliationRuns(ctx context.Context, arg ListReconciliationRunsParams) ([]ReconciliationRun, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
+	ListTransferBatches(ctx context.Context, arg ListTransferBatchesParams) ([]TransferBatch, error)
+	ListTransferLedgerTotals(ctx context.Context, arg ListTransferLedgerTotalsParams) ([]ListTransferLedgerTotalsRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, owner string) ([]WebhookSubscription, error)
+	// Serialises appends to the event stream until the calling transaction ends.
+	// Sequence numbers are handed out while the lock is held, so events commit in
+	// seq order and a reader resuming after a seq can never miss a lower one that
+	// commits late.
+	LockEventStream(ctx context.Context) error
+	MarkInterestPosted(ctx context.Context, arg MarkInterestPostedParams) (int64, error)
+	MarkTransferBatchItemFailed(ctx context.Context, arg MarkTransferBatchItemFailedParams) (TransferBatchItem, error)
+	MarkTransferBatchItemSucceeded(ctx context.Context, arg MarkTransferBatchItemSucceededParams) (TransferBatchItem, error)
+	MarkWebhookDelivered(ctx context.Context, id int64) (WebhookDelivery, error)
+	MarkWebhookFailed(ctx context.Context, arg MarkWebhookFailedParams) (WebhookDelivery, error)
+	RemoveAccountMember(ctx context.Context, arg RemoveAccountMemberParams) (int64, error)
+	ResolvePaymentRequest(ctx context.Context, arg ResolvePaymentRequestParams) (PaymentRequest, error)
+	// Puts a dead delivery back in the queue with a fresh set of attempts.
+	RetryWebhookDelivery(ctx context.Context, arg RetryWebhookDeliveryParams) (WebhookDelivery, error)
+	// Returns no row when the account is already in the requested state.
+	SetAccountFrozen(ctx context.Context, arg SetAccountFrozenParams) (Account, error)
+	SetInterestPostingTransfer(ctx context.Context, arg SetInterestPostingTransferParams) (InterestPosting, error)
+	// Moves a pending batch to processing. It returns no row when the batch was
+	// already approved, so only one caller ever executes it.
+	StartTransferBatch(ctx context.Context, arg StartTransferBatchParams) (TransferBatch, error)
+	SumEntriesInRange(ctx context.Context, arg SumEntriesInRangeParams) (int64, error)
+	SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error)
+	SumUnpostedInterest(ctx context.Context, arg SumUnpostedInterestParams) (int64, error)
+	// Refills the bucket at key for the time since it was last used, then takes
+	// a token when a whole one is available; allowed records whether it did. A
+	// new bucket starts full. The upsert locks the row, so concurrent takes on
+	// one key queue up instead of spending the same token twice.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountNickname(ctx context.Context, arg UpdateAccountNicknameParams) (Account, error)
+	UpdateAccountTier(ctx context.Context, arg UpdateAccountTierParams) (Account, error)
+	UpdateUserDiscoverable(ctx context.Context, arg UpdateUserDiscoverableParams) (User, error)
+	UpsertBalanceSnapshots(ctx context.Context, arg UpsertBalanceSnapshotsParams) ([]int64, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,66 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: rate_limit.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteFullRateLimitBuckets = `-- name: DeleteFullRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets
+WHERE updated_at + make_interval(secs => period_seconds) < now()
+`
+
+// A bucket left alone for a whole period is full again, which is the same
+// as having no row.
+func (q *Queries) DeleteFullRateLimitBuckets(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFullRateLimitBuckets)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const takeRateLimitToken = `-- name: TakeRateLimitToken :one
+INSERT INTO rate_limit_buckets AS b (
+  key,
+  tokens,
+  allowed,
+  period_seconds,
+  updated_at
+) VALUES (
+  $1, $2::float8 - 1, true, $3::float8, now()
+)
+ON CONFLICT (key) DO UPDATE SET
+  tokens = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $2::float8 / $3::float8)
+    - CASE WHEN LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $2::float8 / $3::float8) >= 1 THEN 1 ELSE 0 END,
+  allowed = LEAST($2::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM now() - b.updated_at)::float8, 0) * $2::float8 / $3::float8) >= 1,
+  period_seconds = EXCLUDED.period_seconds,
+  updated_at = now()
+RETURNING tokens, allowed
+`
+
+type TakeRateLimitTokenParams struct {
+	Key           string  `json:"key"`
+	Burst         float64 `json:"burst"`
+	PeriodSeconds float64 `json:"period_seconds"`
+}
+
+type TakeRateLimitTokenRow struct {
+	Tokens  float64 `json:"tokens"`
+	Allowed bool    `json:"allowed"`
+}
+
+// Refills the bucket at key for the time since it was last used, then takes
+// a token when a whole one is available; allowed records whether it did. A
+// new bucket starts full. The upsert locks the row, so concurrent takes on
+// one key queue up instead of spending the same token twice.
+func (q *Queries) TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, takeRateLimitToken, arg.Key, arg.Burst, arg.PeriodSeconds)
+	var i TakeRateLimitTokenRow
+	err := row.Scan(&i.Tokens, &i.Allowed)
+	return i, err
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestTakeRateLimitToken(t *testing.T) {
+	key := "test:" + util.RandomString(12)
+	arg := TakeRateLimitTokenParams{Key: key, Burst: 3, PeriodSeconds: 3600}
+
+	for i := 2; i >= 0; i-- {
+		row, err := testQuery.TakeRateLimitToken(context.Background(), arg)
+		require.NoError(t, err)
+		require.True(t, row.Allowed)
+		require.InDelta(t, float64(i), row.Tokens, 0.01)
+	}
+
+	row, err := testQuery.TakeRateLimitToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, row.Allowed)
+	require.Less(t, row.Tokens, 1.0)
+}
+
+func TestTakeRateLimitTokenConcurrent(t *testing.T) {
+	key := "test:" + util.RandomString(12)
+	arg := TakeRateLimitTokenParams{Key: key, Burst: 5, PeriodSeconds: 3600}
+
+	n := 20
+	results := make(chan bool, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			row, err := testQuery.TakeRateLimitToken(context.Background(), arg)
+			require.NoError(t, err)
+			results <- row.Allowed
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	allowed := 0
+	for ok := range results {
+		if ok {
+			allowed++
+		}
+	}
+	require.Equal(t, 5, allowed)
+}
+
+func TestDeleteFullRateLimitBuckets(t *testing.T) {
+	key := "test:" + util.RandomString(12)
+	_, err := testQuery.TakeRateLimitToken(context.Background(), TakeRateLimitTokenParams{Key: key, Burst: 1, PeriodSeconds: 0.001})
+	require.NoError(t, err)
+
+	_, err = testDB.ExecContext(context.Background(), "SELECT pg_sleep(0.01)")
+	require.NoError(t, err)
+
+	deleted, err := testQuery.DeleteFullRateLimitBuckets(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	// The bucket was full, so taking again starts over as a new bucket.
+	row, err := testQuery.TakeRateLimitToken(context.Background(), TakeRateLimitTokenParams{Key: key, Burst: 1, PeriodSeconds: 3600})
+	require.NoError(t, err)
+	require.True(t, row.Allowed)
+}
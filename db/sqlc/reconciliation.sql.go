@@ -0,0 +1,272 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: reconciliation.sql
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+const createReconciliationRun = `-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  triggered_by
+) VALUES (
+  $1
+) RETURNING id, status, triggered_by, accounts_checked, transfers_checked, discrepancy_count, findings, error, started_at, finished_at
+`
+
+func (q *Queries) CreateReconciliationRun(ctx context.Context, triggeredBy string) (ReconciliationRun, error) {
+	row := q.db.QueryRowContext(ctx, createReconciliationRun, triggeredBy)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.AccountsChecked,
+		&i.TransfersChecked,
+		&i.DiscrepancyCount,
+		&i.Findings,
+		&i.Error,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const finishReconciliationRun = `-- name: FinishReconciliationRun :one
+UPDATE reconciliation_runs
+SET
+  status = $1,
+  accounts_checked = $2,
+  transfers_checked = $3,
+  discrepancy_count = $4,
+  findings = $5,
+  error = $6,
+  finished_at = now()
+WHERE id = $7
+RETURNING id, status, triggered_by, accounts_checked, transfers_checked, discrepancy_count, findings, error, started_at, finished_at
+`
+
+type FinishReconciliationRunParams struct {
+	Status           string          `json:"status"`
+	AccountsChecked  int64           `json:"accounts_checked"`
+	TransfersChecked int64           `json:"transfers_checked"`
+	DiscrepancyCount int64           `json:"discrepancy_count"`
+	Findings         json.RawMessage `json:"findings"`
+	Error            string          `json:"error"`
+	ID               int64           `json:"id"`
+}
+
+func (q *Queries) FinishReconciliationRun(ctx context.Context, arg FinishReconciliationRunParams) (ReconciliationRun, error) {
+	row := q.db.QueryRowContext(ctx, finishReconciliationRun,
+		arg.Status,
+		arg.AccountsChecked,
+		arg.TransfersChecked,
+		arg.DiscrepancyCount,
+		arg.Findings,
+		arg.Error,
+		arg.ID,
+	)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.AccountsChecked,
+		&i.TransfersChecked,
+		&i.DiscrepancyCount,
+		&i.Findings,
+		&i.Error,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getReconciliationRun = `-- name: GetReconciliationRun :one
+SELECT id, status, triggered_by, accounts_checked, transfers_checked, discrepancy_count, findings, error, started_at, finished_at FROM reconciliation_runs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error) {
+	row := q.db.QueryRowContext(ctx, getReconciliationRun, id)
+	var i ReconciliationRun
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.TriggeredBy,
+		&i.AccountsChecked,
+		&i.TransfersChecked,
+		&i.DiscrepancyCount,
+		&i.Findings,
+		&i.Error,
+		&i.StartedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const listAccountLedgerTotals = `-- name: ListAccountLedgerTotals :many
+SELECT
+  a.id,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id > $1
+GROUP BY a.id
+ORDER BY a.id
+LIMIT $2
+`
+
+type ListAccountLedgerTotalsParams struct {
+	AfterID   int64 `json:"after_id"`
+	ChunkSize int32 `json:"chunk_size"`
+}
+
+type ListAccountLedgerTotalsRow struct {
+	ID           int64 `json:"id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+}
+
+func (q *Queries) ListAccountLedgerTotals(ctx context.Context, arg ListAccountLedgerTotalsParams) ([]ListAccountLedgerTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountLedgerTotals, arg.AfterID, arg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountLedgerTotalsRow{}
+	for rows.Next() {
+		var i ListAccountLedgerTotalsRow
+		if err := rows.Scan(&i.ID, &i.Balance, &i.EntriesTotal); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReconciliationRuns = `-- name: ListReconciliationRuns :many
+SELECT id, status, triggered_by, accounts_checked, transfers_checked, discrepancy_count, findings, error, started_at, finished_at FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2
+`
+
+type ListReconciliationRunsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListReconciliationRuns(ctx context.Context, arg ListReconciliationRunsParams) ([]ReconciliationRun, error) {
+	rows, err := q.db.QueryContext(ctx, listReconciliationRuns, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ReconciliationRun{}
+	for rows.Next() {
+		var i ReconciliationRun
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.TriggeredBy,
+			&i.AccountsChecked,
+			&i.TransfersChecked,
+			&i.DiscrepancyCount,
+			&i.Findings,
+			&i.Error,
+			&i.StartedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferLedgerTotals = `-- name: ListTransferLedgerTotals :many
+SELECT
+  t.id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.fee,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS from_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS to_total
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+WHERE t.id > $1
+GROUP BY t.id
+ORDER BY t.id
+LIMIT $2
+`
+
+type ListTransferLedgerTotalsParams struct {
+	AfterID   int64 `json:"after_id"`
+	ChunkSize int32 `json:"chunk_size"`
+}
+
+type ListTransferLedgerTotalsRow struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	Fee           int64 `json:"fee"`
+	EntryCount    int64 `json:"entry_count"`
+	EntriesTotal  int64 `json:"entries_total"`
+	FromTotal     int64 `json:"from_total"`
+	ToTotal       int64 `json:"to_total"`
+}
+
+func (q *Queries) ListTransferLedgerTotals(ctx context.Context, arg ListTransferLedgerTotalsParams) ([]ListTransferLedgerTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferLedgerTotals, arg.AfterID, arg.ChunkSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransferLedgerTotalsRow{}
+	for rows.Next() {
+		var i ListTransferLedgerTotalsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Fee,
+			&i.EntryCount,
+			&i.EntriesTotal,
+			&i.FromTotal,
+			&i.ToTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestReconciliationRun(t *testing.T) {
+	run, err := testQuery.CreateReconciliationRun(context.Background(), util.RandomOwner())
+	require.NoError(t, err)
+	require.Equal(t, "running", run.Status)
+	require.Nil(t, run.FinishedAt)
+
+	findings := json.RawMessage(`[{"kind":"account_balance_drift","account_id":1}]`)
+	finished, err := testQuery.FinishReconciliationRun(context.Background(), FinishReconciliationRunParams{
+		ID:               run.ID,
+		Status:           "drift",
+		AccountsChecked:  3,
+		TransfersChecked: 2,
+		DiscrepancyCount: 1,
+		Findings:         findings,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "drift", finished.Status)
+	require.NotNil(t, finished.FinishedAt)
+	require.JSONEq(t, string(findings), string(finished.Findings))
+
+	got, err := testQuery.GetReconciliationRun(context.Background(), run.ID)
+	require.NoError(t, err)
+	require.Equal(t, finished.DiscrepancyCount, got.DiscrepancyCount)
+}
+
+func TestLedgerTotals(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	accounts, err := testQuery.ListAccountLedgerTotals(context.Background(), ListAccountLedgerTotalsParams{
+		AfterID:   account1.ID - 1,
+		ChunkSize: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account1.ID, accounts[0].ID)
+	require.Equal(t, account1.Balance-10, accounts[0].Balance)
+	require.Equal(t, int64(-10), accounts[0].EntriesTotal)
+
+	transfers, err := testQuery.ListTransferLedgerTotals(context.Background(), ListTransferLedgerTotalsParams{
+		AfterID:   result.Transfer.ID - 1,
+		ChunkSize: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, int64(2), transfers[0].EntryCount)
+	require.Equal(t, int64(-10), transfers[0].FromTotal)
+	require.Equal(t, int64(10), transfers[0].ToTotal)
+}
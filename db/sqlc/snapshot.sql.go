@@ -0,0 +1,130 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: snapshot.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const getLatestBalanceSnapshot = `-- name: GetLatestBalanceSnapshot :one
+SELECT account_id, snapshot_date, balance, created_at FROM account_balance_snapshots
+WHERE account_id = $1 AND snapshot_date < $2::date
+ORDER BY snapshot_date DESC
+LIMIT 1
+`
+
+type GetLatestBalanceSnapshotParams struct {
+	AccountID  int64     `json:"account_id"`
+	BeforeDate time.Time `json:"before_date"`
+}
+
+func (q *Queries) GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (AccountBalanceSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getLatestBalanceSnapshot, arg.AccountID, arg.BeforeDate)
+	var i AccountBalanceSnapshot
+	err := row.Scan(
+		&i.AccountID,
+		&i.SnapshotDate,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const sumEntriesInRange = `-- name: SumEntriesInRange :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+`
+
+type SumEntriesInRangeParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+func (q *Queries) SumEntriesInRange(ctx context.Context, arg SumEntriesInRangeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesInRange, arg.AccountID, arg.FromTime, arg.ToTime)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesSince, arg.AccountID, arg.FromTime)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const upsertBalanceSnapshots = `-- name: UpsertBalanceSnapshots :many
+INSERT INTO account_balance_snapshots (
+  account_id,
+  snapshot_date,
+  balance
+)
+SELECT
+  a.id,
+  $1::date,
+  a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries e
+    WHERE e.account_id = a.id AND e.created_at >= $2
+  ), 0)::bigint
+FROM accounts a
+WHERE a.id > $3 AND a.created_at < $2
+ORDER BY a.id
+LIMIT $4
+ON CONFLICT (account_id, snapshot_date) DO UPDATE
+SET balance = EXCLUDED.balance, created_at = now()
+RETURNING account_id
+`
+
+type UpsertBalanceSnapshotsParams struct {
+	SnapshotDate time.Time `json:"snapshot_date"`
+	Cutoff       time.Time `json:"cutoff"`
+	AfterID      int64     `json:"after_id"`
+	ChunkSize    int32     `json:"chunk_size"`
+}
+
+func (q *Queries) UpsertBalanceSnapshots(ctx context.Context, arg UpsertBalanceSnapshotsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, upsertBalanceSnapshots,
+		arg.SnapshotDate,
+		arg.Cutoff,
+		arg.AfterID,
+		arg.ChunkSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
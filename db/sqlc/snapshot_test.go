@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertBalanceSnapshotsIsIdempotent(t *testing.T) {
+	account := CreateRandomAccount(t)
+	CreateRandomEntry(t, account.ID)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+
+	args := UpsertBalanceSnapshotsParams{
+		SnapshotDate: today,
+		Cutoff:       today.AddDate(0, 0, 1),
+		AfterID:      account.ID - 1,
+		ChunkSize:    1,
+	}
+
+	for i := 0; i < 2; i++ {
+		ids, err := testQuery.UpsertBalanceSnapshots(context.Background(), args)
+		require.NoError(t, err)
+		require.Equal(t, []int64{account.ID}, ids)
+	}
+
+	snap, err := testQuery.GetLatestBalanceSnapshot(context.Background(), GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: today.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, snap.Balance)
+	require.True(t, today.Equal(snap.SnapshotDate))
+
+	_, err = testQuery.GetLatestBalanceSnapshot(context.Background(), GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: today,
+	})
+	require.Error(t, err)
+}
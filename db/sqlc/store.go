@@ -3,112 +3,350 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/ulunnuha-h/simple_bank/fee"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultMaxTxAttempts = 5
+	defaultTxBackoff     = 10 * time.Millisecond
 )
 
+// Reasons execTx retries a transaction.
+const (
+	TxRetrySerializationFailure = "serialization_failure"
+	TxRetryDeadlock             = "deadlock"
+)
+
+// txRetryObserver is told about every retry execTx makes, and about the
+// transactions it gives up on. It records nothing until SetTxRetryObserver
+// installs one, which the metrics package does when it is loaded.
+var txRetryObserver = func(reason string, exhausted bool) {}
+
+// SetTxRetryObserver installs observer to be told about transaction retries.
+// It must be called before any store is used.
+func SetTxRetryObserver(observer func(reason string, exhausted bool)) {
+	txRetryObserver = observer
+}
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, args TransferTxParams) (TransferTxResult, error)
+	PostInterestTx(ctx context.Context, args PostInterestTxParams) (PostInterestTxResult, error)
+	AcceptPaymentRequestTx(ctx context.Context, args AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error)
+	CreateTransferBatchTx(ctx context.Context, args CreateTransferBatchTxParams) (TransferBatchTxResult, error)
+	ExecuteTransferBatchItemTx(ctx context.Context, args ExecuteTransferBatchItemTxParams) (ExecuteTransferBatchItemTxResult, error)
+	CreateAccountTx(ctx context.Context, args CreateAccountTxParams) (Account, error)
+	SetAccountFrozenTx(ctx context.Context, args SetAccountFrozenParams) (SetAccountFrozenTxResult, error)
+	CreateSessionTx(ctx context.Context, args CreateSessionParams) (Session, error)
+	UpdateAccountTx(ctx context.Context, args UpdateAccountTxParams) (Account, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, bool, error)
 }
 
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+
+	maxTxAttempts int
+	txBackoff     time.Duration
 }
 
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		db:            db,
+		Queries:       New(tracedDBTX{db}),
+		maxTxAttempts: defaultMaxTxAttempts,
+		txBackoff:     defaultTxBackoff,
+	}
+}
+
+// execTx runs fn inside a database transaction opened with opts. Serialization
+// failures and deadlocks abort the whole transaction, so fn is re-run from the
+// start after a jittered backoff, up to maxTxAttempts times.
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		if err == nil || !isRetryableTxError(err) {
+			return err
+		}
+		reason := txRetryReason(err)
+
+		if attempt >= store.maxTxAttempts {
+			txRetryObserver(reason, true)
+			return fmt.Errorf("tx failed after %d attempts: %w", attempt, err)
+		}
+
+		txRetryObserver(reason, false)
+		trace.SpanFromContext(ctx).AddEvent("tx retry", trace.WithAttributes(attribute.Int("attempt", attempt), attribute.String("error", err.Error())))
+		if err := sleepCtx(ctx, txBackoff(store.txBackoff, attempt)); err != nil {
+			return err
+		}
 	}
 }
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	var tx *sql.Tx
+	err := traceTxStep(ctx, "BEGIN", func() (err error) {
+		tx, err = store.db.BeginTx(ctx, opts)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	q := New(tracedDBTX{tx})
 	err = fn(q)
 	if err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		if rbErr := traceTxStep(ctx, "ROLLBACK", tx.Rollback); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
 
-	return tx.Commit()
+	return traceTxStep(ctx, "COMMIT", tx.Commit)
+}
+
+// isRetryableTxError reports whether err is a serialization failure (40001) or
+// a deadlock (40P01), both of which are safe to retry from the beginning.
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+	return false
+}
+
+// txRetryReason names the kind of retryable error err is.
+func txRetryReason(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "deadlock_detected" {
+		return TxRetryDeadlock
+	}
+	return TxRetrySerializationFailure
+}
+
+// txBackoff doubles base for every attempt and picks a random delay in the
+// upper half of that window, so colliding transactions don't retry in lockstep.
+func txBackoff(base time.Duration, attempt int) time.Duration {
+	window := base << (attempt - 1)
+	half := window / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 type TransferTxParams struct {
-	FromAccountId int64 `json:"from_account_id"`
-	ToAccountId   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountId int64             `json:"from_account_id"`
+	ToAccountId   int64             `json:"to_account_id"`
+	Amount        int64             `json:"amount"`
+	Memo          string            `json:"memo"`
+	Reference     string            `json:"reference"`
+	Metadata      map[string]string `json:"metadata"`
+
+	// Isolation selects the transaction isolation level. The zero value uses
+	// the database default (READ COMMITTED on Postgres).
+	Isolation sql.IsolationLevel `json:"-"`
 }
 
 type TransferTxResult struct {
-	Transfer    Transfer `json:"transfer"`
-	FromAccount Account  `json:"from_account"`
-	ToAccount   Account  `json:"to_account"`
-	FromEntry   Entry    `json:"from_entry"`
-	ToEntry     Entry    `json:"to_entry"`
+	Transfer    Transfer      `json:"transfer"`
+	FromAccount Account       `json:"from_account"`
+	ToAccount   Account       `json:"to_account"`
+	FromEntry   Entry         `json:"from_entry"`
+	ToEntry     Entry         `json:"to_entry"`
+	Fee         fee.Breakdown `json:"fee"`
+	FeeEntry    *Entry        `json:"fee_entry,omitempty"`
 }
 
+// TransferTx moves Amount between two accounts and charges the sender the
+// fee configured for its currency and tier, posted to the fee revenue account.
 func (store *SQLStore) TransferTx(ctx context.Context, args TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: args.FromAccountId,
-			ToAccountID:   args.ToAccountId,
-			Amount:        args.Amount,
+	err := store.execTx(ctx, &sql.TxOptions{Isolation: args.Isolation}, func(q *Queries) error {
+		charge, err := traceStep(ctx, "TransferTx.fee", func(ctx context.Context) (*feeCharge, error) {
+			return transferFee(ctx, q, args.FromAccountId, args.Amount)
 		})
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.FromAccountId,
-			Amount:    -args.Amount,
+		result, err = traceStep(ctx, "TransferTx.post", func(ctx context.Context) (TransferTxResult, error) {
+			return transferTx(ctx, q, args, charge)
+		})
+		return err
+	})
+
+	return result, err
+}
+
+const feeMemo = "Transfer fee"
+
+var emptyMetadata = json.RawMessage("{}")
+
+// encodeMetadata turns transfer metadata into the jsonb stored on transfers
+// and entries. Missing metadata is stored as an empty object.
+func encodeMetadata(metadata map[string]string) (json.RawMessage, error) {
+	if len(metadata) == 0 {
+		return emptyMetadata, nil
+	}
+
+	return json.Marshal(metadata)
+}
+
+// transferTx records a transfer, its entries and the balance changes through
+// q, which must be bound to an open transaction. Other store methods that
+// move money call it so every movement goes through the same entries. A nil
+// charge transfers without a fee.
+func transferTx(ctx context.Context, q *Queries, args TransferTxParams, charge *feeCharge) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	var feeTotal int64
+	if charge != nil {
+		result.Fee = charge.breakdown
+		feeTotal = charge.breakdown.Total
+	}
+
+	metadata, err := encodeMetadata(args.Metadata)
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: args.FromAccountId,
+		ToAccountID:   args.ToAccountId,
+		Amount:        args.Amount,
+		Fee:           feeTotal,
+		Memo:          args.Memo,
+		Reference:     args.Reference,
+		Metadata:      metadata,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  args.FromAccountId,
+		Amount:     -args.Amount,
+		TransferID: &result.Transfer.ID,
+		Memo:       args.Memo,
+		Reference:  args.Reference,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  args.ToAccountId,
+		Amount:     args.Amount,
+		TransferID: &result.Transfer.ID,
+		Memo:       args.Memo,
+		Reference:  args.Reference,
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	entries := []Entry{result.FromEntry, result.ToEntry}
+	deltas := map[int64]int64{
+		args.FromAccountId: -args.Amount,
+	}
+	deltas[args.ToAccountId] += args.Amount
+
+	if charge != nil {
+		feeEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  args.FromAccountId,
+			Amount:     -feeTotal,
+			TransferID: &result.Transfer.ID,
+			Memo:       feeMemo,
+			Reference:  args.Reference,
+			Metadata:   emptyMetadata,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
+		result.FeeEntry = &feeEntry
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: args.ToAccountId,
-			Amount:    args.Amount,
+		revenueEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  charge.revenueAccountID,
+			Amount:     feeTotal,
+			TransferID: &result.Transfer.ID,
+			Memo:       feeMemo,
+			Reference:  args.Reference,
+			Metadata:   emptyMetadata,
 		})
 		if err != nil {
-			return err
+			return result, err
+		}
+
+		deltas[args.FromAccountId] -= feeTotal
+		deltas[charge.revenueAccountID] += feeTotal
+		entries = append(entries, feeEntry, revenueEntry)
+	}
+
+	// Rows are always locked in ascending id order so that two transfers
+	// touching the same accounts can't deadlock each other.
+	ids := make([]int64, 0, len(deltas))
+	for id := range deltas {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	balances := make([]AccountBalance, 0, len(ids))
+	for _, id := range ids {
+		account, err := transferMoney(ctx, q, id, deltas[id])
+		if err != nil {
+			return result, err
 		}
+		balances = append(balances, AccountBalance{AccountID: id, Balance: account.Balance})
 
-		if args.FromAccountId < args.ToAccountId {
-			result.FromAccount, err = transferMoney(ctx, q, args.FromAccountId, -args.Amount)
-			if err != nil {
-				return err
-			}
-			result.ToAccount, err = transferMoney(ctx, q, args.ToAccountId, args.Amount)
-			if err != nil {
-				return err
-			}
-		} else {
-			result.ToAccount, err = transferMoney(ctx, q, args.ToAccountId, args.Amount)
-			if err != nil {
-				return err
-			}
-			result.FromAccount, err = transferMoney(ctx, q, args.FromAccountId, -args.Amount)
-			if err != nil {
-				return err
-			}
+		if id == args.FromAccountId {
+			result.FromAccount = account
+		}
+		if id == args.ToAccountId {
+			result.ToAccount = account
 		}
+	}
 
-		return nil
-	})
+	err = enqueueEvent(ctx, q, util.TransferCreatedEvent, []string{result.FromAccount.Owner, result.ToAccount.Owner}, result.Transfer)
+	if err != nil {
+		return result, err
+	}
 
+	_, err = recordEvent(ctx, q, util.TransferCreatedEvent, ids, TransferEvent{
+		Transfer: result.Transfer,
+		Entries:  entries,
+		Balances: balances,
+	})
 	return result, err
 }
 
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrAccountLimitReached = errors.New("account limit reached")
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// MaxAccounts is how many accounts the owner may hold; zero means no
+	// limit.
+	MaxAccounts int64 `json:"max_accounts"`
+}
+
+// CreateAccountTx opens an account unless its owner already holds
+// MaxAccounts of them. The owner's row stays locked from the count to the
+// insert, so concurrent requests can't both slip under the limit.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, args CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		if args.MaxAccounts > 0 {
+			if _, err := q.GetUserForUpdate(ctx, args.Owner); err != nil {
+				return err
+			}
+
+			count, err := q.CountOwnedAccounts(ctx, args.Owner)
+			if err != nil {
+				return err
+			}
+			if count >= args.MaxAccounts {
+				return ErrAccountLimitReached
+			}
+		}
+
+		var err error
+		account, err = q.CreateAccount(ctx, args.CreateAccountParams)
+		return err
+	})
+
+	return account, err
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// AccountBalance is the balance an account was left with by an event.
+type AccountBalance struct {
+	AccountID int64 `json:"account_id"`
+	Balance   int64 `json:"balance"`
+}
+
+// TransferEvent is the payload of a transfer.created ledger event: the
+// transfer, every entry it posted and the balances it left behind.
+type TransferEvent struct {
+	Transfer Transfer         `json:"transfer"`
+	Entries  []Entry          `json:"entries"`
+	Balances []AccountBalance `json:"balances"`
+}
+
+// BalanceSetEvent is the payload of an account.balance_set ledger event.
+type BalanceSetEvent struct {
+	AccountID       int64  `json:"account_id"`
+	PreviousBalance int64  `json:"previous_balance"`
+	Balance         int64  `json:"balance"`
+	SetBy           string `json:"set_by"`
+}
+
+// recordEvent adds an event to the ledger event stream. Like enqueueEvent it
+// must run through the q of the transaction making the change. It holds the
+// stream lock until that transaction ends, so it should come as late in the
+// transaction as possible.
+func recordEvent(ctx context.Context, q *Queries, eventType string, accountIDs []int64, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+
+	if err := q.LockEventStream(ctx); err != nil {
+		return Event{}, err
+	}
+
+	return q.AppendEvent(ctx, AppendEventParams{
+		EventID:    uuid.New(),
+		EventType:  eventType,
+		AccountIds: accountIDs,
+		Payload:    payload,
+	})
+}
+
+type UpdateAccountTxParams struct {
+	UpdateAccountParams
+	// SetBy is the admin making the correction.
+	SetBy string `json:"set_by"`
+}
+
+// UpdateAccountTx overwrites an account balance and records the change, and
+// who made it, on the event stream, since no entry explains it.
+func (store *SQLStore) UpdateAccountTx(ctx context.Context, args UpdateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		previous, err := q.GetAccountForUpdate(ctx, args.ID)
+		if err != nil {
+			return err
+		}
+
+		account, err = q.UpdateAccount(ctx, args.UpdateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = recordEvent(ctx, q, util.BalanceSetEvent, []int64{account.ID}, BalanceSetEvent{
+			AccountID:       account.ID,
+			PreviousBalance: previous.Balance,
+			Balance:         account.Balance,
+			SetBy:           args.SetBy,
+		})
+		return err
+	})
+
+	return account, err
+}
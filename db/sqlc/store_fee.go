@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/ulunnuha-h/simple_bank/fee"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// Schedule converts the stored row into the shape the fee package computes with.
+func (s FeeSchedule) Schedule() fee.Schedule {
+	return fee.Schedule{
+		Flat:    s.FlatFee,
+		RatePPM: s.RatePpm,
+		Min:     s.MinFee,
+		Max:     s.MaxFee,
+	}
+}
+
+// QuoteFee returns the fee the owner of account pays to send amount. Accounts
+// whose currency and tier have no schedule transfer for free.
+func QuoteFee(ctx context.Context, q Querier, account Account, amount int64) (fee.Breakdown, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		Currency: account.Currency,
+		Tier:     account.Tier,
+	})
+	if err == sql.ErrNoRows {
+		return fee.Breakdown{}, nil
+	}
+	if err != nil {
+		return fee.Breakdown{}, err
+	}
+
+	return fee.Compute(schedule.Schedule(), amount), nil
+}
+
+// feeCharge is a fee taken from the sender of a transfer and the system
+// account that collects it.
+type feeCharge struct {
+	breakdown        fee.Breakdown
+	revenueAccountID int64
+}
+
+func transferFee(ctx context.Context, q *Queries, fromAccountID int64, amount int64) (*feeCharge, error) {
+	from, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown, err := QuoteFee(ctx, q, from, amount)
+	if err != nil || breakdown.Total <= 0 {
+		return nil, err
+	}
+
+	revenue, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+		Purpose:  util.FeeRevenuePurpose,
+		Currency: from.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &feeCharge{
+		breakdown:        breakdown,
+		revenueAccountID: revenue.ID,
+	}, nil
+}
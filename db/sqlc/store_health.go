@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PoolConfig sets the connection pool limits of a sql.DB. Zero values keep
+// the database/sql defaults.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Connect opens a connection pool and pings it until the database answers,
+// waiting retryInterval after the first failure and doubling it each time,
+// for up to attempts pings.
+func Connect(ctx context.Context, driver string, source string, pool PoolConfig, attempts int, retryInterval time.Duration) (*sql.DB, error) {
+	conn, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if pool.MaxOpenConns > 0 {
+		conn.SetMaxOpenConns(pool.MaxOpenConns)
+	}
+	if pool.MaxIdleConns > 0 {
+		conn.SetMaxIdleConns(pool.MaxIdleConns)
+	}
+	if pool.ConnMaxLifetime > 0 {
+		conn.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		conn.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+
+	attempts = max(attempts, 1)
+	for attempt := 1; ; attempt++ {
+		err = conn.PingContext(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		slog.Warn("database not reachable", slog.Int("attempt", attempt), slog.Int("attempts", attempts), slog.Any("error", err))
+		select {
+		case <-ctx.Done():
+			conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+		retryInterval *= 2
+	}
+
+	conn.Close()
+	return nil, fmt.Errorf("cannot reach database after %d attempts: %w", attempts, err)
+}
+
+// Ping checks that the database is reachable.
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// MigrationVersion returns the schema version recorded by golang-migrate and
+// whether the last migration failed halfway.
+func (store *SQLStore) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	var version int64
+	var dirty bool
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	return version, dirty, err
+}
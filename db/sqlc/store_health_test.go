@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnect(t *testing.T) {
+	conn, err := Connect(context.Background(), viper.GetString("DB_DRIVER"), viper.GetString("DB_SOURCE"), PoolConfig{MaxOpenConns: 5}, 1, 0)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.Equal(t, 5, conn.Stats().MaxOpenConnections)
+}
+
+func TestConnectGivesUp(t *testing.T) {
+	start := time.Now()
+	_, err := Connect(context.Background(), "postgres", "postgresql://root@127.0.0.1:1/simple_bank?sslmode=disable&connect_timeout=1", PoolConfig{}, 3, 10*time.Millisecond)
+	require.ErrorContains(t, err, "after 3 attempts")
+	// Two waits: 10ms then 20ms.
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestMigrationVersion(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	require.NoError(t, store.Ping(context.Background()))
+
+	version, dirty, err := store.MigrationVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, dirty)
+	require.Positive(t, version)
+}
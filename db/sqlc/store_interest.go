@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MicrosPerUnit is how many accrual micros make up one minor currency unit.
+// Interest accrues in micros so that sub-cent amounts aren't lost day to day.
+const MicrosPerUnit = 1_000_000
+
+type PostInterestTxParams struct {
+	AccountID       int64     `json:"account_id"`
+	SystemAccountID int64     `json:"system_account_id"`
+	Period          time.Time `json:"period"`
+}
+
+type PostInterestTxResult struct {
+	Posting       InterestPosting   `json:"posting"`
+	Transfer      *TransferTxResult `json:"transfer"`
+	AlreadyPosted bool              `json:"already_posted"`
+}
+
+// PostInterestTx pays out every unposted accrual dated before the end of
+// Period, plus the carry left over by the previous posting. Whole minor units
+// are transferred from the system account; the sub-unit remainder is kept as
+// the new carry. Posting the same period twice is a no-op.
+func (store *SQLStore) PostInterestTx(ctx context.Context, args PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		result = PostInterestTxResult{}
+		periodEnd := args.Period.AddDate(0, 1, 0)
+		key := GetInterestPostingParams{
+			AccountID: args.AccountID,
+			Period:    args.Period,
+		}
+
+		existing, err := q.GetInterestPosting(ctx, key)
+		if err == nil {
+			result.Posting = existing
+			result.AlreadyPosted = true
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		var carry int64
+		last, err := q.GetLatestInterestPosting(ctx, args.AccountID)
+		if err == nil {
+			carry = last.CarryMicros
+		} else if err != sql.ErrNoRows {
+			return err
+		}
+
+		accrued, err := q.SumUnpostedInterest(ctx, SumUnpostedInterestParams{
+			AccountID:  args.AccountID,
+			BeforeDate: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		total := carry + accrued
+		amount := total / MicrosPerUnit
+
+		posting, err := q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID:   args.AccountID,
+			Period:      args.Period,
+			Amount:      amount,
+			CarryMicros: total - amount*MicrosPerUnit,
+		})
+		if err == sql.ErrNoRows {
+			// A concurrent run inserted the posting first.
+			result.Posting, err = q.GetInterestPosting(ctx, key)
+			result.AlreadyPosted = true
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if amount > 0 {
+			transfer, err := transferTx(ctx, q, TransferTxParams{
+				FromAccountId: args.SystemAccountID,
+				ToAccountId:   args.AccountID,
+				Amount:        amount,
+				Memo:          "Interest for " + args.Period.Format("January 2006"),
+				Reference:     fmt.Sprintf("interest-%d-%s", args.AccountID, args.Period.Format("2006-01")),
+			}, nil)
+			if err != nil {
+				return err
+			}
+			result.Transfer = &transfer
+
+			posting, err = q.SetInterestPostingTransfer(ctx, SetInterestPostingTransferParams{
+				TransferID: &transfer.Transfer.ID,
+				AccountID:  args.AccountID,
+				Period:     args.Period,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.MarkInterestPosted(ctx, MarkInterestPostedParams{
+			Period:     args.Period,
+			AccountID:  args.AccountID,
+			BeforeDate: periodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Posting = posting
+		return nil
+	})
+
+	return result, err
+}
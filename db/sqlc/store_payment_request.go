@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var (
+	ErrPaymentRequestNotPending = errors.New("payment request is no longer pending")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+)
+
+type AcceptPaymentRequestTxParams struct {
+	RequestID     int64 `json:"request_id"`
+	FromAccountID int64 `json:"from_account_id"`
+}
+
+type AcceptPaymentRequestTxResult struct {
+	Request         PaymentRequest    `json:"request"`
+	Transfer        *TransferTxResult `json:"transfer,omitempty"`
+	AlreadyAccepted bool              `json:"already_accepted"`
+}
+
+// AcceptPaymentRequestTx pays a pending request from FromAccountID. The
+// request row is locked first, so of two concurrent accepts one pays and the
+// other sees the request already accepted and returns it unchanged.
+func (store *SQLStore) AcceptPaymentRequestTx(ctx context.Context, args AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error) {
+	var result AcceptPaymentRequestTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		result = AcceptPaymentRequestTxResult{}
+
+		request, err := q.GetPaymentRequestForUpdate(ctx, args.RequestID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case request.Status == util.PaymentRequestAccepted:
+			result.Request = request
+			result.AlreadyAccepted = true
+			return nil
+		case request.Status != util.PaymentRequestPending:
+			return ErrPaymentRequestNotPending
+		case !time.Now().Before(request.ExpiresAt):
+			return ErrPaymentRequestExpired
+		}
+
+		transferArgs := TransferTxParams{
+			FromAccountId: args.FromAccountID,
+			ToAccountId:   request.ToAccountID,
+			Amount:        request.Amount,
+			Memo:          request.Memo,
+			Reference:     fmt.Sprintf("payment-request-%d", request.ID),
+		}
+
+		charge, err := transferFee(ctx, q, transferArgs.FromAccountId, transferArgs.Amount)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := transferTx(ctx, q, transferArgs, charge)
+		if err != nil {
+			return err
+		}
+		result.Transfer = &transfer
+
+		result.Request, err = q.AcceptPaymentRequest(ctx, AcceptPaymentRequestParams{
+			ID:            request.ID,
+			FromAccountID: &args.FromAccountID,
+			TransferID:    &transfer.Transfer.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -2,7 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/stretchr/testify/require"
 	"github.com/ulunnuha-h/simple_bank/util"
@@ -58,6 +64,8 @@ func TestTransaction(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.NotNil(t, fromEntry.TransferID)
+		require.Equal(t, transfer.ID, *fromEntry.TransferID)
 		require.NotEmpty(t, fromEntry.CreatedAt)
 		require.NotEmpty(t, fromEntry.ID)
 
@@ -68,6 +76,8 @@ func TestTransaction(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.NotNil(t, toEntry.TransferID)
+		require.Equal(t, transfer.ID, *toEntry.TransferID)
 		require.NotEmpty(t, toEntry.CreatedAt)
 		require.NotEmpty(t, toEntry.ID)
 
@@ -150,3 +160,150 @@ func TestTransactionDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, resultAccount1.Balance)
 	require.Equal(t, account2.Balance, resultAccount2.Balance)
 }
+
+// txRetries counts the retries execTx reports for the rest of the test, by
+// reason.
+type txRetries struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func observeTxRetries(t *testing.T) *txRetries {
+	retries := &txRetries{counts: make(map[string]int)}
+	previous := txRetryObserver
+	SetTxRetryObserver(func(reason string, exhausted bool) {
+		retries.mu.Lock()
+		defer retries.mu.Unlock()
+		retries.counts[reason]++
+		previous(reason, exhausted)
+	})
+	t.Cleanup(func() { SetTxRetryObserver(previous) })
+	return retries
+}
+
+func (r *txRetries) count(reason string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.counts[reason]
+}
+
+func TestTransactionForcedDeadlockIsRetried(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+
+	var amount int64 = 10
+	retries := observeTxRetries(t)
+
+	// Both transactions lock their first account, wait for each other, then
+	// reach for the other one. Postgres aborts one of them with 40P01 and the
+	// retry has to finish the transfer.
+	var locked sync.WaitGroup
+	locked.Add(2)
+
+	crossed := func(fromID, toID int64) error {
+		attempts := 0
+		return store.execTx(context.Background(), nil, func(q *Queries) error {
+			attempts++
+			if _, err := transferMoney(context.Background(), q, fromID, -amount); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				locked.Done()
+				locked.Wait()
+			}
+			_, err := transferMoney(context.Background(), q, toID, amount)
+			return err
+		})
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- crossed(account1.ID, account2.ID) }()
+	go func() { errs <- crossed(account2.ID, account1.ID) }()
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	require.Greater(t, retries.count(TxRetryDeadlock), 0)
+
+	resultAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	resultAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, resultAccount1.Balance)
+	require.Equal(t, account2.Balance, resultAccount2.Balance)
+}
+
+func TestTransactionSerializableCrossed(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	store.maxTxAttempts = 20
+
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+
+	var amount int64 = 10
+	n := 10
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		fromAccountId := account1.ID
+		toAccountId := account2.ID
+		if i%2 == 1 {
+			fromAccountId = account2.ID
+			toAccountId = account1.ID
+		}
+
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountId: fromAccountId,
+				ToAccountId:   toAccountId,
+				Amount:        amount,
+				Isolation:     sql.LevelSerializable,
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	resultAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	resultAccount2, err := store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, resultAccount1.Balance)
+	require.Equal(t, account2.Balance, resultAccount2.Balance)
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	retries := observeTxRetries(t)
+
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40001"}))
+	require.True(t, isRetryableTxError(&pq.Error{Code: "40P01"}))
+	require.True(t, isRetryableTxError(fmt.Errorf("tx err: %w, rb err: %v", &pq.Error{Code: "40P01"}, sql.ErrTxDone)))
+	require.False(t, isRetryableTxError(&pq.Error{Code: "23505"}))
+	require.False(t, isRetryableTxError(sql.ErrNoRows))
+
+	// Only execTx counts; asking about an error is not a retry.
+	require.Zero(t, retries.count(TxRetryDeadlock))
+	require.Zero(t, retries.count(TxRetrySerializationFailure))
+	require.Equal(t, TxRetryDeadlock, txRetryReason(&pq.Error{Code: "40P01"}))
+	require.Equal(t, TxRetrySerializationFailure, txRetryReason(&pq.Error{Code: "40001"}))
+}
+
+func TestTxBackoff(t *testing.T) {
+	base := 10 * time.Millisecond
+	for attempt := 1; attempt <= 4; attempt++ {
+		window := base << (attempt - 1)
+		for i := 0; i < 20; i++ {
+			d := txBackoff(base, attempt)
+			require.GreaterOrEqual(t, d, window/2)
+			require.LessOrEqual(t, d, window)
+		}
+	}
+}
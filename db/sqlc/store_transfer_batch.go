@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var ErrBatchItemNotValid = errors.New("batch item is not waiting to be executed")
+
+type CreateTransferBatchTxParams struct {
+	Batch CreateTransferBatchParams       `json:"batch"`
+	Items []CreateTransferBatchItemParams `json:"items"`
+}
+
+type TransferBatchTxResult struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+}
+
+// CreateTransferBatchTx stores a batch together with all of its rows. BatchID
+// on the items is filled in from the new batch.
+func (store *SQLStore) CreateTransferBatchTx(ctx context.Context, args CreateTransferBatchTxParams) (TransferBatchTxResult, error) {
+	var result TransferBatchTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+		result = TransferBatchTxResult{}
+
+		result.Batch, err = q.CreateTransferBatch(ctx, args.Batch)
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]TransferBatchItem, 0, len(args.Items))
+		for _, itemArgs := range args.Items {
+			itemArgs.BatchID = result.Batch.ID
+			item, err := q.CreateTransferBatchItem(ctx, itemArgs)
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, item)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+type ExecuteTransferBatchItemTxParams struct {
+	ItemID   int64            `json:"item_id"`
+	Transfer TransferTxParams `json:"transfer"`
+}
+
+type ExecuteTransferBatchItemTxResult struct {
+	Item     TransferBatchItem `json:"item"`
+	Transfer TransferTxResult  `json:"transfer"`
+}
+
+// ExecuteTransferBatchItemTx makes the transfer for one valid batch row and
+// marks the row succeeded in the same transaction, so a row is never paid
+// without being recorded or paid twice.
+func (store *SQLStore) ExecuteTransferBatchItemTx(ctx context.Context, args ExecuteTransferBatchItemTxParams) (ExecuteTransferBatchItemTxResult, error) {
+	var result ExecuteTransferBatchItemTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		item, err := q.GetTransferBatchItemForUpdate(ctx, args.ItemID)
+		if err != nil {
+			return err
+		}
+		if item.Status != util.TransferBatchItemValid {
+			return ErrBatchItemNotValid
+		}
+
+		charge, err := transferFee(ctx, q, args.Transfer.FromAccountId, args.Transfer.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transferTx(ctx, q, args.Transfer, charge)
+		if err != nil {
+			return err
+		}
+
+		result.Item, err = q.MarkTransferBatchItemSucceeded(ctx, MarkTransferBatchItemSucceededParams{
+			ID:         item.ID,
+			TransferID: &result.Transfer.Transfer.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// WebhookEvent is the body posted to webhook subscribers. Every delivery of
+// the same event carries the same ID, so receivers can drop duplicates.
+type WebhookEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// enqueueEvent writes an event to the webhook outbox for every subscription
+// of owners that listens for eventType. It must run through the q of the
+// transaction making the change, so the event exists exactly when the
+// change does.
+func enqueueEvent(ctx context.Context, q *Queries, eventType string, owners []string, data any) error {
+	event := WebhookEvent{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.EnqueueWebhookEvent(ctx, EnqueueWebhookEventParams{
+		EventID:   event.ID,
+		EventType: eventType,
+		Payload:   payload,
+		Owners:    owners,
+	})
+	return err
+}
+
+type SetAccountFrozenTxResult struct {
+	Account Account `json:"account"`
+	Changed bool    `json:"changed"`
+}
+
+// SetAccountFrozenTx freezes or unfreezes an account and tells its owner.
+// Asking for the state the account is already in changes nothing and sends
+// no event.
+func (store *SQLStore) SetAccountFrozenTx(ctx context.Context, args SetAccountFrozenParams) (SetAccountFrozenTxResult, error) {
+	var result SetAccountFrozenTxResult
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+		result = SetAccountFrozenTxResult{}
+
+		result.Account, err = q.SetAccountFrozen(ctx, args)
+		if err == sql.ErrNoRows {
+			result.Account, err = q.GetAccount(ctx, args.ID)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		result.Changed = true
+
+		eventType := util.AccountUnfrozenEvent
+		if args.Frozen {
+			eventType = util.AccountFrozenEvent
+		}
+		return enqueueEvent(ctx, q, eventType, []string{result.Account.Owner}, result.Account)
+	})
+
+	return result, err
+}
+
+type sessionEvent struct {
+	SessionID string    `json:"session_id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	ExpiredAt time.Time `json:"expired_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateSessionTx records a login and tells the user about it. The refresh
+// token itself never leaves the database.
+func (store *SQLStore) CreateSessionTx(ctx context.Context, args CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, nil, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, args)
+		if err != nil {
+			return err
+		}
+
+		return enqueueEvent(ctx, q, util.SessionCreatedEvent, []string{session.Username}, sessionEvent{
+			SessionID: session.ID,
+			Username:  session.Username,
+			UserAgent: session.UserAgent,
+			ClientIp:  session.ClientIp,
+			ExpiredAt: session.ExpiredAt,
+			CreatedAt: session.CreatedAt,
+		})
+	})
+
+	return session, err
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ulunnuha-h/simple_bank/db")
+
+// tracedDBTX starts a span around every statement. Queries issued inside a
+// transaction go through it too, so each step of a Tx method shows up in
+// the trace along with BEGIN and COMMIT.
+//
+// For QueryContext and QueryRowContext the span covers only dispatch:
+// sending the statement and waiting for the first response. DBTX hands
+// back *sql.Rows and *sql.Row, which can't be wrapped, so the span ends
+// before rows are iterated or Scan runs. Time spent reading rows, and any
+// error from Rows.Err or Scan, lands on the enclosing span instead: the
+// traceStep span inside a Tx method, or the caller's request span.
+type tracedDBTX struct {
+	DBTX
+}
+
+// queryName returns the sqlc name of query, read from its "-- name: X"
+// header, or "query" for hand-written SQL.
+func queryName(query string) string {
+	header, _, _ := strings.Cut(query, "\n")
+	rest, ok := strings.CutPrefix(header, "-- name: ")
+	if !ok {
+		return "query"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := queryName(query)
+	return tracer.Start(ctx, "db."+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", query),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	result, err := t.DBTX.ExecContext(ctx, query, args...)
+	endSpan(span, err)
+	return result, err
+}
+
+func (t tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	stmt, err := t.DBTX.PrepareContext(ctx, query)
+	endSpan(span, err)
+	return stmt, err
+}
+
+// QueryContext ends its span once the first response arrives; see tracedDBTX.
+func (t tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := t.DBTX.QueryContext(ctx, query, args...)
+	endSpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext ends its span once the query has been dispatched, so the
+// span records errors from running the query but not from Scan; see
+// tracedDBTX.
+func (t tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	row := t.DBTX.QueryRowContext(ctx, query, args...)
+	endSpan(span, row.Err())
+	return row
+}
+
+// traceTxStep runs one step of a transaction's lifecycle, such as BEGIN or
+// COMMIT, in its own span.
+func traceTxStep(ctx context.Context, name string, fn func() error) error {
+	_, span := tracer.Start(ctx, "db."+name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "postgresql")))
+	err := fn()
+	endSpan(span, err)
+	return err
+}
+
+// traceStep runs one step of a Tx method in a span, so its queries are
+// grouped under it.
+func traceStep[T any](ctx context.Context, name string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, span := tracer.Start(ctx, name)
+	result, err := fn(ctx)
+	endSpan(span, err)
+	return result, err
+}
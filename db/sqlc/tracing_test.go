@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "query", queryName("SELECT 1"))
+}
+
+func TestTransferTxSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+
+	store := NewStore(testDB)
+	from := CreateRandomAccount(t)
+	to := CreateRandomAccount(t)
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "test")
+	_, err := store.TransferTx(ctx, TransferTxParams{FromAccountId: from.ID, ToAccountId: to.ID, Amount: 1})
+	root.End()
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	byName := map[string]tracetest.SpanStub{}
+	for _, span := range spans {
+		require.Equal(t, root.SpanContext().TraceID(), span.SpanContext.TraceID())
+		byName[span.Name] = span
+	}
+
+	for _, name := range []string{"db.BEGIN", "TransferTx.fee", "TransferTx.post", "db.CreateTransfer", "db.CreateEntry", "db.COMMIT"} {
+		require.Contains(t, byName, name)
+	}
+	require.Equal(t, byName["TransferTx.post"].SpanContext.SpanID(), byName["db.CreateTransfer"].Parent.SpanID())
+	require.Equal(t, root.SpanContext().SpanID(), byName["db.COMMIT"].Parent.SpanID())
+}
@@ -0,0 +1,500 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: transfer_batch.sql
+
+package db
+
+import (
+	"context"
+)
+
+const completeTransferBatch = `-- name: CompleteTransferBatch :one
+UPDATE transfer_batches b
+SET status = 'completed',
+  completed_at = now(),
+  succeeded_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'succeeded'),
+  failed_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'failed')
+WHERE b.id = $1
+RETURNING id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error
+`
+
+func (q *Queries) CompleteTransferBatch(ctx context.Context, id int64) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, completeTransferBatch, id)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Format,
+		&i.Filename,
+		&i.Status,
+		&i.ItemCount,
+		&i.ValidCount,
+		&i.SucceededCount,
+		&i.FailedCount,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const createTransferBatch = `-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner,
+  format,
+  filename,
+  item_count,
+  valid_count
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error
+`
+
+type CreateTransferBatchParams struct {
+	Owner      string `json:"owner"`
+	Format     string `json:"format"`
+	Filename   string `json:"filename"`
+	ItemCount  int32  `json:"item_count"`
+	ValidCount int32  `json:"valid_count"`
+}
+
+func (q *Queries) CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, createTransferBatch,
+		arg.Owner,
+		arg.Format,
+		arg.Filename,
+		arg.ItemCount,
+		arg.ValidCount,
+	)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Format,
+		&i.Filename,
+		&i.Status,
+		&i.ItemCount,
+		&i.ValidCount,
+		&i.SucceededCount,
+		&i.FailedCount,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const createTransferBatchItem = `-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+  batch_id,
+  line_no,
+  from_account,
+  to_account,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  reference,
+  memo,
+  status,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+) RETURNING id, batch_id, line_no, from_account, to_account, from_account_id, to_account_id, amount, currency, reference, memo, status, error, transfer_id
+`
+
+type CreateTransferBatchItemParams struct {
+	BatchID       int64  `json:"batch_id"`
+	LineNo        int32  `json:"line_no"`
+	FromAccount   string `json:"from_account"`
+	ToAccount     string `json:"to_account"`
+	FromAccountID *int64 `json:"from_account_id"`
+	ToAccountID   *int64 `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	Reference     string `json:"reference"`
+	Memo          string `json:"memo"`
+	Status        string `json:"status"`
+	Error         string `json:"error"`
+}
+
+func (q *Queries) CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error) {
+	row := q.db.QueryRowContext(ctx, createTransferBatchItem,
+		arg.BatchID,
+		arg.LineNo,
+		arg.FromAccount,
+		arg.ToAccount,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Reference,
+		arg.Memo,
+		arg.Status,
+		arg.Error,
+	)
+	var i TransferBatchItem
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNo,
+		&i.FromAccount,
+		&i.ToAccount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Memo,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const failTransferBatch = `-- name: FailTransferBatch :one
+UPDATE transfer_batches b
+SET status = 'failed',
+  error = $1,
+  completed_at = now(),
+  succeeded_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'succeeded'),
+  failed_count = (SELECT count(*) FROM transfer_batch_items i WHERE i.batch_id = b.id AND i.status = 'failed')
+WHERE b.id = $2 AND b.status = 'processing'
+RETURNING id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error
+`
+
+type FailTransferBatchParams struct {
+	Error string `json:"error"`
+	ID    int64  `json:"id"`
+}
+
+// Stops a batch that could not be run. Rows paid before the failure stay
+// paid; the rest are left valid and unpaid.
+func (q *Queries) FailTransferBatch(ctx context.Context, arg FailTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, failTransferBatch, arg.Error, arg.ID)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Format,
+		&i.Filename,
+		&i.Status,
+		&i.ItemCount,
+		&i.ValidCount,
+		&i.SucceededCount,
+		&i.FailedCount,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getTransferBatch = `-- name: GetTransferBatch :one
+SELECT id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error FROM transfer_batches
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, getTransferBatch, id)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Format,
+		&i.Filename,
+		&i.Status,
+		&i.ItemCount,
+		&i.ValidCount,
+		&i.SucceededCount,
+		&i.FailedCount,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.Error,
+	)
+	return i, err
+}
+
+const getTransferBatchItemForUpdate = `-- name: GetTransferBatchItemForUpdate :one
+SELECT id, batch_id, line_no, from_account, to_account, from_account_id, to_account_id, amount, currency, reference, memo, status, error, transfer_id FROM transfer_batch_items
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetTransferBatchItemForUpdate(ctx context.Context, id int64) (TransferBatchItem, error) {
+	row := q.db.QueryRowContext(ctx, getTransferBatchItemForUpdate, id)
+	var i TransferBatchItem
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNo,
+		&i.FromAccount,
+		&i.ToAccount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Memo,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const listProcessingTransferBatches = `-- name: ListProcessingTransferBatches :many
+SELECT id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error FROM transfer_batches
+WHERE status = 'processing'
+ORDER BY approved_at, id
+LIMIT $1
+`
+
+// Batches approved but not yet finished, oldest approval first.
+func (q *Queries) ListProcessingTransferBatches(ctx context.Context, limit int32) ([]TransferBatch, error) {
+	rows, err := q.db.QueryContext(ctx, listProcessingTransferBatches, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatch{}
+	for rows.Next() {
+		var i TransferBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Format,
+			&i.Filename,
+			&i.Status,
+			&i.ItemCount,
+			&i.ValidCount,
+			&i.SucceededCount,
+			&i.FailedCount,
+			&i.ApprovedBy,
+			&i.ApprovedAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferBatchItems = `-- name: ListTransferBatchItems :many
+SELECT id, batch_id, line_no, from_account, to_account, from_account_id, to_account_id, amount, currency, reference, memo, status, error, transfer_id FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY line_no
+`
+
+func (q *Queries) ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferBatchItems, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatchItem{}
+	for rows.Next() {
+		var i TransferBatchItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.BatchID,
+			&i.LineNo,
+			&i.FromAccount,
+			&i.ToAccount,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.Currency,
+			&i.Reference,
+			&i.Memo,
+			&i.Status,
+			&i.Error,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferBatches = `-- name: ListTransferBatches :many
+SELECT id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error FROM transfer_batches
+WHERE owner = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListTransferBatchesParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListTransferBatches(ctx context.Context, arg ListTransferBatchesParams) ([]TransferBatch, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferBatches, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TransferBatch{}
+	for rows.Next() {
+		var i TransferBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Format,
+			&i.Filename,
+			&i.Status,
+			&i.ItemCount,
+			&i.ValidCount,
+			&i.SucceededCount,
+			&i.FailedCount,
+			&i.ApprovedBy,
+			&i.ApprovedAt,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markTransferBatchItemFailed = `-- name: MarkTransferBatchItemFailed :one
+UPDATE transfer_batch_items
+SET status = 'failed', error = $1
+WHERE id = $2 AND status = 'valid'
+RETURNING id, batch_id, line_no, from_account, to_account, from_account_id, to_account_id, amount, currency, reference, memo, status, error, transfer_id
+`
+
+type MarkTransferBatchItemFailedParams struct {
+	Error string `json:"error"`
+	ID    int64  `json:"id"`
+}
+
+func (q *Queries) MarkTransferBatchItemFailed(ctx context.Context, arg MarkTransferBatchItemFailedParams) (TransferBatchItem, error) {
+	row := q.db.QueryRowContext(ctx, markTransferBatchItemFailed, arg.Error, arg.ID)
+	var i TransferBatchItem
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNo,
+		&i.FromAccount,
+		&i.ToAccount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Memo,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const markTransferBatchItemSucceeded = `-- name: MarkTransferBatchItemSucceeded :one
+UPDATE transfer_batch_items
+SET status = 'succeeded', transfer_id = $1, error = ''
+WHERE id = $2
+RETURNING id, batch_id, line_no, from_account, to_account, from_account_id, to_account_id, amount, currency, reference, memo, status, error, transfer_id
+`
+
+type MarkTransferBatchItemSucceededParams struct {
+	TransferID *int64 `json:"transfer_id"`
+	ID         int64  `json:"id"`
+}
+
+func (q *Queries) MarkTransferBatchItemSucceeded(ctx context.Context, arg MarkTransferBatchItemSucceededParams) (TransferBatchItem, error) {
+	row := q.db.QueryRowContext(ctx, markTransferBatchItemSucceeded, arg.TransferID, arg.ID)
+	var i TransferBatchItem
+	err := row.Scan(
+		&i.ID,
+		&i.BatchID,
+		&i.LineNo,
+		&i.FromAccount,
+		&i.ToAccount,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Reference,
+		&i.Memo,
+		&i.Status,
+		&i.Error,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const startTransferBatch = `-- name: StartTransferBatch :one
+UPDATE transfer_batches
+SET status = 'processing', approved_by = $1, approved_at = now()
+WHERE id = $2 AND status = 'pending'
+RETURNING id, owner, format, filename, status, item_count, valid_count, succeeded_count, failed_count, approved_by, approved_at, completed_at, created_at, error
+`
+
+type StartTransferBatchParams struct {
+	ApprovedBy *string `json:"approved_by"`
+	ID         int64   `json:"id"`
+}
+
+// Moves a pending batch to processing. It returns no row when the batch was
+// already approved, so only one caller ever executes it.
+func (q *Queries) StartTransferBatch(ctx context.Context, arg StartTransferBatchParams) (TransferBatch, error) {
+	row := q.db.QueryRowContext(ctx, startTransferBatch, arg.ApprovedBy, arg.ID)
+	var i TransferBatch
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Format,
+		&i.Filename,
+		&i.Status,
+		&i.ItemCount,
+		&i.ValidCount,
+		&i.SucceededCount,
+		&i.FailedCount,
+		&i.ApprovedBy,
+		&i.ApprovedAt,
+		&i.CompletedAt,
+		&i.CreatedAt,
+		&i.Error,
+	)
+	return i, err
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func TestTransferBatchTx(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := CreateRandomAccount(t)
+	toAccount := CreateRandomAccount(t)
+
+	created, err := store.CreateTransferBatchTx(context.Background(), CreateTransferBatchTxParams{
+		Batch: CreateTransferBatchParams{
+			Owner:      fromAccount.Owner,
+			Format:     "csv",
+			Filename:   "payouts.csv",
+			ItemCount:  2,
+			ValidCount: 1,
+		},
+		Items: []CreateTransferBatchItemParams{
+			{
+				LineNo:        2,
+				FromAccount:   fromAccount.AccountNumber,
+				ToAccount:     toAccount.AccountNumber,
+				FromAccountID: &fromAccount.ID,
+				ToAccountID:   &toAccount.ID,
+				Amount:        1,
+				Currency:      fromAccount.Currency,
+				Status:        util.TransferBatchItemValid,
+			},
+			{
+				LineNo:      3,
+				FromAccount: fromAccount.AccountNumber,
+				ToAccount:   "unknown",
+				Amount:      1,
+				Currency:    fromAccount.Currency,
+				Status:      util.TransferBatchItemInvalid,
+				Error:       "to account not found",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferBatchPending, created.Batch.Status)
+	require.Len(t, created.Items, 2)
+	require.Equal(t, created.Batch.ID, created.Items[0].BatchID)
+
+	started, err := store.StartTransferBatch(context.Background(), StartTransferBatchParams{
+		ID:         created.Batch.ID,
+		ApprovedBy: &fromAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferBatchProcessing, started.Status)
+
+	_, err = store.StartTransferBatch(context.Background(), StartTransferBatchParams{
+		ID:         created.Batch.ID,
+		ApprovedBy: &fromAccount.Owner,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	processing, err := store.ListProcessingTransferBatches(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, processing, started)
+
+	args := ExecuteTransferBatchItemTxParams{
+		ItemID: created.Items[0].ID,
+		Transfer: TransferTxParams{
+			FromAccountId: fromAccount.ID,
+			ToAccountId:   toAccount.ID,
+			Amount:        1,
+		},
+	}
+	result, err := store.ExecuteTransferBatchItemTx(context.Background(), args)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferBatchItemSucceeded, result.Item.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, *result.Item.TransferID)
+
+	_, err = store.ExecuteTransferBatchItemTx(context.Background(), args)
+	require.ErrorIs(t, err, ErrBatchItemNotValid)
+
+	_, err = store.ExecuteTransferBatchItemTx(context.Background(), ExecuteTransferBatchItemTxParams{
+		ItemID:   created.Items[1].ID,
+		Transfer: args.Transfer,
+	})
+	require.ErrorIs(t, err, ErrBatchItemNotValid)
+
+	completed, err := store.CompleteTransferBatch(context.Background(), created.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.TransferBatchCompleted, completed.Status)
+	require.Equal(t, int32(1), completed.SucceededCount)
+	require.Equal(t, int32(0), completed.FailedCount)
+
+	// Only a batch still processing can fail.
+	_, err = store.FailTransferBatch(context.Background(), FailTransferBatchParams{ID: created.Batch.ID, Error: "boom"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -13,6 +13,7 @@ func CreateRandomTransfer(t *testing.T, accountId1 int64, accountId2 int64) Tran
 		FromAccountID: accountId1,
 		ToAccountID:   accountId2,
 		Amount:        util.RandomMoney(),
+		Metadata:      emptyMetadata,
 	}
 
 	transfer, err := testQuery.CreateTransfer(context.Background(), args)
@@ -71,3 +72,42 @@ func TestListTransfers(t *testing.T) {
 		require.NotEmpty(t, transfer)
 	}
 }
+
+func TestTransferTxDetails(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+	reference := "INV-" + util.RandomString(8)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        1,
+		Memo:          "March rent",
+		Reference:     reference,
+		Metadata:      map[string]string{"invoice": "2026-03"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "March rent", result.Transfer.Memo)
+	require.Equal(t, reference, result.ToEntry.Reference)
+	require.JSONEq(t, `{"invoice": "2026-03"}`, string(result.FromEntry.Metadata))
+
+	transfers, err := testQuery.ListTransfers(context.Background(), ListTransfersParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account1.ID,
+		Reference:     &reference,
+		Limit:         5,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, result.Transfer.ID, transfers[0].ID)
+
+	entries, err := testQuery.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: account2.ID,
+		Reference: &reference,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.ToEntry.ID, entries[0].ID)
+}
@@ -52,4 +52,20 @@ func TestGetuser(t *testing.T) {
 	require.Equal(t, user.Username, user2.Username)
 	require.WithinDuration(t, user.CreatedAt, user2.CreatedAt, time.Second)
 	require.WithinDuration(t, user.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
-}
\ No newline at end of file
+}
+func TestUserDiscoverable(t *testing.T) {
+	user := CreateRandomUser(t)
+	require.False(t, user.Discoverable)
+
+	updated, err := testQuery.UpdateUserDiscoverable(context.Background(), UpdateUserDiscoverableParams{
+		Username:     user.Username,
+		Discoverable: true,
+	})
+	require.NoError(t, err)
+	require.True(t, updated.Discoverable)
+
+	byEmail, err := testQuery.GetUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, byEmail.Username)
+	require.True(t, byEmail.Discoverable)
+}
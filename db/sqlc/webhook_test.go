@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+func createRandomWebhookSubscription(t *testing.T, owner string, eventTypes ...string) WebhookSubscription {
+	subscription, err := testQuery.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(8),
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + util.RandomString(32),
+	})
+	require.NoError(t, err)
+	require.Equal(t, eventTypes, subscription.EventTypes)
+	return subscription
+}
+
+func TestTransferEnqueuesWebhook(t *testing.T) {
+	store := NewStore(testDB)
+	fromAccount := CreateRandomAccount(t)
+	toAccount := CreateRandomAccount(t)
+
+	subscription := createRandomWebhookSubscription(t, toAccount.Owner, util.TransferCreatedEvent)
+	other := createRandomWebhookSubscription(t, toAccount.Owner, util.SessionCreatedEvent)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountId: fromAccount.ID,
+		ToAccountId:   toAccount.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, util.TransferCreatedEvent, deliveries[0].EventType)
+	require.Equal(t, util.WebhookDeliveryPending, deliveries[0].Status)
+
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			ID int64 `json:"id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+	require.Equal(t, deliveries[0].EventID.String(), event.ID)
+	require.Equal(t, result.Transfer.ID, event.Data.ID)
+
+	deliveries, err = store.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: other.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func TestSetAccountFrozenTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := CreateRandomAccount(t)
+	subscription := createRandomWebhookSubscription(t, account.Owner, util.AccountFrozenEvent, util.AccountUnfrozenEvent)
+
+	result, err := store.SetAccountFrozenTx(context.Background(), SetAccountFrozenParams{ID: account.ID, Frozen: true})
+	require.NoError(t, err)
+	require.True(t, result.Changed)
+	require.True(t, result.Account.Frozen)
+
+	// Freezing a frozen account changes nothing and sends no event.
+	result, err = store.SetAccountFrozenTx(context.Background(), SetAccountFrozenParams{ID: account.ID, Frozen: true})
+	require.NoError(t, err)
+	require.False(t, result.Changed)
+
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, util.AccountFrozenEvent, deliveries[0].EventType)
+
+	retried, err := store.RetryWebhookDelivery(context.Background(), RetryWebhookDeliveryParams{
+		ID:             deliveries[0].ID,
+		SubscriptionID: subscription.ID,
+	})
+	require.Error(t, err)
+	require.Empty(t, retried)
+}
@@ -0,0 +1,53 @@
+package fee
+
+// Schedule describes how a transfer fee is charged: a flat part plus a
+// percentage of the amount, with the total clamped to [Min, Max]. A nil Max
+// means there is no cap.
+type Schedule struct {
+	Flat    int64
+	RatePPM int64
+	Min     int64
+	Max     *int64
+}
+
+// Breakdown is how a fee was arrived at. Total is what the sender pays on
+// top of the transfer amount.
+type Breakdown struct {
+	Flat       int64 `json:"flat"`
+	Percentage int64 `json:"percentage"`
+	Adjustment int64 `json:"adjustment"`
+	Total      int64 `json:"total"`
+}
+
+// Compute applies s to amount. The percentage part is rounded half up to the
+// nearest minor unit; Adjustment records how much the min/max clamp moved the
+// total.
+func Compute(s Schedule, amount int64) Breakdown {
+	b := Breakdown{
+		Flat:       s.Flat,
+		Percentage: percentOf(amount, s.RatePPM),
+	}
+
+	raw := b.Flat + b.Percentage
+	b.Total = raw
+	if b.Total < s.Min {
+		b.Total = s.Min
+	}
+	if s.Max != nil && b.Total > *s.Max {
+		b.Total = *s.Max
+	}
+	b.Adjustment = b.Total - raw
+
+	return b
+}
+
+func percentOf(amount, ratePPM int64) int64 {
+	if amount <= 0 || ratePPM <= 0 {
+		return 0
+	}
+
+	// Split the amount so amount*rate can't overflow for large transfers.
+	whole := amount / 1_000_000 * ratePPM
+	rest := amount % 1_000_000 * ratePPM
+	return whole + (rest+500_000)/1_000_000
+}
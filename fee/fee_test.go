@@ -0,0 +1,75 @@
+package fee
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(v int64) *int64 { return &v }
+
+func TestCompute(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule Schedule
+		amount   int64
+		want     Breakdown
+	}{
+		{
+			name:     "NoFee",
+			schedule: Schedule{},
+			amount:   1_000,
+			want:     Breakdown{},
+		},
+		{
+			name:     "Flat",
+			schedule: Schedule{Flat: 50},
+			amount:   1_000,
+			want:     Breakdown{Flat: 50, Total: 50},
+		},
+		{
+			name:     "Percentage",
+			schedule: Schedule{RatePPM: 15_000},
+			amount:   10_000,
+			want:     Breakdown{Percentage: 150, Total: 150},
+		},
+		{
+			name:     "PercentageRoundsHalfUp",
+			schedule: Schedule{RatePPM: 15_000},
+			amount:   1_030,
+			want:     Breakdown{Percentage: 15, Total: 15},
+		},
+		{
+			name:     "FlatPlusPercentage",
+			schedule: Schedule{Flat: 25, RatePPM: 10_000},
+			amount:   10_000,
+			want:     Breakdown{Flat: 25, Percentage: 100, Total: 125},
+		},
+		{
+			name:     "Min",
+			schedule: Schedule{RatePPM: 10_000, Min: 100},
+			amount:   1_000,
+			want:     Breakdown{Percentage: 10, Adjustment: 90, Total: 100},
+		},
+		{
+			name:     "Max",
+			schedule: Schedule{RatePPM: 10_000, Max: ptr(500)},
+			amount:   1_000_000,
+			want:     Breakdown{Percentage: 10_000, Adjustment: -9_500, Total: 500},
+		},
+		{
+			name:     "LargeAmount",
+			schedule: Schedule{RatePPM: 1_000},
+			amount:   math.MaxInt64 / 10,
+			want:     Breakdown{Percentage: 922_337_203_685_478, Total: 922_337_203_685_478},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, Compute(tc.schedule, tc.amount))
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package interest
+
+import (
+	"math/big"
+	"time"
+)
+
+// Compounding controls whether accrued but not yet posted interest earns
+// interest itself. Posting always happens monthly.
+type Compounding string
+
+const (
+	CompoundDaily   Compounding = "daily"
+	CompoundMonthly Compounding = "monthly"
+)
+
+func (c Compounding) Valid() bool {
+	return c == CompoundDaily || c == CompoundMonthly
+}
+
+// DailyAccrual returns the interest earned by principal (in minor units) over
+// the day starting at day, in micros of a minor unit. annualRatePPM is the
+// yearly rate in parts per million, so 4.125% is 41250.
+func DailyAccrual(principal, annualRatePPM int64, dc DayCount, day time.Time) int64 {
+	if principal <= 0 || annualRatePPM <= 0 {
+		return 0
+	}
+
+	num, den := dc.dayFraction(day)
+
+	// principal * (rate / 1e6) * (num / den) * 1e6 micros: the two 1e6 cancel.
+	// big.Int keeps large balances from overflowing the intermediate product.
+	v := new(big.Int).Mul(big.NewInt(principal), big.NewInt(annualRatePPM))
+	v.Mul(v, big.NewInt(num))
+	v.Quo(v, big.NewInt(den))
+	return v.Int64()
+}
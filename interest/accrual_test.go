@@ -0,0 +1,57 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayFractionsSumToOneYear(t *testing.T) {
+	for _, year := range []int{2023, 2024} {
+		for _, dc := range []DayCount{Actual365, Actual360, ActualActual, Thirty360} {
+			var num int64
+			var den int64
+			for day := date(year, 1, 1); day.Year() == year; day = day.AddDate(0, 0, 1) {
+				n, d := dc.dayFraction(day)
+				num += n
+				den = d
+			}
+
+			switch dc {
+			case Thirty360, ActualActual:
+				require.Equal(t, den, num, "%s in %d", dc, year)
+			default:
+				require.Equal(t, int64(daysInYear(year)), num, "%s in %d", dc, year)
+			}
+		}
+	}
+}
+
+func TestDays30360(t *testing.T) {
+	require.Equal(t, int64(0), days30360(date(2026, 1, 30), date(2026, 1, 31)))
+	require.Equal(t, int64(1), days30360(date(2026, 1, 31), date(2026, 2, 1)))
+	require.Equal(t, int64(3), days30360(date(2026, 2, 28), date(2026, 3, 1)))
+	require.Equal(t, int64(2), days30360(date(2024, 2, 29), date(2024, 3, 1)))
+}
+
+func TestDailyAccrual(t *testing.T) {
+	// 100.00 at 3.65% ACT/365 earns exactly 0.01 a day.
+	require.Equal(t, int64(1_000_000), DailyAccrual(10_000, 36_500, Actual365, date(2026, 5, 1)))
+
+	// 100.00 at 5% ACT/360 earns 0.013888... a day; micros keep the fraction.
+	require.Equal(t, int64(1_388_888), DailyAccrual(10_000, 50_000, Actual360, date(2026, 5, 1)))
+
+	// Under 30/360 the step from the 30th to the 31st is worth nothing.
+	require.Equal(t, int64(0), DailyAccrual(10_000, 50_000, Thirty360, date(2026, 5, 30)))
+
+	require.Equal(t, int64(0), DailyAccrual(-10_000, 50_000, Actual365, date(2026, 5, 1)))
+	require.Equal(t, int64(0), DailyAccrual(10_000, 0, Actual365, date(2026, 5, 1)))
+
+	// Large balances must not overflow the intermediate product.
+	require.Equal(t, int64(2_500_000_000_000_000_000), DailyAccrual(1_000_000_000_000_000, 912_500, Actual365, date(2026, 5, 1)))
+}
@@ -0,0 +1,60 @@
+package interest
+
+import "time"
+
+// DayCount is the convention used to turn days into a fraction of a year.
+type DayCount string
+
+const (
+	Actual365    DayCount = "ACT/365"
+	Actual360    DayCount = "ACT/360"
+	ActualActual DayCount = "ACT/ACT"
+	Thirty360    DayCount = "30/360"
+)
+
+func (dc DayCount) Valid() bool {
+	switch dc {
+	case Actual365, Actual360, ActualActual, Thirty360:
+		return true
+	}
+	return false
+}
+
+// dayFraction returns num/den, the fraction of a year that accrues over the
+// single day starting at day.
+func (dc DayCount) dayFraction(day time.Time) (num, den int64) {
+	switch dc {
+	case Actual360:
+		return 1, 360
+	case ActualActual:
+		return 1, int64(daysInYear(day.Year()))
+	case Thirty360:
+		return days30360(day, day.AddDate(0, 0, 1)), 360
+	default:
+		return 1, 365
+	}
+}
+
+func daysInYear(year int) int {
+	if time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+		return 366
+	}
+	return 365
+}
+
+// days30360 counts days between two dates under the 30/360 US bond basis:
+// every month has 30 days, so the step into the 31st is worth nothing and the
+// step out of the last day of February makes up the rest of the month.
+func days30360(from, to time.Time) int64 {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+
+	return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+}
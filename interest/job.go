@@ -0,0 +1,219 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+const defaultChunkSize = 500
+
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// FixedClock always reports the same instant.
+type FixedClock time.Time
+
+func (c FixedClock) Now() time.Time { return time.Time(c) }
+
+// Job accrues daily interest on savings accounts and posts it monthly.
+type Job struct {
+	store     db.Store
+	clock     Clock
+	chunkSize int32
+}
+
+func NewJob(store db.Store, clock Clock, chunkSize int32) *Job {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	return &Job{
+		store:     store,
+		clock:     clock,
+		chunkSize: chunkSize,
+	}
+}
+
+// RunDue accrues interest for every day up to yesterday and posts every
+// month that has already ended. Both steps are idempotent, so the job can run
+// as often as the scheduler likes.
+func (job *Job) RunDue(ctx context.Context) error {
+	today := snapshot.Day(job.clock.Now())
+
+	from, err := job.firstDueDay(ctx, today)
+	if err != nil {
+		return err
+	}
+
+	for day := from; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if _, err := job.Accrue(ctx, day); err != nil {
+			return err
+		}
+	}
+
+	_, err = job.Post(ctx, monthStart(today).AddDate(0, -1, 0))
+	return err
+}
+
+// firstDueDay is the day accruals resume from. Days missed while the job was
+// down are caught up, starting again from the last accrued day, which a
+// failed run may have left half done. The very first run accrues yesterday.
+func (job *Job) firstDueDay(ctx context.Context, today time.Time) (time.Time, error) {
+	yesterday := today.AddDate(0, 0, -1)
+
+	latest, err := job.store.GetLatestInterestAccrualDate(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return yesterday, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot find the last accrued day: %w", err)
+	}
+
+	latest = snapshot.Day(latest)
+	if latest.After(yesterday) {
+		return yesterday, nil
+	}
+	return latest, nil
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Accrue books one day of interest for every savings account with an
+// interest product and returns the number of new accruals.
+func (job *Job) Accrue(ctx context.Context, date time.Time) (int64, error) {
+	day := snapshot.Day(date)
+
+	var accrued int64
+	var afterID int64
+	for {
+		rows, err := job.store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+			AfterID:   afterID,
+			ChunkSize: job.chunkSize,
+		})
+		if err != nil {
+			return accrued, fmt.Errorf("cannot list savings accounts after %d: %w", afterID, err)
+		}
+
+		for _, row := range rows {
+			afterID = row.Account.ID
+
+			principal, err := job.principal(ctx, row, day)
+			if err != nil {
+				return accrued, fmt.Errorf("cannot compute principal of account %d: %w", row.Account.ID, err)
+			}
+
+			product := row.InterestProduct
+			n, err := job.store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+				AccountID:    row.Account.ID,
+				AccrualDate:  day,
+				Principal:    principal,
+				AmountMicros: DailyAccrual(principal, product.AnnualRatePpm, DayCount(product.DayCount), day),
+			})
+			if err != nil {
+				return accrued, fmt.Errorf("cannot accrue interest for account %d: %w", row.Account.ID, err)
+			}
+			accrued += n
+		}
+
+		if len(rows) < int(job.chunkSize) {
+			return accrued, nil
+		}
+	}
+}
+
+// principal is the end-of-day balance, plus whole units of interest accrued
+// but not yet posted when the product compounds daily.
+func (job *Job) principal(ctx context.Context, row db.ListInterestBearingAccountsRow, day time.Time) (int64, error) {
+	endOfDay := day.AddDate(0, 0, 1).Add(-time.Microsecond)
+	balance, err := snapshot.BalanceAt(ctx, job.store, row.Account, endOfDay)
+	if err != nil {
+		return 0, err
+	}
+
+	principal := balance.Balance
+	if Compounding(row.InterestProduct.Compounding) == CompoundDaily {
+		pending, err := job.store.SumUnpostedInterest(ctx, db.SumUnpostedInterestParams{
+			AccountID:  row.Account.ID,
+			BeforeDate: day,
+		})
+		if err != nil {
+			return 0, err
+		}
+		principal += pending / db.MicrosPerUnit
+	}
+
+	return principal, nil
+}
+
+// Post pays out interest accrued up to the end of the month starting at
+// period and returns the number of accounts posted.
+func (job *Job) Post(ctx context.Context, period time.Time) (int64, error) {
+	period = monthStart(period)
+	periodEnd := period.AddDate(0, 1, 0)
+	systemAccounts := map[string]int64{}
+
+	var posted int64
+	var afterID int64
+	for {
+		ids, err := job.store.ListAccountsWithUnpostedInterest(ctx, db.ListAccountsWithUnpostedInterestParams{
+			BeforeDate: periodEnd,
+			AfterID:    afterID,
+			ChunkSize:  job.chunkSize,
+		})
+		if err != nil {
+			return posted, fmt.Errorf("cannot list accounts with unposted interest: %w", err)
+		}
+
+		for _, id := range ids {
+			afterID = id
+
+			account, err := job.store.GetAccount(ctx, id)
+			if err != nil {
+				return posted, err
+			}
+
+			systemID, ok := systemAccounts[account.Currency]
+			if !ok {
+				system, err := job.store.GetSystemAccount(ctx, db.GetSystemAccountParams{
+					Purpose:  util.InterestExpensePurpose,
+					Currency: account.Currency,
+				})
+				if err != nil {
+					return posted, fmt.Errorf("cannot find %s interest expense account: %w", account.Currency, err)
+				}
+				systemID = system.ID
+				systemAccounts[account.Currency] = systemID
+			}
+
+			result, err := job.store.PostInterestTx(ctx, db.PostInterestTxParams{
+				AccountID:       id,
+				SystemAccountID: systemID,
+				Period:          period,
+			})
+			if err != nil {
+				return posted, fmt.Errorf("cannot post interest for account %d: %w", id, err)
+			}
+			if !result.AlreadyPosted {
+				posted++
+			}
+		}
+
+		if len(ids) < int(job.chunkSize) {
+			return posted, nil
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobRunDue(t *testing.T) {
+	productID := int64(3)
+	savings := db.Account{ID: 10, Balance: 10_000, Currency: "USD", AccountType: util.SavingsAccount, InterestProductID: &productID}
+	product := db.InterestProduct{ID: productID, Currency: "USD", AnnualRatePpm: 36_500, DayCount: string(Actual365), Compounding: string(CompoundDaily)}
+	system := db.Account{ID: 1, Currency: "USD", AccountType: util.SystemAccount}
+
+	// The job runs just after midnight on the 1st: it accrues the last day of
+	// the previous month and then posts that month.
+	clock := FixedClock(time.Date(2026, 6, 1, 0, 5, 0, 0, time.UTC))
+	yesterday := date(2026, 5, 31)
+	period := date(2026, 5, 1)
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetLatestInterestAccrualDate(gomock.Any()).Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), db.ListInterestBearingAccountsParams{AfterID: 0, ChunkSize: 10}).
+		Return([]db.ListInterestBearingAccountsRow{{Account: savings, InterestProduct: product}}, nil)
+	store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+	store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{AccountID: savings.ID, FromTime: date(2026, 6, 1)}).
+		Return(int64(0), nil)
+	// 2.5 units are pending, so 2 whole units compound on top of the balance.
+	store.EXPECT().SumUnpostedInterest(gomock.Any(), db.SumUnpostedInterestParams{AccountID: savings.ID, BeforeDate: yesterday}).
+		Return(int64(2_500_000), nil)
+	store.EXPECT().CreateInterestAccrual(gomock.Any(), db.CreateInterestAccrualParams{
+		AccountID:    savings.ID,
+		AccrualDate:  yesterday,
+		Principal:    10_002,
+		AmountMicros: 1_000_200,
+	}).Return(int64(1), nil)
+
+	store.EXPECT().ListAccountsWithUnpostedInterest(gomock.Any(), db.ListAccountsWithUnpostedInterestParams{
+		BeforeDate: date(2026, 6, 1), AfterID: 0, ChunkSize: 10,
+	}).Return([]int64{savings.ID}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), savings.ID).Return(savings, nil)
+	store.EXPECT().GetSystemAccount(gomock.Any(), db.GetSystemAccountParams{Purpose: util.InterestExpensePurpose, Currency: "USD"}).
+		Return(system, nil)
+	store.EXPECT().PostInterestTx(gomock.Any(), db.PostInterestTxParams{
+		AccountID: savings.ID, SystemAccountID: system.ID, Period: period,
+	}).Return(db.PostInterestTxResult{}, nil)
+
+	require.NoError(t, NewJob(store, clock, 10).RunDue(context.Background()))
+}
+
+func TestJobAccrueError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetLatestInterestAccrualDate(gomock.Any()).Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Return(nil, sql.ErrConnDone)
+	store.EXPECT().ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Any()).Times(0)
+
+	err := NewJob(store, FixedClock(date(2026, 6, 2)), 10).RunDue(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestJobRunDueCatchesUp(t *testing.T) {
+	productID := int64(3)
+	savings := db.Account{ID: 10, Balance: 36_500, Currency: "USD", AccountType: util.SavingsAccount, InterestProductID: &productID}
+	product := db.InterestProduct{ID: productID, Currency: "USD", AnnualRatePpm: 100_000, DayCount: string(Actual365), Compounding: string(CompoundMonthly)}
+
+	// The job was down from the 29th: the 29th may be half done, and the
+	// 30th and 31st were never accrued.
+	clock := FixedClock(time.Date(2026, 6, 1, 0, 5, 0, 0, time.UTC))
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().GetLatestInterestAccrualDate(gomock.Any()).Return(date(2026, 5, 29), nil)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Times(3).
+		Return([]db.ListInterestBearingAccountsRow{{Account: savings, InterestProduct: product}}, nil)
+	store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).Times(3).Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+	store.EXPECT().SumEntriesSince(gomock.Any(), gomock.Any()).Times(3).Return(int64(0), nil)
+
+	var accrued []time.Time
+	store.EXPECT().CreateInterestAccrual(gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(_ context.Context, args db.CreateInterestAccrualParams) (int64, error) {
+			accrued = append(accrued, args.AccrualDate)
+			return 1, nil
+		})
+	store.EXPECT().ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Any()).Return([]int64{}, nil)
+
+	require.NoError(t, NewJob(store, clock, 10).RunDue(context.Background()))
+	require.Equal(t, []time.Time{date(2026, 5, 29), date(2026, 5, 30), date(2026, 5, 31)}, accrued)
+}
+
+func TestJobRunDueAlreadyAccrued(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	// Yesterday is accrued already; it is only checked again.
+	store.EXPECT().GetLatestInterestAccrualDate(gomock.Any()).Return(date(2026, 6, 1), nil)
+	store.EXPECT().ListInterestBearingAccounts(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	store.EXPECT().ListAccountsWithUnpostedInterest(gomock.Any(), gomock.Any()).Return([]int64{}, nil)
+
+	require.NoError(t, NewJob(store, FixedClock(date(2026, 6, 2)), 10).RunDue(context.Background()))
+}
@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	logger.Info("login",
+		slog.String("password", "hunter2"),
+		slog.Group("session", slog.String("refresh_token", "v2.local.abc")),
+		slog.String("header", "Bearer v2.local.secret-access"),
+		slog.String("cookie_header", "refresh_token=v2.local.cookie; csrf_token=Zm9vYmFy; Path=/"),
+		slog.String("csrf_token", "YmF6cXV4"),
+		slog.Any("error", errors.New("bad token eyJhbGciOi.eyJzdWIiOi.sig")),
+		slog.String("body", `{"secret":"whsec_0123abcd"}`),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "v2.local", "secret-access", "eyJhbGciOi", "Zm9vYmFy", "YmF6cXV4", "0123abcd"} {
+		require.NotContains(t, out, secret)
+	}
+	require.Contains(t, out, redacted)
+}
+
+func TestContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	ctx := WithUsername(WithRequestID(context.Background(), "req-1"), "alice")
+	logger.InfoContext(ctx, "hello")
+	logger.DebugContext(ctx, "dropped")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "req-1", line["request_id"])
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, "hello", line["msg"])
+}
+
+func TestParseLevel(t *testing.T) {
+	require.Equal(t, slog.LevelDebug, ParseLevel("debug"))
+	require.Equal(t, slog.LevelWarn, ParseLevel("WARN"))
+	require.Equal(t, slog.LevelInfo, ParseLevel(""))
+	require.Equal(t, slog.LevelInfo, ParseLevel("loud"))
+}
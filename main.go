@@ -1,37 +1,143 @@
 package main
 
 import (
-	"database/sql"
-	"log"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/spf13/viper"
 	"github.com/ulunnuha-h/simple_bank/api"
 	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/interest"
+	"github.com/ulunnuha-h/simple_bank/logging"
+	"github.com/ulunnuha-h/simple_bank/metrics"
+	"github.com/ulunnuha-h/simple_bank/reconcile"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+	"github.com/ulunnuha-h/simple_bank/tracing"
 	"github.com/ulunnuha-h/simple_bank/util"
+	"github.com/ulunnuha-h/simple_bank/webhook"
+	"github.com/ulunnuha-h/simple_bank/worker"
 )
 
+// fatal logs err and exits. Deferred calls don't run, so it is only used
+// before anything needs cleaning up.
+func fatal(msg string, err error) {
+	slog.Error(msg, slog.Any("error", err))
+	os.Exit(1)
+}
+
 func main(){
+	var logLevel slog.LevelVar
+	slog.SetDefault(logging.New(os.Stdout, &logLevel))
+
 	err := util.LoadConfig(".")
 	if err != nil {
-		log.Fatal("Failed to load .env file")
+		fatal("cannot load config", err)
+	}
+	logLevel.Set(logging.ParseLevel(viper.GetString("LOG_LEVEL")))
+
+	serviceName := viper.GetString("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "simple_bank"
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: serviceName,
+		Endpoint:    viper.GetString("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		SampleRatio: viper.GetFloat64("TRACE_SAMPLE_RATIO"),
+	})
+	if err != nil {
+		fatal("cannot set up tracing", err)
 	}
 
-	conn, err := sql.Open(viper.GetString("DB_DRIVER"), viper.GetString("DB_SOURCE"))
+	conn, err := db.Connect(context.Background(), viper.GetString("DB_DRIVER"), viper.GetString("DB_SOURCE"), db.PoolConfig{
+		MaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+		ConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+	}, viper.GetInt("DB_CONNECT_ATTEMPTS"), viper.GetDuration("DB_CONNECT_RETRY_INTERVAL"))
 
 	if err != nil {
-		log.Fatal((err))
+		fatal("cannot connect to database", err)
 	}
 
-	store := db.NewStore(conn)
+	if err := metrics.RegisterDB(conn, viper.GetString("DB_DRIVER")); err != nil {
+		fatal("cannot register database metrics", err)
+	}
+
+	store := metrics.NewStore(tracing.NewStore(db.NewStore(conn)))
+
+	var workers worker.Group
+
+	reconciler := reconcile.NewReconciler(store, viper.GetInt32("RECONCILIATION_CHUNK_SIZE"))
+	workers.Go("reconciliation", viper.GetDuration("RECONCILIATION_INTERVAL"), func(ctx context.Context) error {
+		run, err := reconciler.Run(ctx, "scheduler")
+		if err == nil && run.Status == reconcile.StatusDrift {
+			slog.Warn("reconciliation found discrepancies", slog.Int64("run_id", run.ID), slog.Int64("discrepancies", run.DiscrepancyCount))
+		}
+		return err
+	})
+
+	snapshotJob := snapshot.NewJob(store, viper.GetInt32("SNAPSHOT_CHUNK_SIZE"))
+	workers.Go("balance snapshots", viper.GetDuration("SNAPSHOT_INTERVAL"), func(ctx context.Context) error {
+		_, err := snapshotJob.RunPreviousDay(ctx, time.Now())
+		return err
+	})
+
+	interestJob := interest.NewJob(store, interest.SystemClock{}, viper.GetInt32("INTEREST_CHUNK_SIZE"))
+	workers.Go("interest", viper.GetDuration("INTEREST_INTERVAL"), interestJob.RunDue)
+
+	workers.Go("payment request expiry", viper.GetDuration("PAYMENT_REQUEST_EXPIRY_INTERVAL"), func(ctx context.Context) error {
+		_, err := store.ExpirePaymentRequests(ctx)
+		return err
+	})
+
+	workers.Go("rate limit pruning", viper.GetDuration("RATE_LIMIT_PRUNE_INTERVAL"), func(ctx context.Context) error {
+		_, err := store.DeleteFullRateLimitBuckets(ctx)
+		return err
+	})
+
+	dispatcher := webhook.NewDispatcher(store, viper.GetInt32("WEBHOOK_BATCH_SIZE"), viper.GetBool("WEBHOOK_ALLOW_INSECURE"))
+	workers.Go("webhook dispatch", viper.GetDuration("WEBHOOK_DISPATCH_INTERVAL"), func(ctx context.Context) error {
+		_, err := dispatcher.RunOnce(ctx)
+		return err
+	})
+
 	server, err := api.NewServer(store)
 	if err != nil {
-		log.Fatal("cannot create server:", err)
+		fatal("cannot create server", err)
+	}
+
+	// Approved batches wait in processing until this job runs them, so it
+	// is never disabled.
+	batchInterval := viper.GetDuration("TRANSFER_BATCH_INTERVAL")
+	if batchInterval <= 0 {
+		batchInterval = 5 * time.Second
 	}
+	workers.Go("transfer batches", batchInterval, server.RunApprovedTransferBatches)
 
-	err = server.Start(viper.GetString("SERVER_ADDRESS"))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Requests drain first so in-flight transfers can still enqueue their
+	// webhooks, then the workers stop, and the database goes last.
+	err = server.Serve(ctx, api.ServeConfigFromEnv())
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		slog.Error("server stopped", slog.Any("error", err))
+	}
+
+	workers.Stop()
+
+	if err := conn.Close(); err != nil {
+		slog.Error("cannot close database", slog.Any("error", err))
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("cannot flush traces", slog.Any("error", err))
 	}
-	
 }
\ No newline at end of file
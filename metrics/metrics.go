@@ -0,0 +1,151 @@
+// Package metrics exposes Prometheus metrics for the HTTP and gRPC APIs,
+// the database and business events.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+const namespace = "simple_bank"
+
+// Registry holds every metric of the process. It is separate from the
+// prometheus default registry so only what is registered here is exported.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	transferTxDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "transfer_tx_duration_seconds",
+		Help:      "Duration of TransferTx by outcome, including retries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"outcome"})
+
+	dbTxRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_tx_retries_total",
+		Help:      "Transactions re-run after a serialization failure or deadlock, by reason.",
+	}, []string{"reason"})
+
+	dbTxRetriesExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_tx_retries_exhausted_total",
+		Help:      "Transactions given up on after their last attempt failed, by reason.",
+	}, []string{"reason"})
+
+	transfers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Completed transfers by currency.",
+	}, []string{"currency"})
+
+	transferAmount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount_total",
+		Help:      "Sum of completed transfer amounts by currency, in minor units.",
+	}, []string{"currency"})
+
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Failed logins by reason.",
+	}, []string{"reason"})
+
+	tokenRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "token_refreshes_total",
+		Help:      "Access token refreshes by outcome.",
+	}, []string{"outcome"})
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Reasons a login fails.
+const (
+	LoginUnknownUser   = "unknown_user"
+	LoginWrongPassword = "wrong_password"
+	LoginError         = "error"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		grpcRequests,
+		grpcDuration,
+		transferTxDuration,
+		dbTxRetries,
+		dbTxRetriesExhausted,
+		transfers,
+		transferAmount,
+		loginFailures,
+		tokenRefreshes,
+	)
+	db.SetTxRetryObserver(countTxRetry)
+}
+
+// Handler serves the metrics in Registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exports the connection pool stats of conn from sql.DB.Stats.
+func RegisterDB(conn *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(conn, name))
+}
+
+// countTxRetry counts a transaction retry, or the transaction given up on
+// when exhausted is set.
+func countTxRetry(reason string, exhausted bool) {
+	if exhausted {
+		dbTxRetriesExhausted.WithLabelValues(reason).Inc()
+		return
+	}
+	dbTxRetries.WithLabelValues(reason).Inc()
+}
+
+// LoginFailed counts a failed login.
+func LoginFailed(reason string) {
+	loginFailures.WithLabelValues(reason).Inc()
+}
+
+// TokenRefreshed counts an access token refresh.
+func TokenRefreshed(outcome string) {
+	tokenRefreshes.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNotFound)
+	})
+	router.GET("/metrics", gin.WrapH(Handler()))
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/accounts/:id", "404"))
+
+	for _, path := range []string{"/accounts/1", "/accounts/2", "/no-such-route"} {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		router.ServeHTTP(recorder, request)
+	}
+
+	require.Equal(t, before+2, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, "/accounts/:id", "404")))
+	require.Equal(t, float64(1), testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	require.True(t, strings.Contains(body, `simple_bank_http_request_duration_seconds_bucket{method="GET",route="/accounts/:id",status="404"`), body)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/GetAccount"}
+
+	before := testutil.ToFloat64(grpcRequests.WithLabelValues(info.FullMethod, codes.OK.String()))
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, before+1, testutil.ToFloat64(grpcRequests.WithLabelValues(info.FullMethod, codes.OK.String())))
+
+	before = testutil.ToFloat64(grpcRequests.WithLabelValues(info.FullMethod, codes.NotFound.String()))
+	_, err = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "account not found")
+	})
+	require.Error(t, err)
+	require.Equal(t, before+1, testutil.ToFloat64(grpcRequests.WithLabelValues(info.FullMethod, codes.NotFound.String())))
+}
@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+// Store decorates a db.Store with metrics. Calls it doesn't override go
+// straight to the wrapped store.
+type Store struct {
+	db.Store
+}
+
+// NewStore returns store instrumented with metrics.
+func NewStore(store db.Store) db.Store {
+	return &Store{Store: store}
+}
+
+func (store *Store) TransferTx(ctx context.Context, args db.TransferTxParams) (db.TransferTxResult, error) {
+	start := time.Now()
+	result, err := store.Store.TransferTx(ctx, args)
+
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeError
+	}
+	transferTxDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		countTransfer(result)
+	}
+	return result, err
+}
+
+// AcceptPaymentRequestTx and ExecuteTransferBatchItemTx make their transfer
+// inside their own transaction, without going through TransferTx, so they
+// count it here.
+func (store *Store) AcceptPaymentRequestTx(ctx context.Context, args db.AcceptPaymentRequestTxParams) (db.AcceptPaymentRequestTxResult, error) {
+	result, err := store.Store.AcceptPaymentRequestTx(ctx, args)
+	if err == nil && result.Transfer != nil {
+		countTransfer(*result.Transfer)
+	}
+	return result, err
+}
+
+func (store *Store) ExecuteTransferBatchItemTx(ctx context.Context, args db.ExecuteTransferBatchItemTxParams) (db.ExecuteTransferBatchItemTxResult, error) {
+	result, err := store.Store.ExecuteTransferBatchItemTx(ctx, args)
+	if err == nil {
+		countTransfer(result.Transfer)
+	}
+	return result, err
+}
+
+func countTransfer(result db.TransferTxResult) {
+	currency := result.FromAccount.Currency
+	transfers.WithLabelValues(currency).Inc()
+	transferAmount.WithLabelValues(currency).Add(float64(result.Transfer.Amount))
+}
@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStoreTransferTx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	store := NewStore(mock)
+
+	result := db.TransferTxResult{
+		Transfer:    db.Transfer{Amount: 25},
+		FromAccount: db.Account{Currency: "EUR"},
+	}
+	mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+	mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, errors.New("boom"))
+
+	countBefore := testutil.ToFloat64(transfers.WithLabelValues("EUR"))
+	amountBefore := testutil.ToFloat64(transferAmount.WithLabelValues("EUR"))
+
+	_, err := store.TransferTx(context.Background(), db.TransferTxParams{})
+	require.NoError(t, err)
+	_, err = store.TransferTx(context.Background(), db.TransferTxParams{})
+	require.Error(t, err)
+
+	require.Equal(t, countBefore+1, testutil.ToFloat64(transfers.WithLabelValues("EUR")))
+	require.Equal(t, amountBefore+25, testutil.ToFloat64(transferAmount.WithLabelValues("EUR")))
+	require.Equal(t, 2, testutil.CollectAndCount(transferTxDuration))
+}
+
+func TestStoreCountsOtherTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	store := NewStore(mock)
+
+	transfer := db.TransferTxResult{
+		Transfer:    db.Transfer{Amount: 40},
+		FromAccount: db.Account{Currency: "IDR"},
+	}
+	mock.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.AcceptPaymentRequestTxResult{Transfer: &transfer}, nil)
+	// Accepting a request that was already accepted pays nothing.
+	mock.EXPECT().AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.AcceptPaymentRequestTxResult{AlreadyAccepted: true}, nil)
+	mock.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ExecuteTransferBatchItemTxResult{Transfer: transfer}, nil)
+	mock.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ExecuteTransferBatchItemTxResult{}, db.ErrBatchItemNotValid)
+
+	countBefore := testutil.ToFloat64(transfers.WithLabelValues("IDR"))
+	amountBefore := testutil.ToFloat64(transferAmount.WithLabelValues("IDR"))
+
+	_, err := store.AcceptPaymentRequestTx(context.Background(), db.AcceptPaymentRequestTxParams{})
+	require.NoError(t, err)
+	_, err = store.AcceptPaymentRequestTx(context.Background(), db.AcceptPaymentRequestTxParams{})
+	require.NoError(t, err)
+	_, err = store.ExecuteTransferBatchItemTx(context.Background(), db.ExecuteTransferBatchItemTxParams{})
+	require.NoError(t, err)
+	_, err = store.ExecuteTransferBatchItemTx(context.Background(), db.ExecuteTransferBatchItemTxParams{})
+	require.ErrorIs(t, err, db.ErrBatchItemNotValid)
+
+	require.Equal(t, countBefore+2, testutil.ToFloat64(transfers.WithLabelValues("IDR")))
+	require.Equal(t, amountBefore+80, testutil.ToFloat64(transferAmount.WithLabelValues("IDR")))
+}
+
+func TestStorePassesThrough(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	store := NewStore(mock)
+
+	mock.EXPECT().GetAccount(gomock.Any(), int64(1)).Times(1).Return(db.Account{ID: 1}, nil)
+
+	account, err := store.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), account.ID)
+}
+
+func TestCountTxRetry(t *testing.T) {
+	retries := testutil.ToFloat64(dbTxRetries.WithLabelValues(db.TxRetryDeadlock))
+	exhausted := testutil.ToFloat64(dbTxRetriesExhausted.WithLabelValues(db.TxRetryDeadlock))
+
+	countTxRetry(db.TxRetryDeadlock, false)
+	countTxRetry(db.TxRetryDeadlock, false)
+	countTxRetry(db.TxRetryDeadlock, true)
+
+	require.Equal(t, retries+2, testutil.ToFloat64(dbTxRetries.WithLabelValues(db.TxRetryDeadlock)))
+	require.Equal(t, exhausted+1, testutil.ToFloat64(dbTxRetriesExhausted.WithLabelValues(db.TxRetryDeadlock)))
+}
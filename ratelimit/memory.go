@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memoryPruneSize = 10_000
+
+// MemoryStore keeps buckets in process. Each instance counts on its own, so
+// it only suits a single instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets map[string]*memoryBucket
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	period  time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if len(s.buckets) >= memoryPruneSize {
+		s.prune(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &memoryBucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	tokens, result := take(refill(b.tokens, now.Sub(b.updated), limit), limit)
+	b.tokens, b.updated, b.period = tokens, now, limit.Period
+	return result, nil
+}
+
+// prune drops buckets that have been idle long enough to be full again.
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) >= b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
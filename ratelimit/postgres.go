@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"context"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so every
+// instance of the API draws from the same ones. The refill and take happen
+// in one statement using the database clock.
+type PostgresStore struct {
+	store db.Store
+}
+
+func NewPostgresStore(store db.Store) *PostgresStore {
+	return &PostgresStore{store: store}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	row, err := s.store.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:           key,
+		Burst:         float64(limit.Burst),
+		PeriodSeconds: limit.Period.Seconds(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(row.Tokens, row.Allowed, limit), nil
+}
@@ -0,0 +1,105 @@
+// Package ratelimit implements token-bucket rate limits over pluggable
+// storage: in memory for a single instance, or in Postgres when several
+// instances must share the same buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Burst requests at once and refills the bucket evenly, so a
+// client that has used it all up gets Burst more over each Period.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Enabled reports whether l limits anything. The zero Limit does not.
+func (l Limit) Enabled() bool {
+	return l.Burst > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Burst, l.Period)
+}
+
+// rate is the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Burst) / l.Period.Seconds()
+}
+
+// ParseLimit reads a limit written as "<burst>/<period>", such as "5/1m".
+// "off" is the zero Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "off" {
+		return Limit{}, nil
+	}
+
+	burst, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q: want <burst>/<period>, such as 5/1m", s)
+	}
+
+	n, err := strconv.Atoi(burst)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: burst must be a positive number", s)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q: period must be a positive duration", s)
+	}
+
+	return Limit{Burst: n, Period: d}, nil
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next token, when not Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Take refills the bucket at key for the time since
+// it was last used and takes a token from it if one is available; it must be
+// atomic per key.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// take applies a request to a bucket holding tokens after refilling, and
+// returns what is left along with the result.
+func take(tokens float64, limit Limit) (float64, Result) {
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+	return tokens, newResult(tokens, allowed, limit)
+}
+
+// newResult describes a bucket left holding tokens.
+func newResult(tokens float64, allowed bool, limit Limit) Result {
+	result := Result{Allowed: allowed, Remaining: int(math.Max(tokens, 0))}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / limit.rate() * float64(time.Second))
+	}
+	return result
+}
+
+// refill returns the tokens in a bucket that held tokens elapsed ago.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.rate())
+}
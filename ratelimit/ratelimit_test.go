@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseLimit(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Limit
+		ok       bool
+	}{
+		{input: "5/1m", expected: Limit{Burst: 5, Period: time.Minute}, ok: true},
+		{input: "100/1h30m", expected: Limit{Burst: 100, Period: 90 * time.Minute}, ok: true},
+		{input: "off", expected: Limit{}, ok: true},
+		{input: "5", ok: false},
+		{input: "0/1m", ok: false},
+		{input: "five/1m", ok: false},
+		{input: "5/0s", ok: false},
+		{input: "5/minute", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			limit, err := ParseLimit(tc.input)
+			if !tc.ok {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestMemoryStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStoreBurstAndRefill(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	limit := Limit{Burst: 3, Period: time.Minute}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 20*time.Second, result.RetryAfter)
+
+	// Other keys have their own bucket.
+	result, err = store.Take(ctx, "ip:5.6.7.8", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	clock.now = clock.now.Add(15 * time.Second)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 5*time.Second, result.RetryAfter)
+
+	clock.now = clock.now.Add(5 * time.Second)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// A long idle spell refills no more than the burst.
+	clock.now = clock.now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	store, clock := newTestMemoryStore()
+	limit := Limit{Burst: 1, Period: time.Minute}
+
+	for i := 0; i < memoryPruneSize; i++ {
+		_, err := store.Take(context.Background(), fmt.Sprint("user:", i), limit)
+		require.NoError(t, err)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, err := store.Take(context.Background(), "user:new", limit)
+	require.NoError(t, err)
+	require.Len(t, store.buckets, 1)
+}
+
+func TestPostgresStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	store := NewPostgresStore(mock)
+	limit := Limit{Burst: 10, Period: time.Minute}
+	arg := db.TakeRateLimitTokenParams{Key: "user:alice", Burst: 10, PeriodSeconds: 60}
+
+	mock.EXPECT().TakeRateLimitToken(gomock.Any(), arg).Times(1).
+		Return(db.TakeRateLimitTokenRow{Tokens: 4.5, Allowed: true}, nil)
+	result, err := store.Take(context.Background(), "user:alice", limit)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: true, Remaining: 4}, result)
+
+	mock.EXPECT().TakeRateLimitToken(gomock.Any(), arg).Times(1).
+		Return(db.TakeRateLimitTokenRow{Tokens: 0.5, Allowed: false}, nil)
+	result, err = store.Take(context.Background(), "user:alice", limit)
+	require.NoError(t, err)
+	require.Equal(t, Result{Allowed: false, RetryAfter: 3 * time.Second}, result)
+
+	mock.EXPECT().TakeRateLimitToken(gomock.Any(), arg).Times(1).
+		Return(db.TakeRateLimitTokenRow{}, errors.New("connection refused"))
+	_, err = store.Take(context.Background(), "user:alice", limit)
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+const (
+	defaultChunkSize = 500
+
+	// maxStoredFindings caps how many findings are written to a run, so a
+	// badly broken ledger can't produce an unbounded jsonb column. The
+	// discrepancy count is always exact.
+	maxStoredFindings = 1000
+)
+
+const (
+	StatusRunning  = "running"
+	StatusBalanced = "balanced"
+	StatusDrift    = "drift"
+	StatusFailed   = "failed"
+)
+
+const (
+	KindBalanceDrift     = "account_balance_drift"
+	KindTransferMismatch = "transfer_entries_mismatch"
+)
+
+// Finding describes a single place where the ledger doesn't add up.
+type Finding struct {
+	Kind       string `json:"kind"`
+	AccountID  int64  `json:"account_id,omitempty"`
+	TransferID int64  `json:"transfer_id,omitempty"`
+	Expected   int64  `json:"expected"`
+	Actual     int64  `json:"actual"`
+	Detail     string `json:"detail"`
+}
+
+// Reconciler checks the double-entry invariants of the ledger:
+// every account balance equals the sum of its entries, and every transfer
+// has exactly one debit and one credit entry matching its amount, plus a
+// matching pair of fee entries when a fee was charged.
+type Reconciler struct {
+	store     db.Store
+	chunkSize int32
+}
+
+func NewReconciler(store db.Store, chunkSize int32) *Reconciler {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	return &Reconciler{
+		store:     store,
+		chunkSize: chunkSize,
+	}
+}
+
+type report struct {
+	accountsChecked  int64
+	transfersChecked int64
+	discrepancies    int64
+	findings         []Finding
+}
+
+func (r *report) add(f Finding) {
+	r.discrepancies++
+	if len(r.findings) < maxStoredFindings {
+		r.findings = append(r.findings, f)
+	}
+}
+
+// Run scans the whole ledger in chunks and records the outcome as a
+// reconciliation run. The returned run is the finished row.
+func (r *Reconciler) Run(ctx context.Context, triggeredBy string) (db.ReconciliationRun, error) {
+	run, err := r.store.CreateReconciliationRun(ctx, triggeredBy)
+	if err != nil {
+		return db.ReconciliationRun{}, fmt.Errorf("cannot start reconciliation run: %w", err)
+	}
+
+	rep := &report{findings: []Finding{}}
+	scanErr := r.checkAccounts(ctx, rep)
+	if scanErr == nil {
+		scanErr = r.checkTransfers(ctx, rep)
+	}
+
+	status := StatusBalanced
+	errMsg := ""
+	switch {
+	case scanErr != nil:
+		status = StatusFailed
+		errMsg = scanErr.Error()
+	case rep.discrepancies > 0:
+		status = StatusDrift
+	}
+
+	findings, err := json.Marshal(rep.findings)
+	if err != nil {
+		return run, err
+	}
+
+	// The scan may have been cut short by ctx; the run is still closed so it
+	// doesn't stay "running" forever.
+	run, err = r.store.FinishReconciliationRun(context.WithoutCancel(ctx), db.FinishReconciliationRunParams{
+		ID:               run.ID,
+		Status:           status,
+		AccountsChecked:  rep.accountsChecked,
+		TransfersChecked: rep.transfersChecked,
+		DiscrepancyCount: rep.discrepancies,
+		Findings:         findings,
+		Error:            errMsg,
+	})
+	if err != nil {
+		return run, fmt.Errorf("cannot finish reconciliation run: %w", err)
+	}
+
+	return run, scanErr
+}
+
+func (r *Reconciler) checkAccounts(ctx context.Context, rep *report) error {
+	var afterID int64
+	for {
+		rows, err := r.store.ListAccountLedgerTotals(ctx, db.ListAccountLedgerTotalsParams{
+			AfterID:   afterID,
+			ChunkSize: r.chunkSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot scan accounts after %d: %w", afterID, err)
+		}
+
+		for _, row := range rows {
+			rep.accountsChecked++
+			if row.Balance != row.EntriesTotal {
+				rep.add(Finding{
+					Kind:      KindBalanceDrift,
+					AccountID: row.ID,
+					Expected:  row.EntriesTotal,
+					Actual:    row.Balance,
+					Detail:    fmt.Sprintf("balance differs from sum of entries by %d", row.Balance-row.EntriesTotal),
+				})
+			}
+			afterID = row.ID
+		}
+
+		if len(rows) < int(r.chunkSize) {
+			return nil
+		}
+	}
+}
+
+func (r *Reconciler) checkTransfers(ctx context.Context, rep *report) error {
+	var afterID int64
+	for {
+		rows, err := r.store.ListTransferLedgerTotals(ctx, db.ListTransferLedgerTotalsParams{
+			AfterID:   afterID,
+			ChunkSize: r.chunkSize,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot scan transfers after %d: %w", afterID, err)
+		}
+
+		for _, row := range rows {
+			rep.transfersChecked++
+			if f, ok := checkTransfer(row); !ok {
+				rep.add(f)
+			}
+			afterID = row.ID
+		}
+
+		if len(rows) < int(r.chunkSize) {
+			return nil
+		}
+	}
+}
+
+func checkTransfer(row db.ListTransferLedgerTotalsRow) (Finding, bool) {
+	f := Finding{
+		Kind:       KindTransferMismatch,
+		TransferID: row.ID,
+	}
+
+	wantEntries := int64(2)
+	if row.Fee > 0 {
+		wantEntries = 4
+	}
+
+	switch {
+	case row.EntryCount != wantEntries:
+		f.Expected, f.Actual = wantEntries, row.EntryCount
+		f.Detail = fmt.Sprintf("transfer does not have exactly %d entries", wantEntries)
+	case row.FromTotal != -(row.Amount + row.Fee):
+		f.AccountID = row.FromAccountID
+		f.Expected, f.Actual = -(row.Amount + row.Fee), row.FromTotal
+		f.Detail = "debit entries do not match transfer amount and fee"
+	case row.ToTotal != row.Amount:
+		f.AccountID = row.ToAccountID
+		f.Expected, f.Actual = row.Amount, row.ToTotal
+		f.Detail = "credit entry does not match transfer amount"
+	case row.EntriesTotal != 0:
+		f.Expected, f.Actual = 0, row.EntriesTotal
+		f.Detail = "transfer entries do not sum to zero"
+	default:
+		return Finding{}, true
+	}
+
+	return f, false
+}
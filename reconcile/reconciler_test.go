@@ -0,0 +1,145 @@
+package reconcile
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReconcilerRun(t *testing.T) {
+	accountChunks := [][]db.ListAccountLedgerTotalsRow{
+		{
+			{ID: 1, Balance: 100, EntriesTotal: 100},
+			{ID: 2, Balance: 250, EntriesTotal: 200},
+		},
+		{
+			{ID: 3, Balance: 0, EntriesTotal: 0},
+		},
+	}
+	transferChunks := [][]db.ListTransferLedgerTotalsRow{
+		{
+			{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 50, EntryCount: 2, FromTotal: -50, ToTotal: 50},
+			{ID: 2, FromAccountID: 2, ToAccountID: 1, Amount: 10, EntryCount: 1, FromTotal: -10, ToTotal: 0},
+		},
+		{},
+	}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore) *db.FinishReconciliationRunParams
+		check      func(t *testing.T, run db.ReconciliationRun, finished *db.FinishReconciliationRunParams, err error)
+	}{
+		{
+			name: "Drift",
+			buildStubs: func(store *mockdb.MockStore) *db.FinishReconciliationRunParams {
+				var finished db.FinishReconciliationRunParams
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), "test").Times(1).
+					Return(db.ReconciliationRun{ID: 7, Status: StatusRunning}, nil)
+				gomock.InOrder(
+					store.EXPECT().ListAccountLedgerTotals(gomock.Any(), db.ListAccountLedgerTotalsParams{AfterID: 0, ChunkSize: 2}).Return(accountChunks[0], nil),
+					store.EXPECT().ListAccountLedgerTotals(gomock.Any(), db.ListAccountLedgerTotalsParams{AfterID: 2, ChunkSize: 2}).Return(accountChunks[1], nil),
+				)
+				gomock.InOrder(
+					store.EXPECT().ListTransferLedgerTotals(gomock.Any(), db.ListTransferLedgerTotalsParams{AfterID: 0, ChunkSize: 2}).Return(transferChunks[0], nil),
+					store.EXPECT().ListTransferLedgerTotals(gomock.Any(), db.ListTransferLedgerTotalsParams{AfterID: 2, ChunkSize: 2}).Return(transferChunks[1], nil),
+				)
+				store.EXPECT().FinishReconciliationRun(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.FinishReconciliationRunParams) (db.ReconciliationRun, error) {
+						finished = arg
+						return db.ReconciliationRun{ID: arg.ID, Status: arg.Status}, nil
+					})
+				return &finished
+			},
+			check: func(t *testing.T, run db.ReconciliationRun, finished *db.FinishReconciliationRunParams, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), run.ID)
+				require.Equal(t, StatusDrift, finished.Status)
+				require.Equal(t, int64(3), finished.AccountsChecked)
+				require.Equal(t, int64(2), finished.TransfersChecked)
+				require.Equal(t, int64(2), finished.DiscrepancyCount)
+
+				var findings []Finding
+				require.NoError(t, json.Unmarshal(finished.Findings, &findings))
+				require.Equal(t, []Finding{
+					{Kind: KindBalanceDrift, AccountID: 2, Expected: 200, Actual: 250, Detail: "balance differs from sum of entries by 50"},
+					{Kind: KindTransferMismatch, TransferID: 2, Expected: 2, Actual: 1, Detail: "transfer does not have exactly 2 entries"},
+				}, findings)
+			},
+		},
+		{
+			name: "ScanError",
+			buildStubs: func(store *mockdb.MockStore) *db.FinishReconciliationRunParams {
+				var finished db.FinishReconciliationRunParams
+				store.EXPECT().CreateReconciliationRun(gomock.Any(), "test").Times(1).
+					Return(db.ReconciliationRun{ID: 8}, nil)
+				store.EXPECT().ListAccountLedgerTotals(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().ListTransferLedgerTotals(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().FinishReconciliationRun(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.FinishReconciliationRunParams) (db.ReconciliationRun, error) {
+						finished = arg
+						return db.ReconciliationRun{ID: arg.ID, Status: arg.Status}, nil
+					})
+				return &finished
+			},
+			check: func(t *testing.T, run db.ReconciliationRun, finished *db.FinishReconciliationRunParams, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Equal(t, StatusFailed, finished.Status)
+				require.NotEmpty(t, finished.Error)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			finished := tc.buildStubs(store)
+
+			run, err := NewReconciler(store, 2).Run(context.Background(), "test")
+			tc.check(t, run, finished, err)
+		})
+	}
+}
+
+func TestCheckTransfer(t *testing.T) {
+	row := db.ListTransferLedgerTotalsRow{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 30, EntryCount: 2, FromTotal: -30, ToTotal: 30}
+	_, ok := checkTransfer(row)
+	require.True(t, ok)
+
+	row.ToTotal = 20
+	row.EntriesTotal = -10
+	f, ok := checkTransfer(row)
+	require.False(t, ok)
+	require.Equal(t, int64(2), f.AccountID)
+	require.Equal(t, int64(30), f.Expected)
+	require.Equal(t, int64(20), f.Actual)
+}
+
+func TestCheckTransferWithFee(t *testing.T) {
+	// 30 sent with a fee of 2: sender -32, receiver +30, revenue +2.
+	row := db.ListTransferLedgerTotalsRow{ID: 1, FromAccountID: 1, ToAccountID: 2, Amount: 30, Fee: 2, EntryCount: 4, EntriesTotal: 0, FromTotal: -32, ToTotal: 30}
+	_, ok := checkTransfer(row)
+	require.True(t, ok)
+
+	// The revenue entry is missing.
+	row.EntryCount = 3
+	row.EntriesTotal = -2
+	f, ok := checkTransfer(row)
+	require.False(t, ok)
+	require.Equal(t, int64(4), f.Expected)
+
+	// The revenue entry credited the wrong amount.
+	row.EntryCount = 4
+	row.EntriesTotal = 1
+	f, ok = checkTransfer(row)
+	require.False(t, ok)
+	require.Equal(t, "transfer entries do not sum to zero", f.Detail)
+}
@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+)
+
+const defaultChunkSize = 500
+
+// Job writes end-of-day balances into account_balance_snapshots. A snapshot
+// for date D holds the balance after every entry created before midnight UTC
+// at the end of D. Re-running a date overwrites its rows, so the job is
+// idempotent per date.
+type Job struct {
+	store     db.Store
+	chunkSize int32
+}
+
+func NewJob(store db.Store, chunkSize int32) *Job {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	return &Job{
+		store:     store,
+		chunkSize: chunkSize,
+	}
+}
+
+// Day truncates t to the start of its UTC calendar day.
+func Day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Run snapshots every account for the given date and returns how many rows
+// were written.
+func (job *Job) Run(ctx context.Context, date time.Time) (int64, error) {
+	date = Day(date)
+	cutoff := date.AddDate(0, 0, 1)
+
+	var written int64
+	var afterID int64
+	for {
+		ids, err := job.store.UpsertBalanceSnapshots(ctx, db.UpsertBalanceSnapshotsParams{
+			SnapshotDate: date,
+			Cutoff:       cutoff,
+			AfterID:      afterID,
+			ChunkSize:    job.chunkSize,
+		})
+		if err != nil {
+			return written, fmt.Errorf("cannot snapshot %s after account %d: %w", date.Format(time.DateOnly), afterID, err)
+		}
+
+		written += int64(len(ids))
+		if len(ids) < int(job.chunkSize) {
+			return written, nil
+		}
+		// RETURNING does not keep the ORDER BY of the SELECT, but the chunk
+		// is still the lowest ids after the cursor, so the next one starts
+		// after the highest.
+		afterID = slices.Max(ids)
+	}
+}
+
+// RunPreviousDay snapshots the last full UTC day before now. It is meant to be
+// scheduled a few times a day; later runs simply refresh the same rows.
+func (job *Job) RunPreviousDay(ctx context.Context, now time.Time) (int64, error) {
+	return job.Run(ctx, Day(now).AddDate(0, 0, -1))
+}
+
+// Balance is the balance of an account at a point in time.
+type Balance struct {
+	AccountID    int64      `json:"account_id"`
+	Currency     string     `json:"currency"`
+	At           time.Time  `json:"at"`
+	Balance      int64      `json:"balance"`
+	SnapshotDate *time.Time `json:"snapshot_date"`
+}
+
+// BalanceAt returns the balance of account right after at. It starts from the
+// closest snapshot taken before at and adds the entries since; without a
+// snapshot it walks back from the current balance instead.
+func BalanceAt(ctx context.Context, store db.Store, account db.Account, at time.Time) (Balance, error) {
+	result := Balance{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		At:        at,
+	}
+
+	// Postgres keeps microseconds, so this makes the range inclusive of at.
+	end := at.Add(time.Microsecond)
+
+	snap, err := store.GetLatestBalanceSnapshot(ctx, db.GetLatestBalanceSnapshotParams{
+		AccountID:  account.ID,
+		BeforeDate: Day(at),
+	})
+	switch {
+	case err == nil:
+		since, err := store.SumEntriesInRange(ctx, db.SumEntriesInRangeParams{
+			AccountID: account.ID,
+			FromTime:  snap.SnapshotDate.AddDate(0, 0, 1),
+			ToTime:    end,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Balance = snap.Balance + since
+		result.SnapshotDate = &snap.SnapshotDate
+	case err == sql.ErrNoRows:
+		after, err := store.SumEntriesSince(ctx, db.SumEntriesSinceParams{
+			AccountID: account.ID,
+			FromTime:  end,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Balance = account.Balance - after
+	default:
+		return result, err
+	}
+
+	return result, nil
+}
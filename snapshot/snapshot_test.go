@@ -0,0 +1,102 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	cutoff := date.AddDate(0, 0, 1)
+
+	// Rows come back from RETURNING in no particular order.
+	gomock.InOrder(
+		store.EXPECT().UpsertBalanceSnapshots(gomock.Any(), db.UpsertBalanceSnapshotsParams{
+			SnapshotDate: date, Cutoff: cutoff, AfterID: 0, ChunkSize: 2,
+		}).Return([]int64{2, 1}, nil),
+		store.EXPECT().UpsertBalanceSnapshots(gomock.Any(), db.UpsertBalanceSnapshotsParams{
+			SnapshotDate: date, Cutoff: cutoff, AfterID: 2, ChunkSize: 2,
+		}).Return([]int64{5}, nil),
+	)
+
+	written, err := NewJob(store, 2).Run(context.Background(), date.Add(15*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), written)
+}
+
+func TestRunPreviousDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	now := time.Date(2026, 3, 1, 0, 30, 0, 0, time.UTC)
+	store.EXPECT().UpsertBalanceSnapshots(gomock.Any(), db.UpsertBalanceSnapshotsParams{
+		SnapshotDate: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		Cutoff:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		ChunkSize:    defaultChunkSize,
+	}).Times(1).Return([]int64{}, nil)
+
+	_, err := NewJob(store, 0).RunPreviousDay(context.Background(), now)
+	require.NoError(t, err)
+}
+
+func TestBalanceAt(t *testing.T) {
+	account := db.Account{ID: 9, Balance: 500, Currency: "USD"}
+	at := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("FromSnapshot", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		snapDate := time.Date(2026, 3, 12, 0, 0, 0, 0, time.UTC)
+		store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), db.GetLatestBalanceSnapshotParams{
+			AccountID: account.ID, BeforeDate: Day(at),
+		}).Return(db.AccountBalanceSnapshot{AccountID: account.ID, SnapshotDate: snapDate, Balance: 300}, nil)
+		store.EXPECT().SumEntriesInRange(gomock.Any(), db.SumEntriesInRangeParams{
+			AccountID: account.ID, FromTime: snapDate.AddDate(0, 0, 1), ToTime: at.Add(time.Microsecond),
+		}).Return(int64(-40), nil)
+		store.EXPECT().SumEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+
+		balance, err := BalanceAt(context.Background(), store, account, at)
+		require.NoError(t, err)
+		require.Equal(t, int64(260), balance.Balance)
+		require.NotNil(t, balance.SnapshotDate)
+		require.Equal(t, snapDate, *balance.SnapshotDate)
+	})
+
+	t.Run("WithoutSnapshot", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+			Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+		store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{
+			AccountID: account.ID, FromTime: at.Add(time.Microsecond),
+		}).Return(int64(120), nil)
+
+		balance, err := BalanceAt(context.Background(), store, account, at)
+		require.NoError(t, err)
+		require.Equal(t, int64(380), balance.Balance)
+		require.Nil(t, balance.SnapshotDate)
+	})
+
+	t.Run("StoreError", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		store := mockdb.NewMockStore(ctrl)
+
+		store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+			Return(db.AccountBalanceSnapshot{}, sql.ErrConnDone)
+
+		_, err := BalanceAt(context.Background(), store, account, at)
+		require.ErrorIs(t, err, sql.ErrConnDone)
+	})
+}
@@ -0,0 +1,166 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// camt053MaxIDLength is the length of Max35Text, which ISO 20022 uses for
+// message, statement and end-to-end ids.
+const camt053MaxIDLength = 35
+
+// camt053Encoder writes an ISO 20022 BankToCustomerStatement (camt.053.001.02)
+// with a single statement. Amounts are always positive; the direction goes in
+// CdtDbtInd.
+type camt053Encoder struct {
+	w *xmlWriter
+}
+
+func newCamt053Encoder(w io.Writer) encoder {
+	return &camt053Encoder{w: newXMLWriter(w)}
+}
+
+func camtDateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func camtDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func creditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+func (enc *camt053Encoder) begin(st Statement) error {
+	w := enc.w
+	w.procInst("xml", `version="1.0" encoding="UTF-8"`)
+	w.token(xml.StartElement{Name: xml.Name{Space: camt053Namespace, Local: "Document"}})
+	w.start("BkToCstmrStmt")
+
+	w.start("GrpHdr")
+	w.leaf("MsgId", fmt.Sprintf("STMT-%d-%d", st.Account.ID, st.GeneratedAt.Unix()))
+	w.leaf("CreDtTm", camtDateTime(st.GeneratedAt))
+	w.end("GrpHdr")
+
+	w.start("Stmt")
+	w.leaf("Id", fmt.Sprintf("%d-%s", st.Account.ID, st.From.UTC().Format("20060102")))
+	w.leaf("CreDtTm", camtDateTime(st.GeneratedAt))
+	w.start("FrToDt")
+	w.leaf("FrDtTm", camtDateTime(st.From))
+	w.leaf("ToDtTm", camtDateTime(st.To))
+	w.end("FrToDt")
+
+	w.start("Acct")
+	w.start("Id")
+	w.start("Othr")
+	w.leaf("Id", st.Account.AccountNumber)
+	w.end("Othr")
+	w.end("Id")
+	w.leaf("Ccy", st.Account.Currency)
+	w.end("Acct")
+
+	enc.balance(st, "OPBD", st.Opening, st.From)
+	enc.balance(st, "CLBD", st.Closing, st.To.AddDate(0, 0, -1))
+	return w.err
+}
+
+func (enc *camt053Encoder) balance(st Statement, code string, amount int64, date time.Time) {
+	w := enc.w
+	w.start("Bal")
+	w.start("Tp")
+	w.start("CdOrPrtry")
+	w.leaf("Cd", code)
+	w.end("CdOrPrtry")
+	w.end("Tp")
+	w.leaf("Amt", util.FormatAmount(abs(amount)), attr("Ccy", st.Account.Currency))
+	w.leaf("CdtDbtInd", creditDebit(amount))
+	w.start("Dt")
+	w.leaf("Dt", camtDate(date))
+	w.end("Dt")
+	w.end("Bal")
+}
+
+func (enc *camt053Encoder) entry(st Statement, e db.ListStatementEntriesRow, balance int64) error {
+	w := enc.w
+	id := strconv.FormatInt(e.ID, 10)
+
+	w.start("Ntry")
+	w.leaf("NtryRef", id)
+	w.leaf("Amt", util.FormatAmount(abs(e.Amount)), attr("Ccy", st.Account.Currency))
+	w.leaf("CdtDbtInd", creditDebit(e.Amount))
+	w.leaf("Sts", "BOOK")
+	w.start("BookgDt")
+	w.leaf("DtTm", camtDateTime(e.CreatedAt))
+	w.end("BookgDt")
+	w.leaf("AcctSvcrRef", id)
+	w.start("BkTxCd")
+	w.start("Prtry")
+	if e.TransferID != nil {
+		w.leaf("Cd", "TRANSFER")
+	} else {
+		w.leaf("Cd", "ADJUSTMENT")
+	}
+	w.end("Prtry")
+	w.end("BkTxCd")
+
+	if e.Memo != "" || e.Reference != "" {
+		enc.details(e)
+	}
+	w.end("Ntry")
+	return w.err
+}
+
+// details writes the reference and memo. References longer than an
+// EndToEndId can hold go into the unstructured remittance lines instead.
+func (enc *camt053Encoder) details(e db.ListStatementEntriesRow) {
+	w := enc.w
+	var lines []string
+	if e.Memo != "" {
+		lines = append(lines, e.Memo)
+	}
+
+	w.start("NtryDtls")
+	w.start("TxDtls")
+	switch {
+	case e.Reference == "":
+	case len(e.Reference) <= camt053MaxIDLength:
+		w.start("Refs")
+		w.leaf("EndToEndId", e.Reference)
+		w.end("Refs")
+	default:
+		lines = append(lines, "Ref: "+e.Reference)
+	}
+	if len(lines) > 0 {
+		w.start("RmtInf")
+		for _, line := range lines {
+			w.leaf("Ustrd", line)
+		}
+		w.end("RmtInf")
+	}
+	w.end("TxDtls")
+	w.end("NtryDtls")
+}
+
+func (enc *camt053Encoder) end(st Statement) error {
+	w := enc.w
+	w.end("Stmt")
+	w.end("BkToCstmrStmt")
+	w.token(xml.EndElement{Name: xml.Name{Space: camt053Namespace, Local: "Document"}})
+	return w.err
+}
+
+func (enc *camt053Encoder) flush() error {
+	return enc.w.flush()
+}
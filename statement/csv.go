@@ -0,0 +1,67 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+var csvHeader = []string{
+	"date", "entry_id", "transfer_id", "counterparty_account_id",
+	"reference", "memo", "amount", "balance",
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func newCSVEncoder(w io.Writer) encoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (enc *csvEncoder) begin(st Statement) error {
+	if err := enc.w.Write(csvHeader); err != nil {
+		return err
+	}
+	return enc.balanceRow(st.From, "Opening balance", st.Opening)
+}
+
+func (enc *csvEncoder) entry(st Statement, e db.ListStatementEntriesRow, balance int64) error {
+	var transferID, counterparty string
+	if e.TransferID != nil {
+		transferID = strconv.FormatInt(*e.TransferID, 10)
+	}
+	if e.CounterpartyAccountID != 0 {
+		counterparty = strconv.FormatInt(e.CounterpartyAccountID, 10)
+	}
+
+	return enc.w.Write([]string{
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(e.ID, 10),
+		transferID,
+		counterparty,
+		util.CSVText(e.Reference),
+		util.CSVText(e.Memo),
+		util.FormatAmount(e.Amount),
+		util.FormatAmount(balance),
+	})
+}
+
+func (enc *csvEncoder) end(st Statement) error {
+	return enc.balanceRow(st.To, "Closing balance", st.Closing)
+}
+
+func (enc *csvEncoder) flush() error {
+	enc.w.Flush()
+	return enc.w.Error()
+}
+
+func (enc *csvEncoder) balanceRow(at time.Time, label string, balance int64) error {
+	return enc.w.Write([]string{
+		at.UTC().Format(time.RFC3339), "", "", "", "", label, "", util.FormatAmount(balance),
+	})
+}
@@ -0,0 +1,150 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+// ofxBankID identifies the bank in BANKACCTFROM. OFX caps it at nine
+// characters.
+const ofxBankID = "SIMPLEBNK"
+
+const ofxHeader = `OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`
+
+// The OFX 2 schema declares only the root element in its namespace; every
+// aggregate below it is unqualified.
+const (
+	ofxNamespace = "http://ofx.net/types/2003/04"
+	ofxRoot      = "ofx:OFX"
+)
+
+// ofxEncoder writes an OFX 2.2 bank statement response. OFX has no opening
+// balance aggregate, so it goes into BALLIST next to the closing LEDGERBAL.
+type ofxEncoder struct {
+	w *xmlWriter
+}
+
+func newOFXEncoder(w io.Writer) encoder {
+	return &ofxEncoder{w: newXMLWriter(w)}
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+func ofxAccountType(accountType string) string {
+	if accountType == util.SavingsAccount {
+		return "SAVINGS"
+	}
+	return "CHECKING"
+}
+
+func (enc *ofxEncoder) status() {
+	w := enc.w
+	w.start("STATUS")
+	w.leaf("CODE", "0")
+	w.leaf("SEVERITY", "INFO")
+	w.end("STATUS")
+}
+
+func (enc *ofxEncoder) begin(st Statement) error {
+	w := enc.w
+	w.procInst("xml", `version="1.0" encoding="UTF-8" standalone="no"`)
+	w.procInst("OFX", ofxHeader)
+	w.start(ofxRoot, attr("xmlns:ofx", ofxNamespace))
+
+	w.start("SIGNONMSGSRSV1")
+	w.start("SONRS")
+	enc.status()
+	w.leaf("DTSERVER", ofxTime(st.GeneratedAt))
+	w.leaf("LANGUAGE", "ENG")
+	w.end("SONRS")
+	w.end("SIGNONMSGSRSV1")
+
+	w.start("BANKMSGSRSV1")
+	w.start("STMTTRNRS")
+	w.leaf("TRNUID", strconv.FormatInt(st.GeneratedAt.UnixNano(), 10))
+	enc.status()
+	w.start("STMTRS")
+	w.leaf("CURDEF", st.Account.Currency)
+	w.start("BANKACCTFROM")
+	w.leaf("BANKID", ofxBankID)
+	w.leaf("ACCTID", st.Account.AccountNumber)
+	w.leaf("ACCTTYPE", ofxAccountType(st.Account.AccountType))
+	w.end("BANKACCTFROM")
+
+	w.start("BANKTRANLIST")
+	w.leaf("DTSTART", ofxTime(st.From))
+	w.leaf("DTEND", ofxTime(st.To))
+	return w.err
+}
+
+func (enc *ofxEncoder) entry(st Statement, e db.ListStatementEntriesRow, balance int64) error {
+	w := enc.w
+	trnType := "CREDIT"
+	if e.Amount < 0 {
+		trnType = "DEBIT"
+	}
+
+	w.start("STMTTRN")
+	w.leaf("TRNTYPE", trnType)
+	w.leaf("DTPOSTED", ofxTime(e.CreatedAt))
+	w.leaf("TRNAMT", util.FormatAmount(e.Amount))
+	w.leaf("FITID", strconv.FormatInt(e.ID, 10))
+	if e.CounterpartyAccountID != 0 {
+		w.leaf("NAME", fmt.Sprintf("Account %d", e.CounterpartyAccountID))
+	}
+	if memo := ofxMemo(e); memo != "" {
+		w.leaf("MEMO", memo)
+	}
+	w.end("STMTTRN")
+	return w.err
+}
+
+// ofxMemo folds the reference into MEMO because REFNUM is too short to hold
+// every reference the API accepts.
+func ofxMemo(e db.ListStatementEntriesRow) string {
+	switch {
+	case e.Reference == "":
+		return e.Memo
+	case e.Memo == "":
+		return "Ref: " + e.Reference
+	default:
+		return e.Memo + " / Ref: " + e.Reference
+	}
+}
+
+func (enc *ofxEncoder) end(st Statement) error {
+	w := enc.w
+	w.end("BANKTRANLIST")
+
+	w.start("LEDGERBAL")
+	w.leaf("BALAMT", util.FormatAmount(st.Closing))
+	w.leaf("DTASOF", ofxTime(st.To))
+	w.end("LEDGERBAL")
+
+	w.start("BALLIST")
+	w.start("BAL")
+	w.leaf("NAME", "Opening balance")
+	w.leaf("DESC", "Balance at the start of the period")
+	w.leaf("BALTYPE", "DOLLAR")
+	w.leaf("VALUE", util.FormatAmount(st.Opening))
+	w.leaf("DTASOF", ofxTime(st.From))
+	w.end("BAL")
+	w.end("BALLIST")
+
+	w.end("STMTRS")
+	w.end("STMTTRNRS")
+	w.end("BANKMSGSRSV1")
+	w.end(ofxRoot)
+	return w.err
+}
+
+func (enc *ofxEncoder) flush() error {
+	return enc.w.flush()
+}
@@ -0,0 +1,176 @@
+package statement
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/snapshot"
+)
+
+const defaultChunkSize = 500
+
+// Supported statement formats.
+const (
+	FormatCSV     = "csv"
+	FormatOFX     = "ofx"
+	FormatCamt053 = "camt053"
+)
+
+// Statement describes the period an export covers. From is inclusive and To
+// is exclusive; Opening and Closing are the balances right before each.
+type Statement struct {
+	Account     db.Account
+	From        time.Time
+	To          time.Time
+	Opening     int64
+	Closing     int64
+	GeneratedAt time.Time
+}
+
+// New looks up the opening and closing balances of account for [from, to).
+func New(ctx context.Context, store db.Store, account db.Account, from, to time.Time) (Statement, error) {
+	st := Statement{
+		Account:     account,
+		From:        from,
+		To:          to,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	// BalanceAt includes entries made at exactly at, so step back by the
+	// smallest interval Postgres can tell apart.
+	opening, err := snapshot.BalanceAt(ctx, store, account, from.Add(-time.Microsecond))
+	if err != nil {
+		return st, fmt.Errorf("opening balance: %w", err)
+	}
+	closing, err := snapshot.BalanceAt(ctx, store, account, to.Add(-time.Microsecond))
+	if err != nil {
+		return st, fmt.Errorf("closing balance: %w", err)
+	}
+
+	st.Opening = opening.Balance
+	st.Closing = closing.Balance
+	return st, nil
+}
+
+// encoder renders one statement format. Entries arrive in id order together
+// with the balance right after each one.
+type encoder interface {
+	begin(st Statement) error
+	entry(st Statement, e db.ListStatementEntriesRow, balance int64) error
+	end(st Statement) error
+	// flush pushes buffered output through to the underlying writer.
+	flush() error
+}
+
+type format struct {
+	contentType string
+	extension   string
+	newEncoder  func(w io.Writer) encoder
+}
+
+var formats = map[string]format{
+	FormatCSV:     {"text/csv; charset=utf-8", "csv", newCSVEncoder},
+	FormatOFX:     {"application/x-ofx", "ofx", newOFXEncoder},
+	FormatCamt053: {"application/xml", "xml", newCamt053Encoder},
+}
+
+func IsValidFormat(name string) bool {
+	_, ok := formats[name]
+	return ok
+}
+
+// ContentType returns the MIME type of a format.
+func ContentType(name string) string {
+	return formats[name].contentType
+}
+
+// Filename suggests a download name for the statement in the given format.
+func Filename(name string, st Statement) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		st.Account.ID,
+		st.From.Format("20060102"),
+		st.To.AddDate(0, 0, -1).Format("20060102"),
+		formats[name].extension,
+	)
+}
+
+// Writer streams statements, reading entries from the store in chunks so the
+// whole period never has to be held in memory.
+type Writer struct {
+	store     db.Store
+	chunkSize int32
+}
+
+func NewWriter(store db.Store, chunkSize int32) *Writer {
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	return &Writer{
+		store:     store,
+		chunkSize: chunkSize,
+	}
+}
+
+// Write renders st to w in the given format. If w can be flushed, it is
+// flushed after every chunk so the client starts receiving rows early.
+func (writer *Writer) Write(ctx context.Context, w io.Writer, name string, st Statement) error {
+	f, ok := formats[name]
+	if !ok {
+		return fmt.Errorf("unsupported statement format %q", name)
+	}
+
+	enc := f.newEncoder(w)
+	if err := enc.begin(st); err != nil {
+		return err
+	}
+
+	balance := st.Opening
+	var afterID int64
+	for {
+		rows, err := writer.store.ListStatementEntries(ctx, db.ListStatementEntriesParams{
+			AccountID: st.Account.ID,
+			FromTime:  st.From,
+			ToTime:    st.To,
+			AfterID:   afterID,
+			Limit:     writer.chunkSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			balance += row.Amount
+			if err := enc.entry(st, row, balance); err != nil {
+				return err
+			}
+			afterID = row.ID
+		}
+
+		if err := enc.flush(); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+
+		if len(rows) < int(writer.chunkSize) {
+			break
+		}
+	}
+
+	if err := enc.end(st); err != nil {
+		return err
+	}
+	return enc.flush()
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,190 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	testFrom = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	testTo   = time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func testStatement() Statement {
+	return Statement{
+		Account: db.Account{
+			ID:            7,
+			Currency:      "USD",
+			AccountType:   "checking",
+			AccountNumber: "123456789015",
+		},
+		From:        testFrom,
+		To:          testTo,
+		Opening:     10000,
+		Closing:     8745,
+		GeneratedAt: time.Date(2026, 4, 2, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func testEntries() []db.ListStatementEntriesRow {
+	transferID := int64(40)
+	return []db.ListStatementEntriesRow{
+		{
+			ID: 101, Amount: -2500, TransferID: &transferID, CounterpartyAccountID: 8,
+			Memo: "March rent", Reference: "INV-2026-03", CreatedAt: testFrom.Add(2 * time.Hour),
+		},
+		{
+			ID: 102, Amount: -5, TransferID: &transferID, CounterpartyAccountID: 8,
+			Memo: "Transfer fee", Reference: "INV-2026-03", CreatedAt: testFrom.Add(2 * time.Hour),
+		},
+		{
+			ID: 110, Amount: 1250, CreatedAt: testFrom.Add(72 * time.Hour),
+			Memo: "=cmd & <script>", Reference: strings.Repeat("R", 64),
+		},
+	}
+}
+
+// expectEntries serves rows to the writer two at a time.
+func expectEntries(store *mockdb.MockStore, rows []db.ListStatementEntriesRow) {
+	gomock.InOrder(
+		store.EXPECT().ListStatementEntries(gomock.Any(), db.ListStatementEntriesParams{
+			AccountID: 7, FromTime: testFrom, ToTime: testTo, AfterID: 0, Limit: 2,
+		}).Return(rows[:2], nil),
+		store.EXPECT().ListStatementEntries(gomock.Any(), db.ListStatementEntriesParams{
+			AccountID: 7, FromTime: testFrom, ToTime: testTo, AfterID: 102, Limit: 2,
+		}).Return(rows[2:], nil),
+	)
+}
+
+func writeStatement(t *testing.T, format string) []byte {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	expectEntries(store, testEntries())
+
+	var buf bytes.Buffer
+	err := NewWriter(store, 2).Write(context.Background(), &buf, format, testStatement())
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+// validateXML checks doc against one of the schemas in testdata with
+// xmllint, which is the only XSD validator available without cgo. A missing
+// xmllint fails the test: skipping would let invalid documents pass CI.
+func validateXML(t *testing.T, doc []byte, schema string) {
+	xmllint, err := exec.LookPath("xmllint")
+	require.NoError(t, err, "xmllint is needed to validate statements; install libxml2-utils")
+
+	path := filepath.Join(t.TempDir(), "statement.xml")
+	require.NoError(t, os.WriteFile(path, doc, 0o600))
+
+	out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", schema), path).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+const (
+	ofxSchema     = "ofx2_statement.xsd"
+	camt053Schema = "camt.053.001.02.xsd"
+)
+
+func TestNew(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	account := db.Account{ID: 7, Balance: 9000, Currency: "USD"}
+
+	store.EXPECT().GetLatestBalanceSnapshot(gomock.Any(), gomock.Any()).
+		Times(2).Return(db.AccountBalanceSnapshot{}, sql.ErrNoRows)
+	gomock.InOrder(
+		store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{
+			AccountID: 7, FromTime: testFrom,
+		}).Return(int64(-1000), nil),
+		store.EXPECT().SumEntriesSince(gomock.Any(), db.SumEntriesSinceParams{
+			AccountID: 7, FromTime: testTo,
+		}).Return(int64(250), nil),
+	)
+
+	st, err := New(context.Background(), store, account, testFrom, testTo)
+	require.NoError(t, err)
+	require.Equal(t, int64(10000), st.Opening)
+	require.Equal(t, int64(8750), st.Closing)
+}
+
+func TestWriteCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeStatement(t, FormatCSV))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, []string{"2026-03-01T00:00:00Z", "", "", "", "", "Opening balance", "", "100.00"}, records[1])
+	require.Equal(t, []string{"2026-03-01T02:00:00Z", "101", "40", "8", "INV-2026-03", "March rent", "-25.00", "75.00"}, records[2])
+	require.Equal(t, "74.95", records[3][7])
+	require.Equal(t, "'=cmd & <script>", records[4][5])
+	require.Equal(t, "87.45", records[4][7])
+	require.Equal(t, []string{"2026-04-01T00:00:00Z", "", "", "", "", "Closing balance", "", "87.45"}, records[5])
+}
+
+func TestWriteOFX(t *testing.T) {
+	doc := writeStatement(t, FormatOFX)
+
+	require.Contains(t, string(doc), `<?OFX OFXHEADER="200" VERSION="220"`)
+	require.Contains(t, string(doc), `<ofx:OFX xmlns:ofx="http://ofx.net/types/2003/04">`)
+	require.Contains(t, string(doc), "<TRNAMT>-25.00</TRNAMT>")
+	require.Contains(t, string(doc), "<MEMO>March rent / Ref: INV-2026-03</MEMO>")
+	require.Contains(t, string(doc), "<BALAMT>87.45</BALAMT>")
+	require.Contains(t, string(doc), "<VALUE>100.00</VALUE>")
+	require.Contains(t, string(doc), "&lt;script&gt;")
+
+	validateXML(t, doc, ofxSchema)
+}
+
+func TestWriteCamt053(t *testing.T) {
+	doc := writeStatement(t, FormatCamt053)
+
+	require.Contains(t, string(doc), `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">`)
+	require.Contains(t, string(doc), "<Cd>OPBD</Cd>")
+	require.Contains(t, string(doc), `<Amt Ccy="USD">25.00</Amt>`)
+	require.Contains(t, string(doc), "<CdtDbtInd>DBIT</CdtDbtInd>")
+	require.Contains(t, string(doc), "<EndToEndId>INV-2026-03</EndToEndId>")
+	require.Contains(t, string(doc), "<Ustrd>Ref: "+strings.Repeat("R", 64)+"</Ustrd>")
+
+	validateXML(t, doc, camt053Schema)
+}
+
+func TestWriteEmptyPeriod(t *testing.T) {
+	for _, format := range []string{FormatOFX, FormatCamt053} {
+		t.Run(format, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListStatementEntries(gomock.Any(), gomock.Any()).
+				Times(1).Return([]db.ListStatementEntriesRow{}, nil)
+
+			var buf bytes.Buffer
+			err := NewWriter(store, 0).Write(context.Background(), &buf, format, testStatement())
+			require.NoError(t, err)
+
+			schema := ofxSchema
+			if format == FormatCamt053 {
+				schema = camt053Schema
+			}
+			validateXML(t, buf.Bytes(), schema)
+		})
+	}
+}
+
+func TestFilename(t *testing.T) {
+	require.Equal(t, "statement-7-20260301-20260331.xml", Filename(FormatCamt053, testStatement()))
+	require.True(t, IsValidFormat(FormatOFX))
+	require.False(t, IsValidFormat("pdf"))
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// xmlWriter wraps xml.Encoder with the handful of calls the XML formats need.
+// The first error sticks and every later call becomes a no-op, so encoders can
+// write a whole element tree and check once at the end.
+type xmlWriter struct {
+	enc *xml.Encoder
+	err error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &xmlWriter{enc: enc}
+}
+
+func (w *xmlWriter) token(t xml.Token) {
+	if w.err == nil {
+		w.err = w.enc.EncodeToken(t)
+	}
+}
+
+func (w *xmlWriter) procInst(target, inst string) {
+	w.token(xml.ProcInst{Target: target, Inst: []byte(inst)})
+}
+
+func (w *xmlWriter) start(name string, attrs ...xml.Attr) {
+	w.token(xml.StartElement{Name: xml.Name{Local: name}, Attr: attrs})
+}
+
+func (w *xmlWriter) end(name string) {
+	w.token(xml.EndElement{Name: xml.Name{Local: name}})
+}
+
+func (w *xmlWriter) leaf(name, value string, attrs ...xml.Attr) {
+	w.start(name, attrs...)
+	w.token(xml.CharData(value))
+	w.end(name)
+}
+
+func (w *xmlWriter) flush() error {
+	if w.err == nil {
+		w.err = w.enc.Flush()
+	}
+	return w.err
+}
+
+func attr(name, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: name}, Value: value}
+}
@@ -14,6 +14,7 @@ var (
 	ErrAuthNotProvided = errors.New("authentication is not provided")
 	ErrActionForbidden = errors.New("action is forbidden")
 	ErrDoesNotBelong = errors.New("this resource doesn't belong to logged user")
+	ErrAdminOnly = errors.New("this action requires an admin")
 )
 
 type Payload struct {
@@ -0,0 +1,69 @@
+package tracing
+
+import (
+	"context"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Store decorates a db.Store with a span around each transaction. The
+// queries themselves, inside a transaction or not, get their own spans from
+// the store, so they nest under these.
+type Store struct {
+	db.Store
+}
+
+// NewStore returns store instrumented with spans.
+func NewStore(store db.Store) db.Store {
+	return &Store{Store: store}
+}
+
+func traced[P any, R any](ctx context.Context, name string, args P, fn func(context.Context, P) (R, error)) (R, error) {
+	ctx, span := tracer.Start(ctx, "store."+name, trace.WithSpanKind(trace.SpanKindInternal))
+	defer span.End()
+
+	result, err := fn(ctx, args)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return result, err
+}
+
+func (store *Store) TransferTx(ctx context.Context, args db.TransferTxParams) (db.TransferTxResult, error) {
+	return traced(ctx, "TransferTx", args, store.Store.TransferTx)
+}
+
+func (store *Store) PostInterestTx(ctx context.Context, args db.PostInterestTxParams) (db.PostInterestTxResult, error) {
+	return traced(ctx, "PostInterestTx", args, store.Store.PostInterestTx)
+}
+
+func (store *Store) AcceptPaymentRequestTx(ctx context.Context, args db.AcceptPaymentRequestTxParams) (db.AcceptPaymentRequestTxResult, error) {
+	return traced(ctx, "AcceptPaymentRequestTx", args, store.Store.AcceptPaymentRequestTx)
+}
+
+func (store *Store) CreateTransferBatchTx(ctx context.Context, args db.CreateTransferBatchTxParams) (db.TransferBatchTxResult, error) {
+	return traced(ctx, "CreateTransferBatchTx", args, store.Store.CreateTransferBatchTx)
+}
+
+func (store *Store) ExecuteTransferBatchItemTx(ctx context.Context, args db.ExecuteTransferBatchItemTxParams) (db.ExecuteTransferBatchItemTxResult, error) {
+	return traced(ctx, "ExecuteTransferBatchItemTx", args, store.Store.ExecuteTransferBatchItemTx)
+}
+
+func (store *Store) CreateAccountTx(ctx context.Context, args db.CreateAccountTxParams) (db.Account, error) {
+	return traced(ctx, "CreateAccountTx", args, store.Store.CreateAccountTx)
+}
+
+func (store *Store) SetAccountFrozenTx(ctx context.Context, args db.SetAccountFrozenParams) (db.SetAccountFrozenTxResult, error) {
+	return traced(ctx, "SetAccountFrozenTx", args, store.Store.SetAccountFrozenTx)
+}
+
+func (store *Store) CreateSessionTx(ctx context.Context, args db.CreateSessionParams) (db.Session, error) {
+	return traced(ctx, "CreateSessionTx", args, store.Store.CreateSessionTx)
+}
+
+func (store *Store) UpdateAccountTx(ctx context.Context, args db.UpdateAccountTxParams) (db.Account, error) {
+	return traced(ctx, "UpdateAccountTx", args, store.Store.UpdateAccountTx)
+}
@@ -0,0 +1,62 @@
+// Package tracing sets up OpenTelemetry and instruments the HTTP and gRPC
+// APIs and db.Store with spans.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const instrumentationName = "github.com/ulunnuha-h/simple_bank"
+
+var tracer = otel.Tracer(instrumentationName)
+
+// Config selects where spans are exported.
+type Config struct {
+	ServiceName string
+	// Endpoint is the URL of an OTLP/HTTP collector. When empty no spans are
+	// recorded, but incoming trace context is still passed along.
+	Endpoint string
+	// SampleRatio is the share of new traces recorded; traces started
+	// upstream follow the caller's decision.
+	SampleRatio float64
+}
+
+// Setup installs the W3C trace context propagator and, when an endpoint is
+// configured, a tracer provider exporting to it. The returned function
+// flushes pending spans.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(config.Endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := config.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,176 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	exporterOnce sync.Once
+	exporter     *tracetest.InMemoryExporter
+)
+
+// recordSpans installs an in-memory exporter. The global provider can only
+// be set once per process, so tests share it and reset it instead.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporterOnce.Do(func() {
+		exporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	exporter.Reset()
+	return exporter
+}
+
+func findSpan(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %s in %d spans", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+const (
+	incomingTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	incomingSpanID  = "00f067aa0ba902b7"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	exporter := recordSpans(t)
+
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	mock.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, errors.New("boom"))
+	store := NewStore(mock)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(GinMiddleware())
+	router.POST("/transfers", func(ctx *gin.Context) {
+		_, err := store.TransferTx(ctx, db.TransferTxParams{})
+		require.Error(t, err)
+		ctx.Status(http.StatusInternalServerError)
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/transfers", nil)
+	require.NoError(t, err)
+	request.Header.Set("traceparent", "00-"+incomingTraceID+"-"+incomingSpanID+"-01")
+	router.ServeHTTP(recorder, request)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	server := findSpan(t, spans, "POST /transfers")
+	require.Equal(t, incomingTraceID, server.SpanContext.TraceID().String())
+	require.Equal(t, incomingSpanID, server.Parent.SpanID().String())
+	require.True(t, server.Parent.IsRemote())
+	require.Equal(t, codes.Error, server.Status.Code)
+
+	tx := findSpan(t, spans, "store.TransferTx")
+	require.Equal(t, server.SpanContext.SpanID(), tx.Parent.SpanID())
+	require.Equal(t, codes.Error, tx.Status.Code)
+	require.Len(t, tx.Events, 1)
+
+	traceparent := recorder.Header().Get("traceparent")
+	require.True(t, strings.Contains(traceparent, incomingTraceID), traceparent)
+	require.True(t, strings.Contains(traceparent, server.SpanContext.SpanID().String()), traceparent)
+}
+
+func TestGinMiddlewareStartsTrace(t *testing.T) {
+	exporter := recordSpans(t)
+
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/accounts/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/accounts/1", nil)
+	require.NoError(t, err)
+	router.ServeHTTP(recorder, request)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET /accounts/:id", spans[0].Name)
+	require.False(t, spans[0].Parent.IsValid())
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	exporter := recordSpans(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs("traceparent", "00-"+incomingTraceID+"-"+incomingSpanID+"-01"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.SimpleBank/CreateTransfer"}
+
+	_, err := UnaryServerInterceptor()(ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+		require.True(t, trace.SpanContextFromContext(ctx).IsValid())
+		return nil, status.Error(grpccodes.Internal, "boom")
+	})
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, info.FullMethod, spans[0].Name)
+	require.Equal(t, incomingTraceID, spans[0].SpanContext.TraceID().String())
+	require.Equal(t, incomingSpanID, spans[0].Parent.SpanID().String())
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+
+	// A caller's mistake is not a server error.
+	exporter.Reset()
+	_, err = UnaryServerInterceptor()(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(grpccodes.NotFound, "account not found")
+	})
+	require.Error(t, err)
+	spans = exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent.IsValid())
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+}
+
+func TestStorePassesThrough(t *testing.T) {
+	exporter := recordSpans(t)
+
+	ctrl := gomock.NewController(t)
+	mock := mockdb.NewMockStore(ctrl)
+	mock.EXPECT().GetAccount(gomock.Any(), int64(1)).Times(1).Return(db.Account{ID: 1}, nil)
+	mock.EXPECT().CreateSessionTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{Username: "alice"}, nil)
+
+	store := NewStore(mock)
+	account, err := store.GetAccount(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), account.ID)
+
+	session, err := store.CreateSessionTx(context.Background(), db.CreateSessionParams{})
+	require.NoError(t, err)
+	require.Equal(t, "alice", session.Username)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, "store.CreateSessionTx", spans[0].Name)
+}
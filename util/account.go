@@ -0,0 +1,47 @@
+package util
+
+const (
+	CheckingAccount = "checking"
+	SavingsAccount  = "savings"
+	PocketAccount   = "pocket"
+	SystemAccount   = "system"
+)
+
+const StandardTier = "standard"
+
+// Purposes of the accounts owned by the bank itself, one per currency.
+const (
+	InterestExpensePurpose = "interest_expense"
+	FeeRevenuePurpose      = "fee_revenue"
+)
+
+// Permissions a member can hold on an account they do not own. Each one
+// implies the ones listed before it; the owner implicitly holds all of them.
+const (
+	ViewPermission     = "view"
+	TransferPermission = "transfer"
+	ManagePermission   = "manage"
+)
+
+// OwnerPermission is required for actions only the owner may take, such as
+// inviting members or deleting the account. It cannot be granted, so no
+// member ever holds it.
+const OwnerPermission = "owner"
+
+var permissionRanks = map[string]int{
+	ViewPermission:     1,
+	TransferPermission: 2,
+	ManagePermission:   3,
+}
+
+func IsValidPermission(permission string) bool {
+	_, ok := permissionRanks[permission]
+	return ok
+}
+
+// HasPermission reports whether holding granted is enough to perform an
+// action that requires required.
+func HasPermission(granted, required string) bool {
+	return IsValidPermission(granted) && IsValidPermission(required) &&
+		permissionRanks[granted] >= permissionRanks[required]
+}
@@ -0,0 +1,53 @@
+package util
+
+import "strings"
+
+// AccountNumberLength is the length of a public account number: eleven
+// random digits followed by a Luhn check digit. The database generates them.
+const AccountNumberLength = 12
+
+// IsValidAccountNumber reports whether number has the right length and a
+// correct Luhn check digit, catching most typos before a lookup.
+func IsValidAccountNumber(number string) bool {
+	if len(number) != AccountNumberLength {
+		return false
+	}
+
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return LuhnCheckDigit(number[:len(number)-1]) == int(number[len(number)-1]-'0')
+}
+
+// LuhnCheckDigit returns the digit that makes digits followed by it pass the
+// Luhn check. digits must contain only ASCII digits.
+func LuhnCheckDigit(digits string) int {
+	total := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		total += d
+	}
+
+	return (10 - total%10) % 10
+}
+
+// MaskName hides all but the first letter of every word in name, so a
+// sender can recognise the recipient without learning their full name.
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+
+	return strings.Join(words, " ")
+}
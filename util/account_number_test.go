@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLuhnCheckDigit(t *testing.T) {
+	// 7992739871 is the textbook Luhn example, its check digit is 3.
+	require.Equal(t, 3, LuhnCheckDigit("7992739871"))
+	require.Equal(t, 0, LuhnCheckDigit("00000000000"))
+}
+
+func TestIsValidAccountNumber(t *testing.T) {
+	require.True(t, IsValidAccountNumber("123456789015"))
+	require.False(t, IsValidAccountNumber("123456789016"))
+	require.False(t, IsValidAccountNumber("123456789051"))
+	require.False(t, IsValidAccountNumber("12345678901"))
+	require.False(t, IsValidAccountNumber("12345678901a"))
+}
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** D**", MaskName("John Doe"))
+	require.Equal(t, "É****", MaskName("  Élise "))
+	require.Equal(t, "", MaskName(""))
+}
@@ -0,0 +1,17 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPermission(t *testing.T) {
+	require.True(t, HasPermission(ManagePermission, ViewPermission))
+	require.True(t, HasPermission(ManagePermission, TransferPermission))
+	require.True(t, HasPermission(TransferPermission, TransferPermission))
+	require.False(t, HasPermission(TransferPermission, ManagePermission))
+	require.False(t, HasPermission(ViewPermission, TransferPermission))
+	require.False(t, HasPermission("owner", ViewPermission))
+	require.False(t, HasPermission(ManagePermission, "owner"))
+}
@@ -0,0 +1,12 @@
+package util
+
+import "strings"
+
+// CSVText keeps user-supplied text from being read as a formula when a CSV
+// file is opened in a spreadsheet.
+func CSVText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -1,5 +1,7 @@
 package util
 
+import "fmt"
+
 var ValidCurrency = []string{"USD", "EUR", "IDR"}
 
 func IsValidCurrency(currency string) bool {
@@ -7,4 +9,15 @@ func IsValidCurrency(currency string) bool {
 		if currency == val { return true }
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// FormatAmount renders an amount in minor units with two decimals, which is
+// the precision of every supported currency.
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,7 @@
+package util
+
+// Types of the ledger events appended to the event stream. Transfers reuse
+// the webhook event name.
+const (
+	BalanceSetEvent = "account.balance_set"
+)
@@ -0,0 +1,9 @@
+package util
+
+const (
+	DepositorRole = "depositor"
+	AdminRole     = "admin"
+	// ServiceRole is for internal services, such as analytics, that read
+	// the event stream but are not admins.
+	ServiceRole = "service"
+)
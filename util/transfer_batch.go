@@ -0,0 +1,20 @@
+package util
+
+// Statuses of a transfer batch. An approved batch is processing until a
+// background job has run its rows; it is failed when the job hit an error
+// it could not record against a single row.
+const (
+	TransferBatchPending    = "pending"
+	TransferBatchProcessing = "processing"
+	TransferBatchCompleted  = "completed"
+	TransferBatchFailed     = "failed"
+)
+
+// Statuses of a row in a transfer batch. Rows that fail validation on upload
+// are invalid and never executed; valid rows end up succeeded or failed.
+const (
+	TransferBatchItemValid     = "valid"
+	TransferBatchItemInvalid   = "invalid"
+	TransferBatchItemSucceeded = "succeeded"
+	TransferBatchItemFailed    = "failed"
+)
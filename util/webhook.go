@@ -0,0 +1,33 @@
+package util
+
+// Events that webhook subscriptions can listen for.
+const (
+	TransferCreatedEvent = "transfer.created"
+	AccountFrozenEvent   = "account.frozen"
+	AccountUnfrozenEvent = "account.unfrozen"
+	SessionCreatedEvent  = "session.created"
+)
+
+var WebhookEvents = []string{
+	TransferCreatedEvent,
+	AccountFrozenEvent,
+	AccountUnfrozenEvent,
+	SessionCreatedEvent,
+}
+
+func IsValidWebhookEvent(event string) bool {
+	for _, val := range WebhookEvents {
+		if val == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Statuses of a webhook delivery. Deliveries that keep failing end up dead
+// and are only retried on request.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
@@ -0,0 +1,209 @@
+// Package webhook delivers queued events to the URLs users subscribed with.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+)
+
+const (
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 10
+	defaultBaseBackoff = 30 * time.Second
+	defaultMaxBackoff  = 6 * time.Hour
+	defaultTimeout     = 10 * time.Second
+)
+
+// statusError is a delivery answered with a status other than 2xx.
+type statusError struct {
+	status string
+}
+
+func (err statusError) Error() string {
+	return err.status
+}
+
+// Dispatcher sends pending webhook deliveries. A delivery succeeds on any 2xx
+// response; anything else is retried with exponential backoff until
+// maxAttempts, after which the delivery is dead.
+type Dispatcher struct {
+	store         db.Store
+	client        *http.Client
+	allowInsecure bool
+	batchSize     int32
+	maxAttempts   int32
+	baseBackoff   time.Duration
+	maxBackoff    time.Duration
+	now           func() time.Time
+}
+
+// NewDispatcher creates a dispatcher that only connects to public
+// addresses over https unless allowInsecure is set, as URLChecker does.
+func NewDispatcher(store db.Store, batchSize int32, allowInsecure bool) *Dispatcher {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &Dispatcher{
+		store:         store,
+		client:        newClient(allowInsecure),
+		allowInsecure: allowInsecure,
+		batchSize:     batchSize,
+		maxAttempts:   defaultMaxAttempts,
+		baseBackoff:   defaultBaseBackoff,
+		maxBackoff:    defaultMaxBackoff,
+		now:           time.Now,
+	}
+}
+
+// newClient returns the client deliveries are sent with. Redirects are not
+// followed, and the address is checked as the connection is made, so
+// neither a redirect nor DNS rebinding can reach an internal service.
+func newClient(allowInsecure bool) *http.Client {
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	if !allowInsecure {
+		dialer.Control = dialControl
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   defaultTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// RunOnce claims one batch of due deliveries and sends them. It returns how
+// many were delivered.
+func (dispatcher *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	// Claimed deliveries stay hidden until every request in the batch could
+	// have timed out.
+	lease := dispatcher.client.Timeout*time.Duration(dispatcher.batchSize) + time.Minute
+
+	deliveries, err := dispatcher.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+		LeaseUntil: dispatcher.now().Add(lease),
+		Limit:      dispatcher.batchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		sendErr := dispatcher.send(ctx, delivery)
+		if sendErr == nil {
+			if _, err := dispatcher.store.MarkWebhookDelivered(ctx, delivery.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		if err := dispatcher.fail(ctx, delivery, sendErr); err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+func (dispatcher *Dispatcher) send(ctx context.Context, delivery db.ClaimWebhookDeliveriesRow) error {
+	// Subscriptions made before https was required may still use http.
+	if u, err := url.Parse(delivery.Url); err != nil || (u.Scheme != "https" && !dispatcher.allowInsecure) {
+		return ErrInsecureURL
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(EventHeader, delivery.EventType)
+	request.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, dispatcher.now(), delivery.Payload))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	// Drain the body so the connection can be reused. It is never kept: the
+	// receiver could be anything, and the last error is shown to the user.
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return statusError{status: strconv.Itoa(response.StatusCode)}
+	}
+	return nil
+}
+
+// lastError describes a failed delivery to the subscriber. Only the status
+// code of a response is recorded; connection errors are reduced to their
+// kind, so the deliveries list can't be used to probe the network.
+func lastError(err error) string {
+	var statusErr statusError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("status %s", statusErr.status)
+	case errors.Is(err, ErrInsecureURL):
+		return ErrInsecureURL.Error()
+	case errors.Is(err, ErrForbiddenAddress):
+		return ErrForbiddenAddress.Error()
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "request timed out"
+	default:
+		return "request failed"
+	}
+}
+
+func (dispatcher *Dispatcher) fail(ctx context.Context, delivery db.ClaimWebhookDeliveriesRow, sendErr error) error {
+	attempts := delivery.Attempts + 1
+	status := util.WebhookDeliveryPending
+	if attempts >= dispatcher.maxAttempts {
+		status = util.WebhookDeliveryDead
+	}
+
+	slog.WarnContext(ctx, "webhook delivery failed",
+		slog.Int64("delivery_id", delivery.ID), slog.Int("attempts", int(attempts)), slog.Any("error", sendErr))
+
+	_, err := dispatcher.store.MarkWebhookFailed(ctx, db.MarkWebhookFailedParams{
+		ID:            delivery.ID,
+		Status:        status,
+		NextAttemptAt: dispatcher.now().Add(dispatcher.backoff(attempts)),
+		LastError:     lastError(sendErr),
+	})
+	return err
+}
+
+// backoff returns how long to wait after the given number of failed
+// attempts: baseBackoff, doubling each time, capped at maxBackoff.
+func (dispatcher *Dispatcher) backoff(attempts int32) time.Duration {
+	delay := dispatcher.baseBackoff
+	for i := int32(1); i < attempts; i++ {
+		delay *= 2
+		if delay >= dispatcher.maxBackoff {
+			return dispatcher.maxBackoff
+		}
+	}
+	return delay
+}
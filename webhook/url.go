@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrInsecureURL      = errors.New("webhook url must use https")
+	ErrForbiddenAddress = errors.New("webhook url must not point to a loopback, private, link-local or unspecified address")
+)
+
+// Resolver looks up the addresses of a host; net.DefaultResolver is one.
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// URLChecker decides which URLs users may subscribe to. Deliveries leave
+// from inside the network, so by default only https URLs of public hosts
+// are accepted. AllowInsecure lifts both rules for local development.
+type URLChecker struct {
+	AllowInsecure bool
+	Resolver      Resolver
+}
+
+// Check rejects rawURL unless it is https and every address of its host is
+// public. The dispatcher checks the address again when it connects, since
+// the host may resolve differently by then.
+func (checker URLChecker) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("webhook url %q is not an absolute url", rawURL)
+	}
+
+	if checker.AllowInsecure {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return errors.New("webhook url must use http or https")
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return ErrInsecureURL
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if forbiddenIP(ip) {
+			return ErrForbiddenAddress
+		}
+		return nil
+	}
+
+	resolver := checker.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("cannot resolve webhook host %q", u.Hostname())
+	}
+	for _, addr := range addrs {
+		if forbiddenIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// forbiddenIP reports whether ip belongs to the host itself or a network
+// that isn't reachable from the internet, such as the cloud metadata
+// service on 169.254.169.254.
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast()
+}
+
+// dialControl refuses connections to forbidden addresses. It runs after
+// the host has been resolved, on the address actually dialled, so a host
+// that resolves to a public address when checked and to a private one when
+// delivering is still refused.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || forbiddenIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
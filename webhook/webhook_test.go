@@ -0,0 +1,232 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	mockdb "github.com/ulunnuha-h/simple_bank/db/mock"
+	db "github.com/ulunnuha-h/simple_bank/db/sqlc"
+	"github.com/ulunnuha-h/simple_bank/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSignature(t *testing.T) {
+	now := time.Unix(1774000000, 0)
+	body := []byte(`{"type":"transfer.created"}`)
+	header := Sign("secret", now, body)
+
+	require.NoError(t, Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute))
+	require.ErrorIs(t, Verify("other", header, body, now, 5*time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, []byte(`{}`), now, 5*time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", header, body, now.Add(time.Hour), 5*time.Minute), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("secret", "v1=abc", body, now, 5*time.Minute), ErrInvalidSignature)
+}
+
+func newTestDispatcher(store db.Store, now time.Time) *Dispatcher {
+	// The receivers are plain http servers on loopback.
+	dispatcher := NewDispatcher(store, 10, true)
+	dispatcher.now = func() time.Time { return now }
+	return dispatcher
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	now := time.Now()
+	payload := []byte(`{"id":"1","type":"transfer.created"}`)
+
+	var received *http.Request
+	var receivedBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ClaimWebhookDeliveriesRow{
+		{ID: 7, EventID: uuid.New(), EventType: util.TransferCreatedEvent, Payload: payload, Url: receiver.URL, Secret: "whsec"},
+	}, nil)
+	store.EXPECT().MarkWebhookDelivered(gomock.Any(), int64(7)).Times(1).Return(db.WebhookDelivery{}, nil)
+
+	delivered, err := newTestDispatcher(store, now).RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, delivered)
+
+	require.Equal(t, payload, receivedBody)
+	require.Equal(t, util.TransferCreatedEvent, received.Header.Get(EventHeader))
+	require.Equal(t, "7", received.Header.Get(DeliveryHeader))
+	require.NoError(t, Verify("whsec", received.Header.Get(SignatureHeader), receivedBody, now, time.Minute))
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	now := time.Now()
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "try later", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	testCases := []struct {
+		name     string
+		attempts int32
+		status   string
+		delay    time.Duration
+	}{
+		{"FirstFailure", 0, util.WebhookDeliveryPending, defaultBaseBackoff},
+		{"ThirdFailure", 2, util.WebhookDeliveryPending, 4 * defaultBaseBackoff},
+		{"DeadLetter", defaultMaxAttempts - 1, util.WebhookDeliveryDead, 512 * defaultBaseBackoff},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ClaimWebhookDeliveriesRow{
+				{ID: 7, Attempts: tc.attempts, Payload: []byte(`{}`), Url: receiver.URL, Secret: "whsec"},
+			}, nil)
+			store.EXPECT().MarkWebhookDelivered(gomock.Any(), gomock.Any()).Times(0)
+			store.EXPECT().MarkWebhookFailed(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ any, args db.MarkWebhookFailedParams) (db.WebhookDelivery, error) {
+					require.Equal(t, int64(7), args.ID)
+					require.Equal(t, tc.status, args.Status)
+					require.Equal(t, now.Add(tc.delay), args.NextAttemptAt)
+					require.Equal(t, "status 503", args.LastError)
+					return db.WebhookDelivery{}, nil
+				})
+
+			delivered, err := newTestDispatcher(store, now).RunOnce(context.Background())
+			require.NoError(t, err)
+			require.Zero(t, delivered)
+		})
+	}
+}
+
+func TestDispatcherUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	url := receiver.URL
+	receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ClaimWebhookDeliveriesRow{
+		{ID: 8, Payload: []byte(`{}`), Url: url, Secret: "whsec"},
+	}, nil)
+	store.EXPECT().MarkWebhookFailed(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, nil)
+
+	_, err := newTestDispatcher(store, time.Now()).RunOnce(context.Background())
+	require.NoError(t, err)
+}
+
+func TestDispatcherRefusesInternalAddresses(t *testing.T) {
+	var hits int
+	receiver := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer receiver.Close()
+	_, port, err := net.SplitHostPort(receiver.Listener.Addr().String())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		url       string
+		lastError string
+	}{
+		{"Loopback", receiver.URL, ErrForbiddenAddress.Error()},
+		// The host name passes no check at subscription time; the address
+		// it resolves to is refused when connecting.
+		{"HostName", "https://localhost:" + port, ErrForbiddenAddress.Error()},
+		{"PlainHTTP", "http://example.com/hooks", ErrInsecureURL.Error()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.ClaimWebhookDeliveriesRow{
+				{ID: 9, Payload: []byte(`{}`), Url: tc.url, Secret: "whsec"},
+			}, nil)
+			store.EXPECT().MarkWebhookFailed(gomock.Any(), gomock.Any()).Times(1).
+				DoAndReturn(func(_ any, args db.MarkWebhookFailedParams) (db.WebhookDelivery, error) {
+					require.Equal(t, tc.lastError, args.LastError)
+					return db.WebhookDelivery{}, nil
+				})
+
+			dispatcher := NewDispatcher(store, 10, false)
+			_, err := dispatcher.RunOnce(context.Background())
+			require.NoError(t, err)
+		})
+	}
+	require.Zero(t, hits)
+}
+
+type fakeResolver map[string][]string
+
+func (resolver fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := resolver[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestURLChecker(t *testing.T) {
+	resolver := fakeResolver{
+		"hooks.example.com":    {"93.184.216.34"},
+		"internal.example.com": {"10.1.2.3"},
+		"mixed.example.com":    {"93.184.216.34", "127.0.0.1"},
+	}
+
+	testCases := []struct {
+		name          string
+		url           string
+		allowInsecure bool
+		err           error
+	}{
+		{name: "Public", url: "https://hooks.example.com/events"},
+		{name: "PublicIP", url: "https://93.184.216.34/events"},
+		{name: "PlainHTTP", url: "http://hooks.example.com/events", err: ErrInsecureURL},
+		{name: "PrivateHost", url: "https://internal.example.com/events", err: ErrForbiddenAddress},
+		{name: "AnyPrivateAddress", url: "https://mixed.example.com/events", err: ErrForbiddenAddress},
+		{name: "Loopback", url: "https://127.0.0.1/events", err: ErrForbiddenAddress},
+		{name: "LoopbackV6", url: "https://[::1]/events", err: ErrForbiddenAddress},
+		{name: "Metadata", url: "https://169.254.169.254/latest/meta-data", err: ErrForbiddenAddress},
+		{name: "Unspecified", url: "https://0.0.0.0/events", err: ErrForbiddenAddress},
+		{name: "MappedPrivate", url: "https://[::ffff:192.168.0.1]/events", err: ErrForbiddenAddress},
+		{name: "InsecureAllowed", url: "http://127.0.0.1:8081/events", allowInsecure: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := URLChecker{AllowInsecure: tc.allowInsecure, Resolver: resolver}
+			err := checker.Check(context.Background(), tc.url)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	checker := URLChecker{Resolver: resolver}
+	require.ErrorContains(t, checker.Check(context.Background(), "https://unknown.example.com"), "cannot resolve")
+	require.Error(t, checker.Check(context.Background(), "ftp://hooks.example.com"))
+	require.Error(t, URLChecker{AllowInsecure: true}.Check(context.Background(), "ftp://hooks.example.com"))
+}
+
+func TestBackoffCap(t *testing.T) {
+	dispatcher := NewDispatcher(nil, 0, false)
+	require.Equal(t, defaultBaseBackoff, dispatcher.backoff(1))
+	require.Equal(t, 2*defaultBaseBackoff, dispatcher.backoff(2))
+	require.Equal(t, defaultMaxBackoff, dispatcher.backoff(20))
+}
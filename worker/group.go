@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Group runs periodic jobs that can be stopped together on shutdown.
+type Group struct {
+	jobs []*job
+}
+
+type job struct {
+	name   string
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Go starts fn with RunPeriodic under the group.
+func (g *Group) Go(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	j := &job{name: name, cancel: cancel, done: make(chan struct{})}
+	g.jobs = append(g.jobs, j)
+
+	go func() {
+		defer close(j.done)
+		RunPeriodic(ctx, name, interval, fn)
+	}()
+}
+
+// Stop stops the jobs one at a time, last started first, waiting for each
+// to return before stopping the next. A run in progress sees its context
+// cancelled.
+func (g *Group) Stop() {
+	for i := len(g.jobs) - 1; i >= 0; i-- {
+		j := g.jobs[i]
+		j.cancel()
+		<-j.done
+		slog.Info("worker stopped", slog.String("worker", j.name))
+	}
+	g.jobs = nil
+}
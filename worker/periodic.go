@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RunPeriodic calls fn every interval until ctx is cancelled. Errors are
+// logged and the job keeps its schedule; a non-positive interval disables it.
+func RunPeriodic(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	if interval <= 0 {
+		slog.Info("worker disabled", slog.String("worker", name))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				slog.Error("worker run failed", slog.String("worker", name), slog.Any("error", err))
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunPeriodic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		RunPeriodic(ctx, "test", time.Millisecond, func(ctx context.Context) error {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return errors.New("keeps running after errors")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RunPeriodic did not stop after cancel")
+	}
+	require.GreaterOrEqual(t, calls.Load(), int32(3))
+}
+
+func TestRunPeriodicDisabled(t *testing.T) {
+	called := false
+	RunPeriodic(context.Background(), "test", 0, func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	require.False(t, called)
+}
+
+func TestGroupStopsInReverseOrder(t *testing.T) {
+	var group Group
+	var mu sync.Mutex
+	var order []string
+
+	for _, name := range []string{"first", "second"} {
+		name := name
+		group.Go(name, time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			if len(order) == 0 || order[len(order)-1] != name {
+				order = append(order, name)
+			}
+			return nil
+		})
+	}
+
+	// Let both jobs enter a run so Stop has to wait for it.
+	time.Sleep(20 * time.Millisecond)
+	group.Stop()
+	require.Equal(t, []string{"second", "first"}, order)
+}